@@ -1,10 +1,16 @@
 package main
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	// "github.com/jinzhu/gorm"
 	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/migration/migrations"
 
 	docs "github.com/s2dio-tech/mindgra-backend/docs"
 	swaggerfiles "github.com/swaggo/files"
@@ -12,6 +18,7 @@ import (
 
 	common "github.com/s2dio-tech/mindgra-backend/common"
 	auth "github.com/s2dio-tech/mindgra-backend/common/auth"
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
 	_httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
 
 	_mailService "github.com/s2dio-tech/mindgra-backend/internal/email/service"
@@ -21,6 +28,8 @@ import (
 	_tokenRepo "github.com/s2dio-tech/mindgra-backend/internal/auth/repository"
 	_authUsecase "github.com/s2dio-tech/mindgra-backend/internal/auth/usecase"
 
+	_maintenanceUsecase "github.com/s2dio-tech/mindgra-backend/internal/maintenance/usecase"
+
 	_userHttp "github.com/s2dio-tech/mindgra-backend/internal/users/delivery/http"
 	_userRepo "github.com/s2dio-tech/mindgra-backend/internal/users/repository"
 	_userUsecase "github.com/s2dio-tech/mindgra-backend/internal/users/usecase"
@@ -35,16 +44,102 @@ func main() {
 	// init configuration
 	common.InitConfig()
 
-	// init database
-	db := datasource.InitNeo4J()
-	defer db.Disconnect()
+	// init database and repositories
+	var transactor domain.Transactor
+	var userRepo domain.UserRepository
+	var tokenRepo domain.TokenRepository
+	var wordRepo domain.WordRepository
+	var graphRepo domain.GraphRepository
+	var linkRepo domain.LinkRepository
+	var memberRepo domain.MemberRepository
+	var shareRepo domain.ShareRepository
+	var revisionRepo domain.RevisionRepository
+	var trashRepo domain.TrashRepository
+
+	switch common.AppConfig.DBDriver {
+	case common.DBDriverNeo4J, common.DBDriverMemgraph:
+		db := datasource.InitDatasource()
+		defer db.Disconnect()
+
+		// refuse to serve on a schema older than the embedded migrations,
+		// or missing the constraints and indexes of memgraph, which has no migrations
+		if mg, ok := db.(*datasource.MemGraph); ok {
+			if err := mg.CheckSchema(context.Background(), migrations.MemgraphSchema); err != nil {
+				panic(err)
+			}
+		} else {
+			version, err := migrations.LatestVersion(common.DBDriverNeo4J)
+			if err != nil {
+				panic(err)
+			}
+			if err := datasource.CheckSchemaVersion(context.Background(), db, version); err != nil {
+				panic(err)
+			}
+		}
 
-	// repositories
-	userRepo := _userRepo.InitUserRepository(&db)
-	tokenRepo := _tokenRepo.InitTokenRepository(&db)
-	wordRepo := _wordRepo.InitWordRepository(&db)
-	graphRepo := _wordRepo.InitGraphRepository(&db)
-	linkRepo := _wordRepo.InitLinkRepository(&db)
+		transactor = db
+		userRepo = _userRepo.InitUserRepository(db)
+		tokenRepo = _tokenRepo.InitTokenRepository(db)
+		wordRepo = _wordRepo.InitWordRepository(db)
+		graphRepo = _wordRepo.InitGraphRepository(db)
+		linkRepo = _wordRepo.InitLinkRepository(db)
+		memberRepo = _wordRepo.InitMemberRepository(db)
+		shareRepo = _wordRepo.InitShareRepository(db)
+		revisionRepo = _wordRepo.InitRevisionRepository(db)
+		trashRepo = _wordRepo.InitTrashRepository(db)
+	case common.DBDriverSQLite:
+		db := datasource.InitSQLite()
+		defer db.Disconnect()
+
+		version, err := migrations.LatestVersion(common.DBDriverSQLite)
+		if err != nil {
+			panic(err)
+		}
+		if err := db.CheckSchemaVersion(context.Background(), version); err != nil {
+			panic(err)
+		}
+
+		transactor = db
+		userRepo = _userRepo.InitUserSQLiteRepository(db)
+		tokenRepo = _tokenRepo.InitTokenSQLiteRepository(db)
+		wordRepo = _wordRepo.InitWordSQLiteRepository(db)
+		graphRepo = _wordRepo.InitGraphSQLiteRepository(db)
+		linkRepo = _wordRepo.InitLinkSQLiteRepository(db)
+		memberRepo = _wordRepo.InitMemberSQLiteRepository(db)
+		shareRepo = _wordRepo.InitShareSQLiteRepository(db)
+		revisionRepo = _wordRepo.InitRevisionSQLiteRepository(db)
+		trashRepo = _wordRepo.InitTrashSQLiteRepository(db)
+	case common.DBDriverMemory:
+		db := datasource.InitMemory()
+		defer db.Disconnect()
+
+		transactor = db
+		userRepo = _userRepo.InitUserMemoryRepository(db)
+		tokenRepo = _tokenRepo.InitTokenMemoryRepository(db)
+		wordRepo = _wordRepo.InitWordMemoryRepository(db)
+		graphRepo = _wordRepo.InitGraphMemoryRepository(db)
+		linkRepo = _wordRepo.InitLinkMemoryRepository(db)
+		memberRepo = _wordRepo.InitMemberMemoryRepository(db)
+		shareRepo = _wordRepo.InitShareMemoryRepository(db)
+		revisionRepo = _wordRepo.InitRevisionMemoryRepository(db)
+		trashRepo = _wordRepo.InitTrashMemoryRepository(db)
+	default:
+		panic("DB_DRIVER " + common.AppConfig.DBDriver + " not supported")
+	}
+
+	// graphs and graph data are read through the cache,
+	// the usecases invalidate them on every write
+	var cacheBackend cache.Cache = cache.Nop{}
+	if common.AppConfig.CacheSize > 0 {
+		cacheBackend = cache.InitLRU(common.AppConfig.CacheSize)
+	}
+	graphCache := _wordRepo.InitGraphDataCache(cacheBackend)
+	wordRepo = _wordRepo.InitWordCachedRepository(wordRepo, graphCache)
+	graphRepo = _wordRepo.InitGraphCachedRepository(graphRepo, graphCache)
+	// autocomplete of the words, dropped with the graph data
+	wordIndex := _wordRepo.InitWordIndex(wordRepo, graphCache, common.AppConfig.WordIndexSize)
+	// related words by text similarity, built again with the graph data
+	wordSimilarity := _wordRepo.InitWordSimilarity(wordRepo, graphCache, common.AppConfig.WordIndexSize)
 
 	mailUsecase := _mailUsecase.Init(&_mailService.MailJet{
 		PublicKey:  *common.AppConfig.MailjetPublicKey,
@@ -56,16 +151,30 @@ func main() {
 	// 	Username: *common.AppConfig.SMTPUsername,
 	// 	Password: *common.AppConfig.SMTPPassword,
 	// })
-	authUsecase := _authUsecase.InitAuthUsecase(tokenRepo, userRepo, mailUsecase)
-	userUsecase := _userUsecase.InitUserUsecase(userRepo, mailUsecase)
-	wordUsecase := _wordUsecase.InitWordUsecase(wordRepo, graphRepo)
-	linkUsecase := _wordUsecase.InitLinkUsecase(linkRepo, wordRepo)
-	graphUsecase := _wordUsecase.InitGraphUsecase(graphRepo)
+	authUsecase := _authUsecase.InitAuthUsecase(tokenRepo, userRepo, mailUsecase, transactor)
+	userUsecase := _userUsecase.InitUserUsecase(userRepo, mailUsecase, transactor)
+	wordUsecase := _wordUsecase.InitWordUsecase(wordRepo, graphRepo, memberRepo, shareRepo, revisionRepo, trashRepo, wordIndex, wordSimilarity, graphCache, transactor)
+	linkUsecase := _wordUsecase.InitLinkUsecase(linkRepo, wordRepo, graphRepo, memberRepo, shareRepo, revisionRepo, trashRepo, graphCache, transactor)
+	graphUsecase := _wordUsecase.InitGraphUsecase(graphRepo, memberRepo, shareRepo, graphCache, transactor)
+	memberUsecase := _wordUsecase.InitMemberUsecase(memberRepo, graphRepo, userRepo, mailUsecase, transactor)
+	shareUsecase := _wordUsecase.InitShareUsecase(shareRepo, graphRepo, memberRepo, transactor)
+	exportUsecase := _wordUsecase.InitExportUsecase(wordRepo, linkRepo, graphRepo, memberRepo, shareRepo)
+	importUsecase := _wordUsecase.InitImportUsecase(graphRepo, wordRepo, linkRepo, memberRepo, graphCache, transactor)
+	revisionUsecase := _wordUsecase.InitRevisionUsecase(revisionRepo, wordRepo, linkRepo, graphRepo, memberRepo, shareRepo, graphCache, transactor)
+	trashUsecase := _wordUsecase.InitTrashUsecase(trashRepo, wordRepo, graphRepo, memberRepo, shareRepo, graphCache, transactor)
+	purgeUsecase := _maintenanceUsecase.InitPurgeUsecase(graphRepo, tokenRepo)
+
+	// remove the expired graphs and tokens in the background
+	if common.AppConfig.PurgeInterval > 0 {
+		go _maintenanceUsecase.Schedule(context.Background(), purgeUsecase, common.AppConfig.PurgeInterval)
+	}
 
 	///////////////////////////
 	// init rest api server
 	///////////////////////////
 	r := gin.Default()
+	// cancel database calls when the client goes away
+	r.ContextWithFallback = true
 	r.SetTrustedProxies(nil)
 	r.Use(_httpCommon.CORSMiddleware())
 	docs.SwaggerInfo.BasePath = "/"
@@ -77,34 +186,80 @@ func main() {
 	wordHandler := _wordHttp.InitWordHandlers(wordUsecase)
 	linkHandler := _wordHttp.InitLinkHandlers(linkUsecase)
 	graphHandler := _wordHttp.InitGraphHandlers(graphUsecase)
+	memberHandler := _wordHttp.InitMemberHandlers(memberUsecase)
+	shareHandler := _wordHttp.InitShareHandlers(shareUsecase)
+	exportHandler := _wordHttp.InitExportHandlers(exportUsecase)
+	importHandler := _wordHttp.InitImportHandlers(importUsecase)
+	revisionHandler := _wordHttp.InitRevisionHandlers(revisionUsecase)
+	trashHandler := _wordHttp.InitTrashHandlers(trashUsecase)
 
 	authGroup := v1.Group("")
 	authGroup.Use(_httpCommon.CORSMiddleware())
 	authGroup.Use(auth.AuthMiddleware())
 	{
 		//words
-		authGroup.GET("/words/search", wordHandler.SearchWord)
-		authGroup.GET("/words/findPath", wordHandler.FindPath)
-		authGroup.GET("/words/:id", wordHandler.GetWordDetail)
 		authGroup.POST("/words", wordHandler.CreateWord)
 		authGroup.POST("/words/links", wordHandler.Link2Words)
 		authGroup.PUT("/words/:id", wordHandler.UpdateWord)
 		authGroup.DELETE("/words/:id", wordHandler.DeleteWord)
+		authGroup.POST("/words/:id/revisions/:revisionId/revert", revisionHandler.RevertWord)
 		//links
-		authGroup.GET("/links/:path1", linkHandler.GetDetail)
-		authGroup.GET("/links/:path1/:path2", linkHandler.GetDetail)
 		authGroup.POST("/links", linkHandler.CreateLink)
 		authGroup.PUT("/links/:id", linkHandler.UpdateLink)
 		authGroup.DELETE("/links", linkHandler.DeleteLink)
+		authGroup.POST("/links/:id/revisions/:revisionId/revert", revisionHandler.RevertLink)
 		//graphs
-		authGroup.GET("/graphs/:id/data", wordHandler.GetGraphData)
 		authGroup.GET("/graphs", graphHandler.List)
 		authGroup.POST("/graphs", graphHandler.CreateGraph)
 		authGroup.PUT("/graphs/:id", graphHandler.UpdateGraph)
 		authGroup.DELETE("/graphs/:id", graphHandler.DeleteGraph)
+		authGroup.POST("/graphs/:id/clone", graphHandler.CloneGraph)
+		authGroup.POST("/graphs/import", importHandler.Import)
+		authGroup.POST("/graphs/:id/import", importHandler.Import)
+		authGroup.GET("/graphs/trash", graphHandler.ListDeleted)
+		authGroup.POST("/graphs/:id/restore", graphHandler.RestoreGraph)
+		//trash
+		authGroup.GET("/graphs/:id/trash", trashHandler.List)
+		authGroup.DELETE("/graphs/:id/trash", trashHandler.Empty)
+		authGroup.POST("/graphs/:id/trash/words/:wordId/restore", trashHandler.RestoreWord)
+		authGroup.DELETE("/graphs/:id/trash/words/:wordId", trashHandler.PurgeWord)
+		authGroup.POST("/graphs/:id/trash/relations/:word1Id/:word2Id/restore", trashHandler.RestoreRelation)
+		authGroup.DELETE("/graphs/:id/trash/relations/:word1Id/:word2Id", trashHandler.PurgeRelation)
+		//members
+		authGroup.GET("/graphs/:id/members", memberHandler.List)
+		authGroup.POST("/graphs/:id/members", memberHandler.Invite)
+		authGroup.POST("/graphs/:id/members/accept", memberHandler.Accept)
+		authGroup.PUT("/graphs/:id/members/:memberId", memberHandler.ChangeRole)
+		authGroup.DELETE("/graphs/:id/members/:memberId", memberHandler.Remove)
+		authGroup.GET("/invitations", memberHandler.ListInvitations)
+		//share links
+		authGroup.GET("/graphs/:id/shares", shareHandler.List)
+		authGroup.POST("/graphs/:id/shares", shareHandler.Create)
+		authGroup.DELETE("/graphs/:id/shares/:shareId", shareHandler.Revoke)
 	}
 
-	v1.GET("/graphs/:id", graphHandler.Detail)
+	// reads of the graphs allowed by their visibility,
+	// anonymous requests see the public and unlisted ones
+	// and the graphs opened by the share link they give
+	publicGroup := v1.Group("")
+	publicGroup.Use(auth.OptionalAuthMiddleware())
+	{
+		publicGroup.GET("/graphs/:id", graphHandler.Detail)
+		publicGroup.GET("/graphs/:id/data", wordHandler.GetGraphData)
+		publicGroup.GET("/graphs/:id/suggest", wordHandler.Suggest)
+		publicGroup.POST("/graphs/:id/similar", wordHandler.SimilarToDraft)
+		publicGroup.GET("/graphs/:id/export", exportHandler.Export)
+		publicGroup.GET("/words/search", wordHandler.SearchWord)
+		publicGroup.GET("/words/findPath", wordHandler.FindPath)
+		publicGroup.GET("/words/:id", wordHandler.GetWordDetail)
+		publicGroup.GET("/words/:id/similar", wordHandler.Similar)
+		publicGroup.GET("/words/:id/revisions", revisionHandler.ListWord)
+		publicGroup.GET("/words/:id/revisions/diff", revisionHandler.DiffWord)
+		publicGroup.GET("/links/:path1", linkHandler.GetDetail)
+		publicGroup.GET("/links/:path1/:path2", linkHandler.GetDetail)
+		publicGroup.GET("/links/:path1/revisions", revisionHandler.ListLink)
+		publicGroup.GET("/links/:path1/revisions/diff", revisionHandler.DiffLink)
+	}
 
 	v1.POST("/auth/login", authHandler.Login)
 	v1.POST("/auth/grant", authHandler.Grant)
@@ -120,6 +275,17 @@ func main() {
 			"message": "pong",
 		})
 	})
+	// prometheus metrics, database queries among them,
+	// served on their own address so they are not exposed with the API
+	if common.AppConfig.MetricsAddr != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle("/metrics", promhttp.Handler())
+			if err := http.ListenAndServe(common.AppConfig.MetricsAddr, mux); err != nil {
+				panic(err)
+			}
+		}()
+	}
 
 	// listen and serve on 0.0.0.0:8080
 	r.Run()
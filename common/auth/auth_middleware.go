@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -17,34 +18,74 @@ func stripBearerPrefixFromTokenString(tok string) (string, error) {
 	return strings.Replace(tok, "Bearer ", "", 1), nil
 }
 
+// Read the user from the jwt of the request
+func parseUser(c *gin.Context) (*domain.Profile, error) {
+	token, err := request.ParseFromRequest(
+		c.Request,
+		&request.MultiExtractor{
+			&request.PostExtractionFilter{
+				Extractor: request.HeaderExtractor{"Authorization"},
+				Filter:    stripBearerPrefixFromTokenString,
+			},
+			request.ArgumentExtractor{"access_token"},
+		},
+		func(token *jwt.Token) (interface{}, error) {
+			b := ([]byte(common.AppConfig.TokenSecret))
+			return b, nil
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, common.ErrTokenInvalid
+	}
+	return &domain.Profile{
+		Id:   claims["id"].(string),
+		Role: domain.RoleMap[claims["role"].(string)],
+	}, nil
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		token, err := request.ParseFromRequest(
-			c.Request,
-			&request.MultiExtractor{
-				&request.PostExtractionFilter{
-					Extractor: request.HeaderExtractor{"Authorization"},
-					Filter:    stripBearerPrefixFromTokenString,
-				},
-				request.ArgumentExtractor{"access_token"},
-			},
-			func(token *jwt.Token) (interface{}, error) {
-				b := ([]byte(common.AppConfig.TokenSecret))
-				return b, nil
-			},
-		)
+		user, err := parseUser(c)
 		if err != nil {
 			c.AbortWithError(http.StatusUnauthorized, err)
 			return
 		}
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok || !token.Valid {
+		c.Set("user", *user)
+	}
+}
+
+// Read the share link token given in the X-Share-Token header of the request,
+// never in the query which is written to the access log
+func parseShare(c *gin.Context) *domain.ShareCredential {
+	token := c.GetHeader("X-Share-Token")
+	if token == "" {
+		return nil
+	}
+	return &domain.ShareCredential{
+		Token:    token,
+		Password: c.GetHeader("X-Share-Password"),
+	}
+}
+
+// OptionalAuthMiddleware lets the requests without a token through
+// as an anonymous user with an empty profile, for the public routes.
+// A token which is given must be valid.
+// The share link token of the request is kept in the profile.
+func OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, err := parseUser(c)
+		if errors.Is(err, request.ErrNoTokenInRequest) {
+			user, err = &domain.Profile{}, nil
+		}
+		if err != nil {
 			c.AbortWithError(http.StatusUnauthorized, err)
 			return
 		}
-		c.Set("user", domain.Profile{
-			Id:   claims["id"].(string),
-			Role: domain.RoleMap[claims["role"].(string)],
-		})
+		user.Share = parseShare(c)
+		c.Set("user", *user)
 	}
 }
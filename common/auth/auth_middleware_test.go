@@ -0,0 +1,43 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestShareTokenOnlyInTheHeader(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, test := range []struct {
+		name   string
+		header string
+		query  string
+		want   string
+	}{
+		{"header", "secret", "", "secret"},
+		{"query", "", "?share_token=secret", ""},
+		{"none", "", "", ""},
+	} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/graphs/g1"+test.query, nil)
+		if test.header != "" {
+			c.Request.Header.Set("X-Share-Token", test.header)
+			c.Request.Header.Set("X-Share-Password", "pass")
+		}
+		OptionalAuthMiddleware()(c)
+
+		user := ExtractUser(c)
+		got := ""
+		if user.Share != nil {
+			got = user.Share.Token
+			if user.Share.Password != "pass" {
+				t.Errorf("share password by the %s = %q", test.name, user.Share.Password)
+			}
+		}
+		if got != test.want {
+			t.Errorf("share token by the %s = %q, want %q", test.name, got, test.want)
+		}
+	}
+}
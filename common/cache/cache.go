@@ -0,0 +1,22 @@
+package cache
+
+import "context"
+
+// Cache keeps encoded values by key. The in-process LRU is the default
+// backend, a shared one such as redis can take its place behind this interface.
+type Cache interface {
+	Get(c context.Context, key string) ([]byte, bool)
+	Set(c context.Context, key string, value []byte)
+	Delete(c context.Context, keys ...string)
+}
+
+// Nop never keeps anything, used when the cache is disabled
+type Nop struct{}
+
+func (Nop) Get(c context.Context, key string) ([]byte, bool) {
+	return nil, false
+}
+
+func (Nop) Set(c context.Context, key string, value []byte) {}
+
+func (Nop) Delete(c context.Context, keys ...string) {}
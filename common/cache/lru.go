@@ -0,0 +1,37 @@
+package cache
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+)
+
+// LRU keeps the most recently used entries in memory,
+// evicting the oldest one when size is reached
+type LRU struct {
+	entries *lru.Cache[string, []byte]
+}
+
+func InitLRU(size int) *LRU {
+	entries, err := lru.New[string, []byte](size)
+	if err != nil {
+		panic(err)
+	}
+	return &LRU{
+		entries: entries,
+	}
+}
+
+func (l *LRU) Get(c context.Context, key string) ([]byte, bool) {
+	return l.entries.Get(key)
+}
+
+func (l *LRU) Set(c context.Context, key string, value []byte) {
+	l.entries.Add(key, value)
+}
+
+func (l *LRU) Delete(c context.Context, keys ...string) {
+	for _, key := range keys {
+		l.entries.Remove(key)
+	}
+}
@@ -2,6 +2,8 @@ package common
 
 import (
 	"os"
+	"strconv"
+	"time"
 )
 
 type Configuration struct {
@@ -11,10 +13,34 @@ type Configuration struct {
 	TokenSecret        string
 	RefreshTokenSecret string
 	//database
+	DBDriver   string
 	DBHost     string
 	DBPort     string
 	DBUsername string
 	DBPassword string
+	// database file of the sqlite driver
+	DBPath string
+	// timeout of a single read or write query
+	DBReadTimeout  time.Duration
+	DBWriteTimeout time.Duration
+	// retries of the queries failing on a transient error
+	DBRetryMaxAttempts  int
+	DBRetryInitialDelay time.Duration
+	DBRetryMaxDelay     time.Duration
+	// queries slower than this are logged, 0 disables the log
+	DBSlowQueryThreshold time.Duration
+	// entries of the graph data cache, 0 disables the cache
+	CacheSize int
+	// graphs whose word autocomplete and similarity indexes are kept, 0 builds them on every request
+	WordIndexSize int
+	// time a password reset OTP may be used
+	OTPLifeTime time.Duration
+	// time between the purges of the expired data, 0 disables them
+	PurgeInterval time.Duration
+	// time the deleted graphs are kept before they are purged
+	GraphRetention time.Duration
+	// address the prometheus metrics are served on, apart from the API, empty disables them
+	MetricsAddr string
 	//mail server
 	SMTPHost     *string
 	SMTPPort     *string
@@ -25,36 +51,37 @@ type Configuration struct {
 	MailjetPrivateKey *string
 }
 
+// supported values of DB_DRIVER
+const (
+	DBDriverNeo4J    = "neo4j"
+	DBDriverMemgraph = "memgraph"
+	DBDriverMemory   = "memory"
+	DBDriverSQLite   = "sqlite"
+)
+
 var AppConfig *Configuration
 
 func InitConfig() {
 	// string: key, bool: required
-	variables := map[string]bool{
+	tmp := lookupVariables(map[string]bool{
 		"APP_NAME":             true,
 		"APP_DOMAIN":           true,
 		"APP_EMAIL":            true,
 		"TOKEN_SECRET":         true,
 		"REFRESH_TOKEN_SECRET": true,
-		"DB_HOST":              true,
-		"DB_PORT":              true,
-		"DB_USERNAME":          true,
-		"DB_PASSWORD":          true,
 		"SMTP_HOST":            false,
 		"SMTP_PORT":            false,
 		"SMTP_USERNAME":        false,
 		"SMTP_PASSWORD":        false,
 		"MAILJET_PUBLIC_KEY":   false,
 		"MAILJET_PRIVATE_KEY":  false,
-	}
-
-	tmp := map[string]*string{}
-	for k, v := range variables {
-		val, found := os.LookupEnv(k)
-		if !found && v {
-			panic(k + " not set")
-		}
-		tmp[k] = &val
-	}
+		"CACHE_SIZE":           false,
+		"WORD_INDEX_SIZE":      false,
+		"OTP_LIFE_TIME":        false,
+		"PURGE_INTERVAL":       false,
+		"GRAPH_RETENTION":      false,
+		"METRICS_ADDR":         false,
+	})
 
 	AppConfig = &Configuration{
 		AppName:            *tmp["APP_NAME"],
@@ -62,15 +89,117 @@ func InitConfig() {
 		AppEmail:           *tmp["APP_EMAIL"],
 		TokenSecret:        *tmp["TOKEN_SECRET"],
 		RefreshTokenSecret: *tmp["REFRESH_TOKEN_SECRET"],
-		DBHost:             *tmp["DB_HOST"],
-		DBPort:             *tmp["DB_PORT"],
-		DBUsername:         *tmp["DB_USERNAME"],
-		DBPassword:         *tmp["DB_PASSWORD"],
 		SMTPHost:           tmp["SMTP_HOST"],
 		SMTPPort:           tmp["SMTP_PORT"],
 		SMTPUsername:       tmp["SMTP_USERNAME"],
 		SMTPPassword:       tmp["SMTP_PASSWORD"],
 		MailjetPublicKey:   tmp["MAILJET_PUBLIC_KEY"],
 		MailjetPrivateKey:  tmp["MAILJET_PRIVATE_KEY"],
+		CacheSize:          parseInt("CACHE_SIZE", *tmp["CACHE_SIZE"], 1000),
+		WordIndexSize:      parseInt("WORD_INDEX_SIZE", *tmp["WORD_INDEX_SIZE"], 100),
+		OTPLifeTime:        time.Duration(parseInt("OTP_LIFE_TIME", *tmp["OTP_LIFE_TIME"], 60)) * time.Second,
+		PurgeInterval:      parseDuration("PURGE_INTERVAL", *tmp["PURGE_INTERVAL"], time.Hour),
+		GraphRetention:     parseDuration("GRAPH_RETENTION", *tmp["GRAPH_RETENTION"], 30*24*time.Hour),
+		MetricsAddr:        *tmp["METRICS_ADDR"],
+	}
+	if AppConfig.CacheSize < 0 || AppConfig.WordIndexSize < 0 {
+		panic("CACHE_SIZE and WORD_INDEX_SIZE must not be negative")
+	}
+	if AppConfig.OTPLifeTime <= 0 {
+		panic("OTP_LIFE_TIME must be positive")
+	}
+	if AppConfig.PurgeInterval < 0 || AppConfig.GraphRetention < 0 {
+		panic("PURGE_INTERVAL and GRAPH_RETENTION must not be negative")
+	}
+
+	InitDatabaseConfig()
+}
+
+// Load only the database variables, for the tools
+// which don't serve the API such as the migration command
+func InitDatabaseConfig() {
+	tmp := lookupVariables(map[string]bool{
+		"DB_DRIVER":               false,
+		"DB_HOST":                 false,
+		"DB_PORT":                 false,
+		"DB_USERNAME":             false,
+		"DB_PASSWORD":             false,
+		"DB_PATH":                 false,
+		"DB_READ_TIMEOUT":         false,
+		"DB_WRITE_TIMEOUT":        false,
+		"DB_RETRY_MAX_ATTEMPTS":   false,
+		"DB_RETRY_INITIAL_DELAY":  false,
+		"DB_RETRY_MAX_DELAY":      false,
+		"DB_SLOW_QUERY_THRESHOLD": false,
+	})
+
+	if AppConfig == nil {
+		AppConfig = &Configuration{}
+	}
+	AppConfig.DBDriver = *tmp["DB_DRIVER"]
+	AppConfig.DBHost = *tmp["DB_HOST"]
+	AppConfig.DBPort = *tmp["DB_PORT"]
+	AppConfig.DBUsername = *tmp["DB_USERNAME"]
+	AppConfig.DBPassword = *tmp["DB_PASSWORD"]
+	AppConfig.DBPath = *tmp["DB_PATH"]
+	AppConfig.DBReadTimeout = parseDuration("DB_READ_TIMEOUT", *tmp["DB_READ_TIMEOUT"], 10*time.Second)
+	AppConfig.DBWriteTimeout = parseDuration("DB_WRITE_TIMEOUT", *tmp["DB_WRITE_TIMEOUT"], 10*time.Second)
+	AppConfig.DBRetryMaxAttempts = parseInt("DB_RETRY_MAX_ATTEMPTS", *tmp["DB_RETRY_MAX_ATTEMPTS"], 3)
+	AppConfig.DBRetryInitialDelay = parseDuration("DB_RETRY_INITIAL_DELAY", *tmp["DB_RETRY_INITIAL_DELAY"], 100*time.Millisecond)
+	AppConfig.DBRetryMaxDelay = parseDuration("DB_RETRY_MAX_DELAY", *tmp["DB_RETRY_MAX_DELAY"], 2*time.Second)
+	AppConfig.DBSlowQueryThreshold = parseDuration("DB_SLOW_QUERY_THRESHOLD", *tmp["DB_SLOW_QUERY_THRESHOLD"], 500*time.Millisecond)
+	if AppConfig.DBRetryMaxAttempts < 1 {
+		panic("DB_RETRY_MAX_ATTEMPTS must be at least 1")
+	}
+
+	if AppConfig.DBDriver == "" {
+		AppConfig.DBDriver = DBDriverNeo4J
+	}
+	if AppConfig.DBDriver == DBDriverSQLite && AppConfig.DBPath == "" {
+		AppConfig.DBPath = "mindgra.db"
+	}
+	if AppConfig.DBDriver == DBDriverNeo4J || AppConfig.DBDriver == DBDriverMemgraph {
+		for _, k := range []string{"DB_HOST", "DB_PORT", "DB_USERNAME", "DB_PASSWORD"} {
+			if *tmp[k] == "" {
+				panic(k + " not set")
+			}
+		}
+	}
+}
+
+// Read the environment variables, panic when a required one is not set
+func lookupVariables(variables map[string]bool) map[string]*string {
+	tmp := map[string]*string{}
+	for k, v := range variables {
+		val, found := os.LookupEnv(k)
+		if !found && v {
+			panic(k + " not set")
+		}
+		tmp[k] = &val
+	}
+	return tmp
+}
+
+// Parse a duration such as "1m30s", falling back to def when not set
+func parseDuration(key string, val string, def time.Duration) time.Duration {
+	if val == "" {
+		return def
+	}
+	d, err := time.ParseDuration(val)
+	if err != nil {
+		panic(key + " is not a valid duration")
+	}
+	return d
+}
+
+// Parse an integer, falling back to def when not set
+func parseInt(key string, val string, def int) int {
+	if val == "" {
+		return def
+	}
+	i, err := strconv.Atoi(val)
+	if err != nil {
+		panic(key + " is not a valid integer")
 	}
+	return i
 }
@@ -12,4 +12,14 @@ var (
 	ErrUnauthentication    = errors.New("unAuthentication")
 	ErrUnauthorization     = errors.New("unAuthorization")
 	ErrInvalidCredential   = errors.New("invalidCredential")
+	ErrTimeout             = errors.New("timeout")
 )
+
+// Hide a repository error behind ErrInternalServerError,
+// except a timeout which the client is told about
+func InternalError(err error) error {
+	if errors.Is(err, ErrTimeout) {
+		return ErrTimeout
+	}
+	return ErrInternalServerError
+}
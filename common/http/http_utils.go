@@ -25,6 +25,16 @@ func ErrorResponse(c *gin.Context, err error) {
 			"message": err.Error(),
 		})
 		break
+	case common.ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{
+			"message": err.Error(),
+		})
+		break
+	case common.ErrTimeout:
+		c.JSON(http.StatusGatewayTimeout, gin.H{
+			"message": err.Error(),
+		})
+		break
 	case common.ErrInternalServerError:
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"message": err.Error(),
@@ -35,3 +35,10 @@ func (n Nullable) ToInt64Ptr() *int64 {
 func ToPointer[T any](x T) *T {
 	return &x
 }
+
+func (n Nullable) ToString() string {
+	if str, ok := n.Value.(string); ok {
+		return str
+	}
+	return ""
+}
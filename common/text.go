@@ -0,0 +1,146 @@
+package common
+
+import (
+	"html"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// words ignored by the english analyzer of the full-text index
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true, "to": true,
+	"was": true, "will": true, "with": true,
+}
+
+// letters which don't decompose into a base letter and marks
+var foldedLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+}
+
+// Lower-case a text and strip its diacritics, so "Élan" and "elan" are equal
+func Fold(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if s, ok := foldedLetters[r]; ok {
+			b.WriteString(s)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Split a text into lower-cased terms, stop words removed
+// and plurals reduced, close to the english analyzer of the full-text index
+func Tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	terms := []string{}
+	for _, f := range fields {
+		if stopWords[f] {
+			continue
+		}
+		terms = append(terms, stem(f))
+	}
+	return terms
+}
+
+func stem(term string) string {
+	switch {
+	case len(term) > 4 && strings.HasSuffix(term, "ies"):
+		return term[:len(term)-3] + "y"
+	case len(term) > 3 && strings.HasSuffix(term, "es") && strings.ContainsAny(term[len(term)-3:len(term)-2], "sxz"):
+		return term[:len(term)-2]
+	case len(term) > 3 && strings.HasSuffix(term, "s") && !strings.HasSuffix(term, "ss"):
+		return term[:len(term)-1]
+	}
+	return term
+}
+
+// Escape the HTML of a text and wrap the words matching the terms in <mark> tags.
+// A text longer than size runes is cut to a snippet around the first match.
+// It returns false when no word matches.
+func Highlight(text string, terms []string, size int) (string, bool) {
+	matching := map[string]bool{}
+	for _, t := range terms {
+		matching[t] = true
+	}
+	runes := []rune(text)
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsNumber(r)
+	}
+
+	// the runes [start, end) of the matching words
+	type match struct{ start, end int }
+	matches := []match{}
+	for i := 0; i < len(runes); {
+		if !isWord(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWord(runes[j]) {
+			j++
+		}
+		if w := strings.ToLower(string(runes[i:j])); !stopWords[w] && matching[stem(w)] {
+			matches = append(matches, match{i, j})
+		}
+		i = j
+	}
+	if len(matches) == 0 {
+		return "", false
+	}
+
+	from, to := 0, len(runes)
+	if size > 0 && len(runes) > size {
+		// some context before the first match, cut at spaces
+		from = matches[0].start - size/4
+		if from < 0 {
+			from = 0
+		}
+		if from+size > len(runes) {
+			from = len(runes) - size
+		}
+		for from > 0 && from < matches[0].start && !unicode.IsSpace(runes[from-1]) {
+			from++
+		}
+		to = from + size
+		if to > len(runes) {
+			to = len(runes)
+		}
+		for to < len(runes) && to > matches[0].end && !unicode.IsSpace(runes[to]) {
+			to--
+		}
+	}
+
+	var b strings.Builder
+	if from > 0 {
+		b.WriteString("…")
+	}
+	pos := from
+	for _, m := range matches {
+		if m.start < from || m.end > to {
+			continue
+		}
+		b.WriteString(html.EscapeString(string(runes[pos:m.start])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[m.start:m.end])))
+		b.WriteString("</mark>")
+		pos = m.end
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:to])))
+	if to < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
@@ -2,11 +2,16 @@
 package common
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"math/rand"
+	mathRand "math/rand"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"github.com/s2dio-tech/mindgra-backend/domain"
 
 	"github.com/gin-gonic/gin"
@@ -19,11 +24,31 @@ var letters = []rune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ012345
 func RandString(n int) string {
 	b := make([]rune, n)
 	for i := range b {
-		b[i] = letters[rand.Intn(len(letters))]
+		b[i] = letters[mathRand.Intn(len(letters))]
 	}
 	return string(b)
 }
 
+// Generate an unguessable token from n random bytes
+func NewSecretToken(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Hash a secret token before it is stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Generate a node id, same format as apoc.create.uuid()
+func NewId() string {
+	return uuid.NewString()
+}
+
 // My own Error type that will help return my customized Error info
 //
 //	{"database": {"hello":"no such table", error: "not_exists"}}
@@ -0,0 +1,161 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+)
+
+// Datasource runs Cypher queries against a graph database.
+// Repositories depend on this interface only, so the database can be
+// swapped, wrapped or faked without touching them.
+type Datasource interface {
+	ExecRead(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error)
+	ExecWrite(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error)
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	Dialect() Dialect
+	Disconnect()
+}
+
+// context key of the transaction opened by WithinTransaction
+type txKey struct{}
+
+// explicit transaction shared by the queries of a WithinTransaction call,
+// err keeps the driver error of a failed query, which the caller
+// usually hides behind a domain error, to decide on a retry
+type txState struct {
+	tx  neo4j.ExplicitTransaction
+	err error
+}
+
+// Connect to the graph database selected by DB_DRIVER
+func InitDatasource() Datasource {
+	switch common.AppConfig.DBDriver {
+	case common.DBDriverNeo4J:
+		db := InitNeo4J()
+		return &db
+	case common.DBDriverMemgraph:
+		db := InitMemGraph()
+		return &db
+	default:
+		panic("DB_DRIVER " + common.AppConfig.DBDriver + " is not a graph database")
+	}
+}
+
+// Run a query in a managed transaction, cancelled with ctx
+// or when the timeout of the access mode is over
+func execute(ctx context.Context, driver neo4j.DriverWithContext, mode neo4j.AccessMode, query string, params map[string]any) (records []*neo4j.Record, err error) {
+	timeout, modeName := common.AppConfig.DBReadTimeout, modeRead
+	if mode == neo4j.AccessModeWrite {
+		timeout, modeName = common.AppConfig.DBWriteTimeout, modeWrite
+	}
+	defer func(start time.Time) {
+		observeQuery(query, modeName, params, start, err)
+	}(time.Now())
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// join the unit of work of the caller
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		res, err := state.tx.Run(ctx, query, params)
+		if err != nil {
+			state.err = err
+			return nil, toTimeoutError(err)
+		}
+		records, err := res.Collect(ctx)
+		if err != nil {
+			state.err = err
+			return nil, toTimeoutError(err)
+		}
+		return records, nil
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode: mode,
+	})
+	defer session.Close(ctx)
+
+	work := func(tx neo4j.ManagedTransaction) (any, error) {
+		res, err := tx.Run(ctx, query, params)
+		if err != nil {
+			return nil, err
+		}
+		return res.Collect(ctx)
+	}
+
+	// reads are idempotent and a managed write transaction
+	// is rolled back as a whole, both can run again
+	var result any
+	err = withRetry(ctx, query, func() (err error) {
+		if mode == neo4j.AccessModeWrite {
+			result, err = session.ExecuteWrite(ctx, work, neo4j.WithTxTimeout(timeout))
+		} else {
+			result, err = session.ExecuteRead(ctx, work, neo4j.WithTxTimeout(timeout))
+		}
+		return err
+	})
+	if err != nil {
+		return nil, toTimeoutError(err)
+	}
+	return result.([]*neo4j.Record), nil
+}
+
+// Run fn in an explicit write transaction, queries executed
+// with the context given to fn are part of it.
+// The whole transaction runs again after a transient failure.
+func withinTransaction(ctx context.Context, driver neo4j.DriverWithContext, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*txState); ok {
+		return fn(ctx)
+	}
+
+	session := driver.NewSession(ctx, neo4j.SessionConfig{
+		AccessMode: neo4j.AccessModeWrite,
+	})
+	defer session.Close(context.WithoutCancel(ctx))
+
+	var fnErr error
+	err := withRetry(ctx, "transaction", func() error {
+		fnErr = nil
+		tx, err := session.BeginTransaction(ctx, neo4j.WithTxTimeout(common.AppConfig.DBWriteTimeout))
+		if err != nil {
+			return err
+		}
+		state := &txState{tx: tx}
+		if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+			tx.Rollback(context.WithoutCancel(ctx))
+			// retry on the driver error, return the one of fn
+			fnErr = err
+			if state.err != nil {
+				return state.err
+			}
+			return err
+		}
+		return tx.Commit(ctx)
+	})
+	if fnErr != nil {
+		return fnErr
+	}
+	return toTimeoutError(err)
+}
+
+// Report both a client side deadline and a transaction
+// killed by the server as common.ErrTimeout
+func toTimeoutError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return common.ErrTimeout
+	}
+	var dbErr *neo4j.Neo4jError
+	if errors.As(err, &dbErr) && dbErr.Code == "Neo.ClientError.Transaction.TransactionTimedOut" {
+		return common.ErrTimeout
+	}
+	return err
+}
+
+func disconnect(driver neo4j.DriverWithContext) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	driver.Close(ctx)
+}
@@ -0,0 +1,82 @@
+// Package datasourcetest fakes the neo4j datasource and opens temporary sqlite
+// databases for the tests of the repositories
+package datasourcetest
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+)
+
+// Rows returned for a query from its params
+type Handler func(params map[string]any) []map[string]any
+
+// Fake answers the queries by their name, the comment starting them such as "// link.FindById".
+// A record only holds the columns the query returns, so a value the handler gives
+// but the query misses is not seen by the repository.
+type Fake struct {
+	Handlers map[string]Handler
+	// names of the queries run, in order
+	Queries []string
+	// text of the last query run under each name
+	Statements map[string]string
+}
+
+func NewFake() *Fake {
+	return &Fake{Handlers: map[string]Handler{}, Statements: map[string]string{}}
+}
+
+var (
+	queryName = regexp.MustCompile(`^\s*//\s*(\S+)`)
+	alias     = regexp.MustCompile(`(?i)\bAS\s+(\w+)`)
+)
+
+func (f *Fake) exec(query string, params map[string]any) ([]*neo4j.Record, error) {
+	name := ""
+	if m := queryName.FindStringSubmatch(query); m != nil {
+		name = m[1]
+	}
+	f.Queries = append(f.Queries, name)
+	f.Statements[name] = query
+	handler := f.Handlers[name]
+	if handler == nil {
+		return []*neo4j.Record{}, nil
+	}
+
+	keys := []string{}
+	if i := strings.LastIndex(strings.ToUpper(query), "RETURN"); i >= 0 {
+		for _, m := range alias.FindAllStringSubmatch(query[i:], -1) {
+			keys = append(keys, m[1])
+		}
+	}
+	records := []*neo4j.Record{}
+	for _, row := range handler(params) {
+		values := []any{}
+		for _, k := range keys {
+			values = append(values, row[k])
+		}
+		records = append(records, &neo4j.Record{Keys: keys, Values: values})
+	}
+	return records, nil
+}
+
+func (f *Fake) ExecRead(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return f.exec(query, params)
+}
+
+func (f *Fake) ExecWrite(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return f.exec(query, params)
+}
+
+func (f *Fake) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func (f *Fake) Dialect() datasource.Dialect {
+	return datasource.Neo4JDialect{}
+}
+
+func (f *Fake) Disconnect() {}
@@ -0,0 +1,48 @@
+package datasourcetest
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/migration/migrations"
+)
+
+// Open a sqlite database in a temporary file, with every migration applied
+func NewSQLite(t *testing.T) *datasource.SQLite {
+	t.Helper()
+	if common.AppConfig == nil {
+		common.AppConfig = &common.Configuration{
+			DBDriver:           common.DBDriverSQLite,
+			DBReadTimeout:      10 * time.Second,
+			DBWriteTimeout:     10 * time.Second,
+			DBRetryMaxAttempts: 1,
+		}
+	}
+	path := filepath.Join(t.TempDir(), "mindgra.db")
+
+	src, err := migrations.Source(common.DBDriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, "sqlite://"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	m.Close()
+
+	// the database file is read from the configuration
+	dbPath := common.AppConfig.DBPath
+	common.AppConfig.DBPath = path
+	db := datasource.InitSQLite()
+	common.AppConfig.DBPath = dbPath
+	t.Cleanup(db.Disconnect)
+	return db
+}
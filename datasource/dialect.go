@@ -0,0 +1,62 @@
+package datasource
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Dialect builds the Cypher fragments that differ between graph databases.
+type Dialect interface {
+	// Clause yielding `node` and `score` for the nodes matching the text parameter
+	FullTextSearch(index string, label string, properties []string, param string) string
+	// Value of the text parameter of FullTextSearch matching the words of the user text
+	FullTextQuery(text string) string
+	// Pattern binding pathVar to a shortest path between two bound nodes
+	ShortestPath(pathVar string, from string, to string, relType string) string
+}
+
+type Neo4JDialect struct{}
+
+func (Neo4JDialect) FullTextSearch(index string, label string, properties []string, param string) string {
+	return fmt.Sprintf(`CALL db.index.fulltext.queryNodes("%s", $%s) YIELD node, score`, index, param)
+}
+
+// The text is a Lucene query, each word is quoted so that the query
+// syntax typed by the user, like `foo)` or `a:"b`, is matched as text
+func (Neo4JDialect) FullTextQuery(text string) string {
+	terms := strings.Fields(text)
+	for i, t := range terms {
+		t = strings.ReplaceAll(t, `\`, `\\`)
+		terms[i] = `"` + strings.ReplaceAll(t, `"`, `\"`) + `"`
+	}
+	return strings.Join(terms, " ")
+}
+
+func (Neo4JDialect) ShortestPath(pathVar string, from string, to string, relType string) string {
+	return fmt.Sprintf(`%s = shortestPath((%s)-[:%s*]-(%s))`, pathVar, from, relType, to)
+}
+
+// Memgraph has no Lucene index, text search falls back to a
+// case insensitive substring match scored by the matched property
+type MemgraphDialect struct{}
+
+func (MemgraphDialect) FullTextSearch(index string, label string, properties []string, param string) string {
+	scores := []string{}
+	for i, p := range properties {
+		scores = append(scores, fmt.Sprintf(
+			`CASE WHEN node.%s IS NOT NULL AND toLower(node.%s) CONTAINS toLower($%s) THEN %d ELSE 0 END`,
+			p, p, param, len(properties)-i,
+		))
+	}
+	return fmt.Sprintf(`MATCH (node:%s)
+		WITH node, %s AS score
+		WHERE score > 0`, label, strings.Join(scores, " + "))
+}
+
+func (MemgraphDialect) FullTextQuery(text string) string {
+	return text
+}
+
+func (MemgraphDialect) ShortestPath(pathVar string, from string, to string, relType string) string {
+	return fmt.Sprintf(`%s = (%s)-[:%s *BFS]-(%s)`, pathVar, from, relType, to)
+}
@@ -0,0 +1,17 @@
+package datasource
+
+import "testing"
+
+func TestNeo4JFullTextQueryQuotesTheWords(t *testing.T) {
+	for text, want := range map[string]string{
+		"rain flood":   `"rain" "flood"`,
+		"foo)":         `"foo)"`,
+		`a:"b`:         `"a:\"b"`,
+		`C:\ AND OR*~`: `"C:\\" "AND" "OR*~"`,
+		"  ":           "",
+	} {
+		if got := (Neo4JDialect{}).FullTextQuery(text); got != want {
+			t.Errorf("FullTextQuery(%q) = %s, want %s", text, got, want)
+		}
+	}
+}
@@ -1,29 +1,25 @@
 package datasource
 
 import (
-	"os"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
 )
 
 type MemGraph struct {
-	Driver neo4j.Driver
+	Driver neo4j.DriverWithContext
 }
 
 func InitMemGraph() MemGraph {
-	uri, found := os.LookupEnv("MEMGRAPH_URI")
-	if !found {
-		panic("MEMGRAPH_URI not set")
-	}
-	username, found := os.LookupEnv("MEMGRAPH_USERNAME")
-	if !found {
-		panic("MEMGRAPH_USERNAME not set")
-	}
-	password, found := os.LookupEnv("MEMGRAPH_PASSWORD")
-	if !found {
-		panic("MEMGRAPH_PASSWORD not set")
-	}
-	driver, err := neo4j.NewDriver(uri, neo4j.BasicAuth(username, password, ""))
+	driver, err := neo4j.NewDriverWithContext(
+		"bolt://"+common.AppConfig.DBHost+":"+common.AppConfig.DBPort,
+		neo4j.BasicAuth(common.AppConfig.DBUsername, common.AppConfig.DBPassword, ""),
+		disableDriverRetries,
+	)
 	if err != nil {
 		panic(err)
 	}
@@ -32,52 +28,100 @@ func InitMemGraph() MemGraph {
 	}
 }
 
-func (m *MemGraph) ExecRead(query string, params map[string]any) ([]*neo4j.Record, error) {
-	session := m.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-	defer session.Close()
+func (m *MemGraph) ExecRead(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return execute(ctx, m.Driver, neo4j.AccessModeRead, query, params)
+}
 
-	result, err := session.Run(query, params)
+func (m *MemGraph) ExecWrite(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return execute(ctx, m.Driver, neo4j.AccessModeWrite, query, params)
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (m *MemGraph) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, m.Driver, fn)
+}
 
-	records, err := result.(neo4j.Result).Collect()
-	if err != nil {
-		return nil, err
-	}
+func (m *MemGraph) Dialect() Dialect {
+	return MemgraphDialect{}
+}
 
-	return records, err
+func (m *MemGraph) Disconnect() {
+	disconnect(m.Driver)
 }
 
-func (m *MemGraph) ExecWrite(query string, params map[string]any) ([]*neo4j.Record, error) {
-	session := m.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-	defer session.Close()
+var (
+	memgraphConstraint = regexp.MustCompile(`^CREATE CONSTRAINT ON \(\w+:(\w+)\) ASSERT \w+\.(\w+) IS UNIQUE;$`)
+	memgraphIndex      = regexp.MustCompile(`^CREATE INDEX ON :(\w+)\((\w+)\);$`)
+)
 
-	result, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		res, err := tx.Run(query, params)
-		if err != nil {
-			return nil, err
-		}
+// Fail when a constraint or an index of the schema statements is missing.
+// The schema queries of memgraph can't run in a transaction, they are auto-committed.
+func (m *MemGraph) CheckSchema(ctx context.Context, schema string) error {
+	session := m.Driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead})
+	defer session.Close(ctx)
 
-		records, err := res.(neo4j.Result).Collect()
+	show := func(query string) ([]*neo4j.Record, error) {
+		res, err := session.Run(ctx, query, nil)
 		if err != nil {
 			return nil, err
 		}
-		return records, nil
-	})
-
+		return res.Collect(ctx)
+	}
+	constraints, err := show("SHOW CONSTRAINT INFO;")
+	if err != nil {
+		return err
+	}
+	indexes, err := show("SHOW INDEX INFO;")
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return result.([]*neo4j.Record), err
+	if missing := missingSchema(schema, constraints, indexes); len(missing) > 0 {
+		return fmt.Errorf("memgraph schema is missing, apply migration/migrations/memgraph/schema.cypher:\n%s", strings.Join(missing, "\n"))
+	}
+	return nil
 }
 
-func (m *MemGraph) Disconnect() {
-	m.Driver.Close()
+// The statements of the schema whose constraint or index is not in the records
+// of SHOW CONSTRAINT INFO and SHOW INDEX INFO
+func missingSchema(schema string, constraints []*neo4j.Record, indexes []*neo4j.Record) []string {
+	found := map[string]bool{}
+	for _, r := range constraints {
+		kind, _ := r.Get("constraint type")
+		label, _ := r.Get("label")
+		properties, _ := r.Get("properties")
+		found[fmt.Sprintf("%v %v(%s)", kind, label, propertyNames(properties))] = true
+	}
+	for _, r := range indexes {
+		label, _ := r.Get("label")
+		property, _ := r.Get("property")
+		found[fmt.Sprintf("index %v(%s)", label, propertyNames(property))] = true
+	}
+
+	missing := []string{}
+	for _, line := range strings.Split(schema, "\n") {
+		line = strings.TrimSpace(line)
+		key := ""
+		if m := memgraphConstraint.FindStringSubmatch(line); m != nil {
+			key = fmt.Sprintf("unique %s(%s)", m[1], m[2])
+		} else if m := memgraphIndex.FindStringSubmatch(line); m != nil {
+			key = fmt.Sprintf("index %s(%s)", m[1], m[2])
+		}
+		if key != "" && !found[key] {
+			missing = append(missing, line)
+		}
+	}
+	return missing
+}
+
+// Properties of a schema record, a name or a list of them
+func propertyNames(v any) string {
+	list, ok := v.([]any)
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	names := []string{}
+	for _, p := range list {
+		names = append(names, fmt.Sprint(p))
+	}
+	return strings.Join(names, ",")
 }
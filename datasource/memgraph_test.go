@@ -0,0 +1,42 @@
+package datasource
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/migration/migrations"
+)
+
+func TestMissingSchema(t *testing.T) {
+	schema := `// comment
+CREATE CONSTRAINT ON (n:User) ASSERT n.email IS UNIQUE;
+CREATE CONSTRAINT ON (n:Word) ASSERT n.id IS UNIQUE;
+CREATE INDEX ON :Link(word1Id);
+CREATE INDEX ON :Token(userId);
+`
+	constraints := []*neo4j.Record{
+		{Keys: []string{"constraint type", "label", "properties"}, Values: []any{"unique", "User", []any{"email"}}},
+		// an existence constraint is not the unique one
+		{Keys: []string{"constraint type", "label", "properties"}, Values: []any{"exists", "Word", "id"}},
+	}
+	indexes := []*neo4j.Record{
+		{Keys: []string{"index type", "label", "property", "count"}, Values: []any{"label+property", "Link", "word1Id", int64(0)}},
+		{Keys: []string{"index type", "label", "property", "count"}, Values: []any{"label+property", "Token", []any{"userId"}, int64(0)}},
+	}
+
+	missing := missingSchema(schema, constraints, indexes)
+	want := []string{"CREATE CONSTRAINT ON (n:Word) ASSERT n.id IS UNIQUE;"}
+	if !slices.Equal(missing, want) {
+		t.Fatalf("missingSchema() = %q, want %q", missing, want)
+	}
+}
+
+func TestMissingSchemaReadsTheSchemaFile(t *testing.T) {
+	// with an empty database every statement of the file is missing
+	statements := strings.Count(migrations.MemgraphSchema, "\nCREATE ")
+	if missing := missingSchema(migrations.MemgraphSchema, nil, nil); len(missing) != statements {
+		t.Fatalf("missingSchema() found %d of the %d statements", len(missing), statements)
+	}
+}
@@ -0,0 +1,198 @@
+package datasource
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// MemoryGraph is a graph node kept by the in-memory datasource.
+type MemoryGraph struct {
+	domain.Graph
+	DeleteFlag bool
+}
+
+// MemoryRelation is a CONCERN relationship between two words.
+// Id is set when a Link annotation is attached to the relationship.
+type MemoryRelation struct {
+	Id      *string
+	StartId string
+	EndId   string
+}
+
+// MemoryTrashedRelation is a relationship moved to the trash,
+// TrashedWith is the word it was trashed with, nil when
+// the relationship was deleted on its own.
+type MemoryTrashedRelation struct {
+	MemoryRelation
+	TrashedWith *string
+	DeletedBy   string
+	DeletedAt   time.Time
+}
+
+// Memory keeps every node and relationship in process memory.
+// It is meant for local development and tests, data is lost on exit.
+type Memory struct {
+	mu        sync.RWMutex
+	Users     map[string]*domain.User
+	Tokens    map[string]*domain.Token
+	Graphs    map[string]*MemoryGraph
+	Words     map[string]*domain.Word
+	Links     map[string]*domain.Link
+	Relations []*MemoryRelation
+	Members   map[string]*domain.GraphMember
+	Shares    map[string]*domain.ShareLink
+	Revisions map[string]*domain.Revision
+	// the deleted words, relationships and links of the graphs
+	TrashedWords     map[string]*domain.TrashedWord
+	TrashedRelations []*MemoryTrashedRelation
+	TrashedLinks     map[string]*domain.Link
+}
+
+// context key marking the calls made inside WithinTransaction
+type memoryTxKey struct{}
+
+func InitMemory() *Memory {
+	return &Memory{
+		Users:     map[string]*domain.User{},
+		Tokens:    map[string]*domain.Token{},
+		Graphs:    map[string]*MemoryGraph{},
+		Words:     map[string]*domain.Word{},
+		Links:     map[string]*domain.Link{},
+		Relations: []*MemoryRelation{},
+		Members:   map[string]*domain.GraphMember{},
+		Shares:    map[string]*domain.ShareLink{},
+		Revisions: map[string]*domain.Revision{},
+
+		TrashedWords:     map[string]*domain.TrashedWord{},
+		TrashedRelations: []*MemoryTrashedRelation{},
+		TrashedLinks:     map[string]*domain.Link{},
+	}
+}
+
+func (m *Memory) inTransaction(ctx context.Context) bool {
+	tx, _ := ctx.Value(memoryTxKey{}).(*Memory)
+	return tx == m
+}
+
+// Lock the store for writing and return the unlock function.
+// Inside a transaction the lock is already held.
+func (m *Memory) WriteLock(ctx context.Context) func() {
+	if m.inTransaction(ctx) {
+		return func() {}
+	}
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+// Lock the store for reading and return the unlock function.
+func (m *Memory) ReadLock(ctx context.Context) func() {
+	if m.inTransaction(ctx) {
+		return func() {}
+	}
+	m.mu.RLock()
+	return m.mu.RUnlock
+}
+
+// Run fn holding the write lock, the store is restored
+// to its previous state when fn fails
+func (m *Memory) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.inTransaction(ctx) {
+		return fn(ctx)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	backup := m.snapshot()
+	if err := fn(context.WithValue(ctx, memoryTxKey{}, m)); err != nil {
+		m.restore(backup)
+		return err
+	}
+	return nil
+}
+
+func (m *Memory) snapshot() *Memory {
+	s := &Memory{
+		Users:     make(map[string]*domain.User, len(m.Users)),
+		Tokens:    make(map[string]*domain.Token, len(m.Tokens)),
+		Graphs:    make(map[string]*MemoryGraph, len(m.Graphs)),
+		Words:     make(map[string]*domain.Word, len(m.Words)),
+		Links:     make(map[string]*domain.Link, len(m.Links)),
+		Relations: make([]*MemoryRelation, 0, len(m.Relations)),
+		Members:   make(map[string]*domain.GraphMember, len(m.Members)),
+		Shares:    make(map[string]*domain.ShareLink, len(m.Shares)),
+		Revisions: make(map[string]*domain.Revision, len(m.Revisions)),
+
+		TrashedWords:     make(map[string]*domain.TrashedWord, len(m.TrashedWords)),
+		TrashedRelations: make([]*MemoryTrashedRelation, 0, len(m.TrashedRelations)),
+		TrashedLinks:     make(map[string]*domain.Link, len(m.TrashedLinks)),
+	}
+	for k, v := range m.Users {
+		c := *v
+		s.Users[k] = &c
+	}
+	for k, v := range m.Tokens {
+		c := *v
+		s.Tokens[k] = &c
+	}
+	for k, v := range m.Graphs {
+		c := *v
+		s.Graphs[k] = &c
+	}
+	for k, v := range m.Words {
+		c := *v
+		s.Words[k] = &c
+	}
+	for k, v := range m.Links {
+		c := *v
+		s.Links[k] = &c
+	}
+	for _, v := range m.Relations {
+		c := *v
+		s.Relations = append(s.Relations, &c)
+	}
+	for k, v := range m.Members {
+		c := *v
+		s.Members[k] = &c
+	}
+	for k, v := range m.Shares {
+		c := *v
+		s.Shares[k] = &c
+	}
+	// the revisions are never updated, the copy of the map is enough
+	for k, v := range m.Revisions {
+		s.Revisions[k] = v
+	}
+	for k, v := range m.TrashedWords {
+		c := *v
+		s.TrashedWords[k] = &c
+	}
+	for _, v := range m.TrashedRelations {
+		c := *v
+		s.TrashedRelations = append(s.TrashedRelations, &c)
+	}
+	for k, v := range m.TrashedLinks {
+		c := *v
+		s.TrashedLinks[k] = &c
+	}
+	return s
+}
+
+func (m *Memory) restore(s *Memory) {
+	m.Users = s.Users
+	m.Tokens = s.Tokens
+	m.Graphs = s.Graphs
+	m.Words = s.Words
+	m.Links = s.Links
+	m.Relations = s.Relations
+	m.Members = s.Members
+	m.Shares = s.Shares
+	m.Revisions = s.Revisions
+	m.TrashedWords = s.TrashedWords
+	m.TrashedRelations = s.TrashedRelations
+	m.TrashedLinks = s.TrashedLinks
+}
+
+func (m *Memory) Disconnect() {}
@@ -0,0 +1,120 @@
+package datasource
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"golang.org/x/exp/slog"
+)
+
+// access modes of the metrics labels
+const (
+	modeRead  = "read"
+	modeWrite = "write"
+)
+
+var (
+	queryTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mindgra_db_queries_total",
+		Help: "Database queries by name, access mode and status.",
+	}, []string{"query", "mode", "status"})
+
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mindgra_db_query_duration_seconds",
+		Help:    "Duration of the database queries, retries included.",
+		Buckets: []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"query", "mode"})
+
+	queryRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mindgra_db_query_retries_total",
+		Help: "Database queries run again after a transient error.",
+	}, []string{"query"})
+)
+
+// Name of a query, given by its leading comment such as
+// "// word.FindById" in Cypher or "-- word.FindById" in SQL,
+// the first line of the query otherwise
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if i := strings.IndexByte(query, '\n'); i >= 0 {
+		query = query[:i]
+	}
+	for _, prefix := range []string{"//", "--"} {
+		if strings.HasPrefix(query, prefix) {
+			return strings.TrimSpace(strings.TrimPrefix(query, prefix))
+		}
+	}
+	return query
+}
+
+// Record the duration and the status of a query,
+// and log it when it is slower than DB_SLOW_QUERY_THRESHOLD
+func observeQuery(query string, mode string, params any, start time.Time, err error) {
+	name := queryName(query)
+	elapsed := time.Since(start)
+
+	status := "ok"
+	if errors.Is(err, common.ErrTimeout) {
+		status = "timeout"
+	} else if err != nil {
+		status = "error"
+	}
+	queryTotal.WithLabelValues(name, mode, status).Inc()
+	queryDuration.WithLabelValues(name, mode).Observe(elapsed.Seconds())
+
+	threshold := common.AppConfig.DBSlowQueryThreshold
+	if threshold > 0 && elapsed >= threshold {
+		slog.Warn("slow database query",
+			"query", name,
+			"mode", mode,
+			"duration", elapsed,
+			"status", status,
+			"params", redactParams(params),
+		)
+	}
+}
+
+// Keep the shape of the query parameters for the logs, never their values
+func redactParams(params any) any {
+	switch p := params.(type) {
+	case map[string]any:
+		res := make(map[string]string, len(p))
+		for k, v := range p {
+			res[k] = redactValue(v)
+		}
+		return res
+	case []any:
+		res := make([]string, len(p))
+		for i, v := range p {
+			res[i] = redactValue(v)
+		}
+		return res
+	default:
+		return nil
+	}
+}
+
+func redactValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return "null"
+		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return fmt.Sprintf("%s(%d)", rv.Type(), rv.Len())
+	default:
+		return rv.Type().String()
+	}
+}
@@ -1,18 +1,21 @@
 package datasource
 
 import (
+	"context"
+
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 	"github.com/s2dio-tech/mindgra-backend/common"
 )
 
 type Neo4J struct {
-	Driver neo4j.Driver
+	Driver neo4j.DriverWithContext
 }
 
 func InitNeo4J() Neo4J {
-	driver, err := neo4j.NewDriver(
+	driver, err := neo4j.NewDriverWithContext(
 		"neo4j://"+common.AppConfig.DBHost+":"+common.AppConfig.DBPort,
 		neo4j.BasicAuth(common.AppConfig.DBUsername, common.AppConfig.DBPassword, ""),
+		disableDriverRetries,
 	)
 	if err != nil {
 		panic(err)
@@ -22,52 +25,22 @@ func InitNeo4J() Neo4J {
 	}
 }
 
-func (m *Neo4J) ExecRead(query string, params map[string]any) ([]*neo4j.Record, error) {
-	session := m.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-	defer session.Close()
-
-	result, err := session.Run(query, params)
-
-	if err != nil {
-		return nil, err
-	}
-
-	records, err := result.Collect()
-	if err != nil {
-		return nil, err
-	}
-
-	return records, err
+func (m *Neo4J) ExecRead(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return execute(ctx, m.Driver, neo4j.AccessModeRead, query, params)
 }
 
-func (m *Neo4J) ExecWrite(query string, params map[string]any) ([]*neo4j.Record, error) {
-	session := m.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-	defer session.Close()
-
-	result, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		res, err := tx.Run(query, params)
-		if err != nil {
-			return nil, err
-		}
-
-		records, err := res.Collect()
-		if err != nil {
-			return nil, err
-		}
-		return records, nil
-	})
+func (m *Neo4J) ExecWrite(ctx context.Context, query string, params map[string]any) ([]*neo4j.Record, error) {
+	return execute(ctx, m.Driver, neo4j.AccessModeWrite, query, params)
+}
 
-	if err != nil {
-		return nil, err
-	}
+func (m *Neo4J) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTransaction(ctx, m.Driver, fn)
+}
 
-	return result.([]*neo4j.Record), err
+func (m *Neo4J) Dialect() Dialect {
+	return Neo4JDialect{}
 }
 
 func (m *Neo4J) Disconnect() {
-	m.Driver.Close()
+	disconnect(m.Driver)
 }
@@ -0,0 +1,77 @@
+package datasource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"syscall"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"golang.org/x/exp/slog"
+)
+
+// The driver retries managed transactions on its own for 30 seconds,
+// it is turned off so DB_RETRY_* is the only retry policy
+func disableDriverRetries(config *neo4j.Config) {
+	config.MaxTransactionRetryTime = 0
+}
+
+// Tell a transient failure, such as a leader switch, a dropped
+// connection or a deadlock, from a permanent one like a syntax error
+// or a constraint violation. Timeouts are never retried.
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	// the driver gave up after its single attempt
+	var limit *neo4j.TransactionExecutionLimit
+	if errors.As(err, &limit) {
+		if len(limit.Errors) == 0 {
+			return false
+		}
+		return isRetryable(limit.Errors[len(limit.Errors)-1])
+	}
+	if neo4j.IsRetryable(err) {
+		return true
+	}
+	// a connection closed under the driver
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// Run fn until it succeeds, fails with a permanent error, ctx is done or
+// DB_RETRY_MAX_ATTEMPTS is reached. The wait between two attempts is drawn
+// at random below an exponential backoff capped by DB_RETRY_MAX_DELAY.
+func withRetry(ctx context.Context, query string, fn func() error) error {
+	delay := common.AppConfig.DBRetryInitialDelay
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			if attempt > 1 {
+				slog.Info("database query succeeded after retries", "query", queryName(query), "attempts", attempt)
+			}
+			return nil
+		}
+		if !isRetryable(err) {
+			return err
+		}
+		if attempt >= common.AppConfig.DBRetryMaxAttempts {
+			slog.Error("database query failed after retries", "query", queryName(query), "attempts", attempt, "error", err)
+			return err
+		}
+
+		queryRetries.WithLabelValues(queryName(query)).Inc()
+		wait := time.Duration(rand.Int63n(int64(delay) + 1))
+		slog.Warn("retrying database query", "query", queryName(query), "attempt", attempt, "wait", wait, "error", err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay = min(2*delay, common.AppConfig.DBRetryMaxDelay)
+	}
+}
@@ -0,0 +1,85 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+// label of the nodes golang-migrate records the applied version in
+const schemaMigrationLabel = "SchemaMigration"
+
+// Read the schema version applied by the migrations, 0 when none ran
+func SchemaVersion(ctx context.Context, db Datasource) (version uint, dirty bool, err error) {
+	result, err := db.ExecRead(
+		ctx,
+		`// schema.Version
+		MATCH (sm:`+schemaMigrationLabel+`)
+		RETURN sm.version AS version, sm.dirty AS dirty
+		ORDER BY COALESCE(sm.ts, datetime({year: 0})) DESC, sm.version DESC
+		LIMIT 1;`,
+		nil,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	if len(result) == 0 {
+		return 0, false, nil
+	}
+
+	_version, _ := result[0].Get("version")
+	_dirty, _ := result[0].Get("dirty")
+	if v, ok := _version.(int64); ok {
+		version = uint(v)
+	}
+	if d, ok := _dirty.(bool); ok {
+		dirty = d
+	}
+	return version, dirty, nil
+}
+
+// Fail when the database schema is dirty or behind the expected version
+func CheckSchemaVersion(ctx context.Context, db Datasource, expected uint) error {
+	version, dirty, err := SchemaVersion(ctx, db)
+	if err != nil {
+		return err
+	}
+	return checkSchemaVersion(version, dirty, expected)
+}
+
+// Read the schema version applied by the sqlite migrations, 0 when none ran
+func (s *SQLite) SchemaVersion(ctx context.Context) (version uint, dirty bool, err error) {
+	err = s.ExecRead(
+		ctx,
+		`-- schema.Version
+		SELECT version, dirty FROM schema_migrations LIMIT 1`,
+		func(rows *sql.Rows) error {
+			return rows.Scan(&version, &dirty)
+		},
+	)
+	// the table is created by the first migration run
+	if err != nil && strings.Contains(err.Error(), "no such table") {
+		return 0, false, nil
+	}
+	return version, dirty, err
+}
+
+// Fail when the sqlite schema is dirty or behind the expected version
+func (s *SQLite) CheckSchemaVersion(ctx context.Context, expected uint) error {
+	version, dirty, err := s.SchemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+	return checkSchemaVersion(version, dirty, expected)
+}
+
+func checkSchemaVersion(version uint, dirty bool, expected uint) error {
+	if dirty {
+		return fmt.Errorf("database schema version %d is dirty, fix the failed migration", version)
+	}
+	if version < expected {
+		return fmt.Errorf("database schema version %d is behind %d, run the migrations", version, expected)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	_ "modernc.org/sqlite"
+)
+
+// SQLite keeps the data in a single database file,
+// for the deployments which can't operate a graph database.
+// The tables are created by the sqlite migrations.
+type SQLite struct {
+	DB *sql.DB
+}
+
+// context key of the transaction opened by SQLite.WithinTransaction
+type sqlTxKey struct{}
+
+// queries run either on the database or inside a transaction
+type sqlQuerier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func InitSQLite() *SQLite {
+	// transactions take the write lock when they begin,
+	// so two of them never wait on each other to upgrade
+	params := url.Values{}
+	params.Add("_pragma", "foreign_keys(1)")
+	params.Add("_pragma", "journal_mode(WAL)")
+	params.Add("_pragma", "busy_timeout(5000)")
+	params.Set("_txlock", "immediate")
+
+	db, err := sql.Open("sqlite", "file:"+common.AppConfig.DBPath+"?"+params.Encode())
+	if err != nil {
+		panic(err)
+	}
+	if err := db.Ping(); err != nil {
+		panic(err)
+	}
+	return &SQLite{
+		DB: db,
+	}
+}
+
+func (s *SQLite) querier(ctx context.Context) sqlQuerier {
+	if tx, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return s.DB
+}
+
+// Run a read query and call scan for each row,
+// cancelled with ctx or when the read timeout is over
+func (s *SQLite) ExecRead(ctx context.Context, query string, scan func(rows *sql.Rows) error, args ...any) (err error) {
+	defer func(start time.Time) {
+		observeQuery(query, modeRead, args, start, err)
+	}(time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, common.AppConfig.DBReadTimeout)
+	defer cancel()
+
+	rows, err := s.querier(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return toSQLTimeoutError(ctx, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return toSQLTimeoutError(ctx, rows.Err())
+}
+
+// Run a write query, cancelled with ctx or when the write timeout is over
+func (s *SQLite) ExecWrite(ctx context.Context, query string, args ...any) (res sql.Result, err error) {
+	defer func(start time.Time) {
+		observeQuery(query, modeWrite, args, start, err)
+	}(time.Now())
+
+	ctx, cancel := context.WithTimeout(ctx, common.AppConfig.DBWriteTimeout)
+	defer cancel()
+
+	res, err = s.querier(ctx).ExecContext(ctx, query, args...)
+	if err != nil {
+		return nil, toSQLTimeoutError(ctx, err)
+	}
+	return res, nil
+}
+
+// Run fn in a transaction, queries executed
+// with the context given to fn are part of it
+func (s *SQLite) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(sqlTxKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, common.AppConfig.DBWriteTimeout)
+	defer cancel()
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return toSQLTimeoutError(ctx, err)
+	}
+	if err := fn(context.WithValue(ctx, sqlTxKey{}, tx)); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return toSQLTimeoutError(ctx, tx.Commit())
+}
+
+func (s *SQLite) Disconnect() {
+	s.DB.Close()
+}
+
+// The driver reports an interrupted query rather than
+// the context error, so the deadline is read from ctx
+func toSQLTimeoutError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return common.ErrTimeout
+	}
+	return err
+}
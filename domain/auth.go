@@ -28,10 +28,12 @@ type Token struct {
 }
 
 type TokenRepository interface {
-	Store(*Token) (*string, error)
-	DeleteByTypeAndUserId(tType TokenType, userId string) error
-	FindToken(tokenType TokenType, token string, userId string) (*Token, error)
-	FindOne(tokenType TokenType, userId string) (*Token, error)
+	Store(c context.Context, t *Token) (*string, error)
+	DeleteByTypeAndUserId(c context.Context, tType TokenType, userId string) error
+	FindToken(c context.Context, tokenType TokenType, token string, userId string) (*Token, error)
+	FindOne(c context.Context, tokenType TokenType, userId string) (*Token, error)
+	// Remove the tokens of the type created before the given time, and count them
+	DeleteCreatedBefore(c context.Context, tokenType TokenType, before time.Time) (int, error)
 }
 
 type AuthUsecase interface {
@@ -29,4 +29,5 @@ type EmailUsecase interface {
 	SendEmail(context.Context, Email) error
 	GetOTPMailTemplate(string, string) *EmailTemplate
 	GetPasswordChangedMailTemplate(string) *EmailTemplate
+	GetGraphInvitationMailTemplate(name string, inviterName string, graphName string, graphId string) *EmailTemplate
 }
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// version of the GraphExport document, raised on incompatible changes
+const GraphExportVersion = 1
+
+// GraphExport is the whole content of a graph, written to the export files.
+// The relationships between the words are in Relations,
+// the links annotating some of them in Links.
+type GraphExport struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exportedAt"`
+	Graph      Graph       `json:"graph"`
+	Words      []Word      `json:"words"`
+	Relations  []WordsLink `json:"relations"`
+	Links      []Link      `json:"links"`
+}
+
+type ExportUsecase interface {
+	Export(c context.Context, graphId string, user Profile) (*GraphExport, error)
+}
@@ -5,27 +5,74 @@ import (
 	"time"
 )
 
+type GraphVisibility string
+
+const (
+	// only the owner and the members read the graph
+	GraphVisibilityPrivate GraphVisibility = "private"
+	// anyone knowing the graph id reads it
+	GraphVisibilityUnlisted GraphVisibility = "unlisted"
+	// anyone reads the graph, its words show in the search results
+	GraphVisibilityPublic GraphVisibility = "public"
+)
+
+var GraphVisibilityMap = map[string]GraphVisibility{
+	"private":  GraphVisibilityPrivate,
+	"unlisted": GraphVisibilityUnlisted,
+	"public":   GraphVisibilityPublic,
+}
+
 type Graph struct {
-	Id        string     `json:"id"`
-	UserId    string     `json:"userId"`
-	Name      string     `json:"name"`
-	Type      string     `json:"type"`
-	CreatedAt *time.Time `json:"createdAt"`
-	UpdatedAt *time.Time `json:"updatedAt"`
+	Id         string          `json:"id"`
+	UserId     string          `json:"userId"`
+	Name       string          `json:"name"`
+	Type       string          `json:"type"`
+	Visibility GraphVisibility `json:"visibility"`
+	OriginId   *string         `json:"originId"`
+	CreatedAt  *time.Time      `json:"createdAt"`
+	UpdatedAt  *time.Time      `json:"updatedAt"`
+	// set on the deleted graphs, the ones deleted before it was kept have none
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
 }
 
 type GraphRepository interface {
-	Select(userId string) ([]Graph, error)
-	SelectOne(id string) (*Graph, error)
-	Store(r Graph) (*string, error)
-	Update(id string, graph Graph) error
-	Delete(id string) error
+	Select(c context.Context, userId string) ([]Graph, error)
+	SelectOne(c context.Context, id string) (*Graph, error)
+	Store(c context.Context, r Graph) (*string, error)
+	Update(c context.Context, id string, graph Graph) error
+	// flag the graph as deleted, its data is kept until the graph is purged
+	Delete(c context.Context, id string) error
+	// the deleted graphs created by the user or shared with them as an owner
+	SelectDeleted(c context.Context, userId string) ([]Graph, error)
+	// the deleted graph of the id, nil when it is not deleted
+	SelectDeletedOne(c context.Context, id string) (*Graph, error)
+	// clear the delete flag of the graph
+	Restore(c context.Context, id string) error
+	// the graphs of every user deleted before the given time
+	SelectDeletedBefore(c context.Context, before time.Time) ([]Graph, error)
+	// Remove the deleted graph for good with its words, links,
+	// relationships, revisions, members and share links
+	Purge(c context.Context, id string) (*PurgeReport, error)
+	// Store the graph with a copy of the words, relationships and links
+	// of the graph id, under fresh ids
+	Clone(c context.Context, id string, graph Graph) (*string, error)
 }
 
 type GraphUsecase interface {
 	List(c context.Context, user Profile) ([]Graph, error)
-	Get(c context.Context, id string) (*Graph, error)
+	Get(c context.Context, id string, user Profile) (*Graph, error)
 	Create(c context.Context, graph Graph, user Profile) (res *string, err error)
 	Update(c context.Context, id string, graph Graph, user Profile) error
 	Delete(c context.Context, id string, user Profile) error
+	Clone(c context.Context, id string, name string, user Profile) (*Graph, error)
+	ListDeleted(c context.Context, user Profile) ([]Graph, error)
+	Restore(c context.Context, id string, user Profile) (*Graph, error)
+}
+
+// GraphCache drops the cached graphs once a write has been committed
+type GraphCache interface {
+	// the graph changed, its data as well
+	InvalidateGraph(c context.Context, graphIds ...string)
+	// the words or the links of the graph changed
+	InvalidateGraphData(c context.Context, graphIds ...string)
 }
@@ -0,0 +1,27 @@
+package domain
+
+import "context"
+
+// ImportError tells why a row of the imported file was left out
+type ImportError struct {
+	// place of the row in the file, e.g. "nodes.csv:4"
+	Row     string `json:"row"`
+	Message string `json:"message"`
+}
+
+type ImportResult struct {
+	GraphId string `json:"graphId"`
+	// words created and words of the graph which matched imported ones
+	Words       int           `json:"words"`
+	MergedWords int           `json:"mergedWords"`
+	Relations   int           `json:"relations"`
+	Links       int           `json:"links"`
+	Errors      []ImportError `json:"errors"`
+}
+
+type ImportUsecase interface {
+	// Import the words, relationships and links into the graph,
+	// or into a new graph named after data.Graph when graphId is empty.
+	// The data is already validated, its ids are the ones of the file.
+	Import(c context.Context, data GraphExport, graphId string, user Profile) (*ImportResult, error)
+}
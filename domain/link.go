@@ -6,29 +6,31 @@ import (
 )
 
 type Link struct {
-	Id          string
-	Word1Id     string
-	Word2Id     string
-	UserId      string
-	Content     string
-	Description *string
-	Refs        *[]string
-	CreatedAt   time.Time
-	UpdatedAt   *time.Time
+	Id          string     `json:"id"`
+	Word1Id     string     `json:"word1Id"`
+	Word2Id     string     `json:"word2Id"`
+	UserId      string     `json:"userId"`
+	Content     string     `json:"content"`
+	Description *string    `json:"description"`
+	Refs        *[]string  `json:"refs"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt"`
 }
 
 type LinkRepository interface {
-	FindById(id string) (*Link, error)
-	FindByWordIds(w1Id string, w2Id string) (*Link, error)
-	Store(r Link) (*string, error)
-	Update(id string, link Link) error
+	FindById(c context.Context, id string) (*Link, error)
+	FindByWordIds(c context.Context, w1Id string, w2Id string) (*Link, error)
+	// the links annotating the relationships of the words of the graph
+	FindByGraphId(c context.Context, graphId string) ([]Link, error)
+	Store(c context.Context, r Link) (*string, error)
+	Update(c context.Context, id string, link Link) error
 	// Delete(id string) error
-	Delete(w1Id string, w2Id string) error
+	Delete(c context.Context, w1Id string, w2Id string) error
 }
 
 type LinkUsecase interface {
-	GetDetail(id string) (*Link, error)
-	GetDetailByWordIds(w1id string, w2id string) (*Link, error)
+	GetDetail(c context.Context, id string, user Profile) (*Link, error)
+	GetDetailByWordIds(c context.Context, w1id string, w2id string, user Profile) (*Link, error)
 	Create(c context.Context, w1Id string, w2Id string, r Link, user Profile) (res *string, err error)
 	Update(c context.Context, id string, link Link, user Profile) error
 	Delete(c context.Context, w1Id string, w2Id string, user Profile) error
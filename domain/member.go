@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type GraphRole string
+
+const (
+	GraphRoleViewer GraphRole = "viewer"
+	GraphRoleEditor GraphRole = "editor"
+	GraphRoleOwner  GraphRole = "owner"
+)
+
+var GraphRoleMap = map[string]GraphRole{
+	"viewer": GraphRoleViewer,
+	"editor": GraphRoleEditor,
+	"owner":  GraphRoleOwner,
+}
+
+// each role is granted the permissions of the lower ones
+var graphRoleRank = map[GraphRole]int{
+	GraphRoleViewer: 1,
+	GraphRoleEditor: 2,
+	GraphRoleOwner:  3,
+}
+
+// Includes reports whether the role grants the permissions of other
+func (r GraphRole) Includes(other GraphRole) bool {
+	return graphRoleRank[r] > 0 && graphRoleRank[r] >= graphRoleRank[other]
+}
+
+type MemberStatus string
+
+const (
+	MemberStatusPending  MemberStatus = "pending"
+	MemberStatusAccepted MemberStatus = "accepted"
+)
+
+// GraphMember gives a user a role on a graph shared by its owner.
+// The invitation is addressed to an email, UserId is set once accepted.
+type GraphMember struct {
+	Id         string       `json:"id"`
+	GraphId    string       `json:"graphId"`
+	UserId     *string      `json:"userId"`
+	Email      string       `json:"email"`
+	Role       GraphRole    `json:"role"`
+	Status     MemberStatus `json:"status"`
+	InvitedBy  string       `json:"invitedBy"`
+	CreatedAt  time.Time    `json:"createdAt"`
+	AcceptedAt *time.Time   `json:"acceptedAt"`
+}
+
+type MemberRepository interface {
+	FindById(c context.Context, id string) (*GraphMember, error)
+	FindByGraphId(c context.Context, graphId string) ([]GraphMember, error)
+	FindByGraphIdAndEmail(c context.Context, graphId string, email string) (*GraphMember, error)
+	// the accepted membership of the user on the graph
+	FindByGraphIdAndUserId(c context.Context, graphId string, userId string) (*GraphMember, error)
+	FindPendingByEmail(c context.Context, email string) ([]GraphMember, error)
+	Store(c context.Context, m GraphMember) (*string, error)
+	Update(c context.Context, id string, m GraphMember) error
+	Delete(c context.Context, id string) error
+}
+
+type MemberUsecase interface {
+	List(c context.Context, graphId string, user Profile) ([]GraphMember, error)
+	ListInvitations(c context.Context, user Profile) ([]GraphMember, error)
+	Invite(c context.Context, graphId string, email string, role GraphRole, user Profile) (*string, error)
+	Accept(c context.Context, graphId string, user Profile) error
+	ChangeRole(c context.Context, graphId string, memberId string, role GraphRole, user Profile) error
+	Remove(c context.Context, graphId string, memberId string, user Profile) error
+}
@@ -0,0 +1,33 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// PurgeReport counts what a purge removed for good,
+// the words and links in the trash of the graphs among them
+type PurgeReport struct {
+	Graphs    int           `json:"graphs"`
+	Words     int           `json:"words"`
+	Links     int           `json:"links"`
+	Revisions int           `json:"revisions"`
+	Tokens    int           `json:"tokens"`
+	StartedAt time.Time     `json:"startedAt"`
+	Duration  time.Duration `json:"duration"`
+}
+
+// Add the counts of another report
+func (r *PurgeReport) Add(o PurgeReport) {
+	r.Graphs += o.Graphs
+	r.Words += o.Words
+	r.Links += o.Links
+	r.Revisions += o.Revisions
+	r.Tokens += o.Tokens
+}
+
+type PurgeUsecase interface {
+	// Remove the graphs deleted for longer than the retention
+	// with all their data, and the expired OTPs
+	Purge(c context.Context) (*PurgeReport, error)
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type RevisionEntity string
+
+const (
+	RevisionEntityWord RevisionEntity = "word"
+	RevisionEntityLink RevisionEntity = "link"
+)
+
+// Revision keeps the values a word or a link had before an update,
+// with the user who updated it and when
+type Revision struct {
+	Id         string         `json:"id"`
+	EntityType RevisionEntity `json:"entityType"`
+	EntityId   string         `json:"entityId"`
+	// the revisions of an entity are numbered from 1
+	Version     int       `json:"version"`
+	UserId      string    `json:"userId"`
+	Content     string    `json:"content"`
+	Description *string   `json:"description"`
+	Refs        *[]string `json:"refs"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+// the current values of the entity, compared like a revision
+const RevisionCurrent = "current"
+
+// RevisionChange is a field which differs between two revisions,
+// the refs tell which of them were added and removed
+type RevisionChange struct {
+	Field   string      `json:"field"`
+	From    interface{} `json:"from"`
+	To      interface{} `json:"to"`
+	Added   []string    `json:"added,omitempty"`
+	Removed []string    `json:"removed,omitempty"`
+}
+
+type RevisionDiff struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Changes []RevisionChange `json:"changes"`
+}
+
+type RevisionRepository interface {
+	FindById(c context.Context, id string) (*Revision, error)
+	// the revisions of the entity, the latest first
+	FindByEntity(c context.Context, entityType RevisionEntity, entityId string) ([]Revision, error)
+	// store the revision as the next version of the entity
+	Store(c context.Context, r Revision) (*string, error)
+}
+
+type RevisionUsecase interface {
+	List(c context.Context, entityType RevisionEntity, entityId string, user Profile) ([]Revision, error)
+	// compare two revisions of the entity, either of them may be RevisionCurrent
+	Diff(c context.Context, entityType RevisionEntity, entityId string, fromId string, toId string, user Profile) (*RevisionDiff, error)
+	// restore the values of the revision, the replaced values are kept as a new revision
+	Revert(c context.Context, entityType RevisionEntity, entityId string, revisionId string, user Profile) error
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type SharePermission string
+
+const (
+	SharePermissionRead SharePermission = "read"
+	// reads the graph like SharePermissionRead,
+	// and will comment on it once the graphs have comments
+	SharePermissionComment SharePermission = "comment"
+)
+
+var SharePermissionMap = map[string]SharePermission{
+	"read":    SharePermissionRead,
+	"comment": SharePermissionComment,
+}
+
+// ShareLink lets anyone holding its token read a graph without an account.
+// Only the hash of the token and of the optional password are stored.
+type ShareLink struct {
+	Id           string          `json:"id"`
+	GraphId      string          `json:"graphId"`
+	TokenHash    string          `json:"-"`
+	Permission   SharePermission `json:"permission"`
+	PasswordHash *string         `json:"-"`
+	ExpiresAt    *time.Time      `json:"expiresAt"`
+	CreatedBy    string          `json:"createdBy"`
+	CreatedAt    time.Time       `json:"createdAt"`
+}
+
+// ShareCredential is the share token given with a request,
+// and the password of the share link when it has one
+type ShareCredential struct {
+	Token    string
+	Password string
+}
+
+type ShareRepository interface {
+	FindById(c context.Context, id string) (*ShareLink, error)
+	FindByGraphId(c context.Context, graphId string) ([]ShareLink, error)
+	FindByTokenHash(c context.Context, tokenHash string) (*ShareLink, error)
+	Store(c context.Context, s ShareLink) (*string, error)
+	Delete(c context.Context, id string) error
+}
+
+type ShareUsecase interface {
+	List(c context.Context, graphId string, user Profile) ([]ShareLink, error)
+	// create a share link and return it with its token,
+	// which is never given back afterwards
+	Create(c context.Context, graphId string, share ShareLink, password *string, user Profile) (*ShareLink, string, error)
+	Revoke(c context.Context, graphId string, id string, user Profile) error
+}
@@ -0,0 +1,12 @@
+package domain
+
+import "context"
+
+// Transactor runs several repository calls as one unit of work.
+// Repositories called with the context given to fn join the transaction,
+// which is committed when fn returns nil and rolled back otherwise.
+// fn may run again when a transient database error aborts the transaction,
+// so it should have no effect outside the repositories.
+type Transactor interface {
+	WithinTransaction(c context.Context, fn func(c context.Context) error) error
+}
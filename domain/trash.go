@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// TrashedWord is a deleted word, it keeps its relationships
+// and their links until it is restored or purged
+type TrashedWord struct {
+	Word
+	DeletedBy string    `json:"deletedBy"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// TrashedRelation is a relationship deleted on its own,
+// with the link annotating it when it had one
+type TrashedRelation struct {
+	SourceId  string    `json:"sourceId"`
+	TargetId  string    `json:"targetId"`
+	Link      *Link     `json:"link"`
+	DeletedBy string    `json:"deletedBy"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// Trash holds the deleted words of a graph and the deleted
+// relationships of its words, the latest deleted first
+type Trash struct {
+	Words     []TrashedWord     `json:"words"`
+	Relations []TrashedRelation `json:"relations"`
+}
+
+type TrashRepository interface {
+	FindByGraphId(c context.Context, graphId string) (*Trash, error)
+	FindWordById(c context.Context, id string) (*TrashedWord, error)
+	// Move the word to the trash with its relationships and their links
+	TrashWord(c context.Context, id string, userId string) error
+	// Move the relationships between the words to the trash with their links
+	TrashRelation(c context.Context, w1Id string, w2Id string, userId string) error
+	// Bring back the word with the relationships trashed with it,
+	// except the ones going to a word which is still in the trash
+	RestoreWord(c context.Context, id string) error
+	// Bring back the latest relationship deleted between the words,
+	// false when there is none
+	RestoreRelation(c context.Context, w1Id string, w2Id string) (bool, error)
+	// Purge the word from the trash with its relationships and their links
+	DeleteWord(c context.Context, id string) error
+	// Purge the relationships deleted between the words
+	DeleteRelation(c context.Context, w1Id string, w2Id string) error
+}
+
+type TrashUsecase interface {
+	List(c context.Context, graphId string, user Profile) (*Trash, error)
+	RestoreWord(c context.Context, graphId string, id string, user Profile) error
+	RestoreRelation(c context.Context, graphId string, w1Id string, w2Id string, user Profile) error
+	PurgeWord(c context.Context, graphId string, id string, user Profile) error
+	PurgeRelation(c context.Context, graphId string, w1Id string, w2Id string, user Profile) error
+	// purge every word and relationship in the trash of the graph
+	Empty(c context.Context, graphId string, user Profile) error
+}
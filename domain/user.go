@@ -32,13 +32,15 @@ type User struct {
 type Profile struct {
 	Id   string
 	Role RoleType
+	// share link given with the request, on the routes which accept one
+	Share *ShareCredential
 }
 
 type UserRepository interface {
-	Create(*User) (*string, error)
-	Update(string, map[string]interface{}) error
-	FindById(string) (*User, error)
-	FindByEmail(string) (*User, error)
+	Create(context.Context, *User) (*string, error)
+	Update(context.Context, string, map[string]interface{}) error
+	FindById(context.Context, string) (*User, error)
+	FindByEmail(context.Context, string) (*User, error)
 }
 
 type UserUsecase interface {
@@ -26,28 +26,126 @@ type WordsGraphData struct {
 	Links []WordsLink `json:"links"`
 }
 
+// Graphs searched when no graph is given
+type SearchScope string
+
+const (
+	// every graph the user finds in the search results
+	SearchScopeAll SearchScope = "all"
+	// the graphs the user owns or is a member of
+	SearchScopeMine SearchScope = "mine"
+)
+
+type WordSearch struct {
+	Text string
+	// the only graph searched when set, the scope is ignored then
+	GraphId string
+	Scope   SearchScope
+	Offset  int
+	Limit   int
+	// continues the search after a page, instead of the offset
+	Cursor string
+}
+
+// The graphs a word search reads the words of, the deleted graphs excluded
+type WordSearchGraphs struct {
+	// every graph, for the site admins and moderators
+	All bool
+	Ids []string
+	// the public graphs as well
+	Public bool
+}
+
+// A word matching the search, the highlights are the matched fields
+// with the HTML escaped and the matched terms wrapped in <mark> tags
+type WordSearchHit struct {
+	Word
+	Score      float64           `json:"score"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type WordSearchPage struct {
+	Results []WordSearchHit `json:"results"`
+	Limit   int             `json:"limit"`
+	// empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// How a suggested word matched the typed text
+type SuggestMatch string
+
+const (
+	// the whole content, diacritics and case aside
+	SuggestMatchExact SuggestMatch = "exact"
+	// the start of the content
+	SuggestMatchPrefix SuggestMatch = "prefix"
+	// the start of a later word of the content
+	SuggestMatchWord SuggestMatch = "word"
+	// the start of a word of the content with typos
+	SuggestMatchFuzzy SuggestMatch = "fuzzy"
+)
+
+type WordSuggestion struct {
+	Id      string       `json:"id"`
+	Content string       `json:"content"`
+	Match   SuggestMatch `json:"match"`
+	// edits between the typed text and the matched one
+	Distance int `json:"distance"`
+	// relationships of the word
+	Degree int `json:"degree"`
+}
+
+// WordIndex suggests the words of a graph as their content is typed
+type WordIndex interface {
+	Suggest(c context.Context, graphId string, text string, limit int) ([]WordSuggestion, error)
+}
+
+// A word close to another one by the terms of their text
+type SimilarWord struct {
+	Word
+	// cosine of their TF-IDF vectors, from 0 to 1
+	Score float64 `json:"score"`
+}
+
+// WordSimilarity compares the words of a graph by their content, description and refs
+type WordSimilarity interface {
+	// Words of the graph closest to w from the best score, w and the excluded words left out.
+	// w may be a word not stored yet.
+	Similar(c context.Context, graphId string, w Word, exclude []string, limit int) ([]SimilarWord, error)
+}
+
 type WordRepository interface {
-	FindByIds(id []string) ([]Word, error)
-	FindById(id string) (*Word, error)
-	FindByRandomId() (*Word, error)
-	FindByGraphId(graphId string) ([]Word, []WordsLink, error)
-	FindNeighborIds(id string, depth int) ([]WordsLink, error)
-	FindByContentOrDescription(search string, limit int) ([]Word, error)
-	FindPath(fromId string, toId string) ([]Word, []WordsLink, error)
-	Store(w Word, graphId string, linkWordId *string) (*string, error)
-	Update(w Word) error
-	Delete(id string) error
-	StoreRelation(sourceId string, targetId string) error
+	FindByIds(c context.Context, id []string) ([]Word, error)
+	FindById(c context.Context, id string) (*Word, error)
+	FindByRandomId(c context.Context) (*Word, error)
+	FindByGraphId(c context.Context, graphId string) ([]Word, []WordsLink, error)
+	FindNeighborIds(c context.Context, id string, depth int) ([]WordsLink, error)
+	// Words of the graphs matching the search, from the best score
+	Search(c context.Context, search string, graphs WordSearchGraphs, offset int, limit int) ([]WordSearchHit, error)
+	FindPath(c context.Context, fromId string, toId string) ([]Word, []WordsLink, error)
+	Store(c context.Context, w Word, graphId string, linkWordId *string) (*string, error)
+	Update(c context.Context, w Word) error
+	Delete(c context.Context, id string) error
+	StoreRelation(c context.Context, sourceId string, targetId string) error
+	// Lock the words against concurrent writes until the transaction ends.
+	// A concurrent writer waits for the commit, then reads the values committed,
+	// so what it replaces, such as the values kept as a revision, is never stale.
+	Lock(c context.Context, ids []string) error
 }
 
 type WordUsecase interface {
-	GetGraphData(c context.Context, graphId string) (data *WordsGraphData, err error)
-	SearchWord(c context.Context, search string) ([]Word, error)
-	FindPath(c context.Context, fromId string, toId string) ([]Word, []WordsLink, error)
-	GetWordById(c context.Context, id string) (*Word, error)
+	GetGraphData(c context.Context, graphId string, user Profile) (data *WordsGraphData, err error)
+	SearchWord(c context.Context, search WordSearch, user Profile) (*WordSearchPage, error)
+	Suggest(c context.Context, graphId string, text string, limit int, user Profile) ([]WordSuggestion, error)
+	// words of the graph of the word close to it, but not linked to it
+	Similar(c context.Context, id string, limit int, user Profile) ([]SimilarWord, error)
+	// words of the graph close to a word about to be created
+	SimilarToDraft(c context.Context, graphId string, w Word, limit int, user Profile) ([]SimilarWord, error)
+	FindPath(c context.Context, fromId string, toId string, user Profile) ([]Word, []WordsLink, error)
+	GetWordById(c context.Context, id string, user Profile) (*Word, error)
 	Create(c context.Context, w Word, graphId string, user Profile) (res *string, err error)
 	CreateWordWithLink(c context.Context, word Word, linkWordId string, graphId string, user Profile) (res *string, err error)
-	Update(c context.Context, wordId string, data Word) (err error)
+	Update(c context.Context, wordId string, data Word, user Profile) (err error)
 	Delete(c context.Context, id string, user Profile) error
-	Link2Words(c context.Context, sourceId string, targetId string) error
+	Link2Words(c context.Context, sourceId string, targetId string, user Profile) error
 }
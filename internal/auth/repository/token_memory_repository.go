@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type tokenMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitTokenMemoryRepository(db *datasource.Memory) domain.TokenRepository {
+	return &tokenMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func (repo *tokenMemoryRepository) Store(ctx context.Context, r *domain.Token) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Users[r.UserId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	token := *r
+	token.Id = common.NewId()
+	db.Tokens[token.Id] = &token
+	return &token.Id, nil
+}
+
+func (repo *tokenMemoryRepository) FindToken(ctx context.Context, tokenType domain.TokenType, token string, userId string) (*domain.Token, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	for _, t := range db.Tokens {
+		if t.Type == tokenType && t.Token == token && t.UserId == userId {
+			return &domain.Token{
+				Id:        t.Id,
+				CreatedAt: t.CreatedAt,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *tokenMemoryRepository) FindOne(ctx context.Context, tokenType domain.TokenType, userId string) (*domain.Token, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	for _, t := range db.Tokens {
+		if t.Type == tokenType && t.UserId == userId {
+			return &domain.Token{
+				Id:        t.Id,
+				Token:     t.Token,
+				CreatedAt: t.CreatedAt,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *tokenMemoryRepository) DeleteByTypeAndUserId(ctx context.Context, tType domain.TokenType, userId string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	for id, t := range db.Tokens {
+		if t.Type == tType && t.UserId == userId {
+			delete(db.Tokens, id)
+		}
+	}
+	return nil
+}
+
+func (repo *tokenMemoryRepository) DeleteCreatedBefore(ctx context.Context, tType domain.TokenType, before time.Time) (int, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	count := 0
+	for id, t := range db.Tokens {
+		if t.Type == tType && t.CreatedAt.Before(before) {
+			delete(db.Tokens, id)
+			count++
+		}
+	}
+	return count, nil
+}
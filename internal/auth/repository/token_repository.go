@@ -1,73 +1,64 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 
+	"github.com/s2dio-tech/mindgra-backend/common"
 	"github.com/s2dio-tech/mindgra-backend/datasource"
 	"github.com/s2dio-tech/mindgra-backend/domain"
 )
 
 type tokenRepository struct {
-	Datasource *datasource.Neo4J
+	Datasource datasource.Datasource
 }
 
-func InitTokenRepository(db *datasource.Neo4J) domain.TokenRepository {
+func InitTokenRepository(db datasource.Datasource) domain.TokenRepository {
 	return &tokenRepository{
 		Datasource: db,
 	}
 }
 
-func (repo *tokenRepository) Store(r *domain.Token) (*string, error) {
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-
-	defer session.Close()
-
-	result, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		query := `
-		MATCH (u:User {id: $userId})
+func (repo *tokenRepository) Store(ctx context.Context, r *domain.Token) (*string, error) {
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// token.Store
+			MATCH (u:User {id: $userId})
 		CREATE (t:Token {
-			id: apoc.create.uuid(),
+			id: $id,
 			userId: $userId,
 			type: $type,
 			token: $token,
 			createdAt: $createdAt
 		})
 		CREATE (t)-[:BELONGS_TO]->(u)
-		RETURN t.id as id;`
-		parameters := map[string]interface{}{
+		RETURN t.id as id;`,
+		map[string]interface{}{
+			"id":        common.NewId(),
 			"userId":    r.UserId,
 			"type":      string(r.Type),
 			"token":     r.Token,
 			"createdAt": neo4j.LocalDateTimeOf(r.CreatedAt),
-		}
-		res, err := tx.Run(query, parameters)
-		if err != nil || !res.Next() {
-			return nil, err
-		}
-
-		record := res.Record().Values[0]
-		return record, nil
-	})
-
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
+	if len(result) == 0 {
+		return nil, common.ErrInternalServerError
+	}
 
-	id := result.(string)
-	return &id, nil
+	_id, _ := result[0].Get("id")
+	return common.Nullable{Value: _id}.ToStringPtr(), nil
 }
 
-func (repo *tokenRepository) FindToken(tokenType domain.TokenType, token string, userId string) (*domain.Token, error) {
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-
-	defer session.Close()
-
-	result, err := session.Run(
-		`MATCH (t:Token {type: $type, token: $token, userId: $userId})
+func (repo *tokenRepository) FindToken(ctx context.Context, tokenType domain.TokenType, token string, userId string) (*domain.Token, error) {
+	result, err := repo.Datasource.ExecRead(
+		ctx,
+		`// token.FindToken
+			MATCH (t:Token {type: $type, token: $token, userId: $userId})
 		 RETURN t.id as id, t.createdAt as createdAt`,
 		map[string]interface{}{
 			"type":   string(tokenType),
@@ -78,15 +69,11 @@ func (repo *tokenRepository) FindToken(tokenType domain.TokenType, token string,
 	if err != nil {
 		return nil, err
 	}
-	if !result.Next() {
+	if len(result) == 0 {
 		return nil, nil
 	}
 
-	record := result.Record()
-	if err != nil {
-		return nil, err
-	}
-
+	record := result[0]
 	id, _ := record.Get("id")
 	createdAt, _ := record.Get("createdAt")
 	return &domain.Token{
@@ -95,15 +82,11 @@ func (repo *tokenRepository) FindToken(tokenType domain.TokenType, token string,
 	}, nil
 }
 
-func (repo *tokenRepository) FindOne(tokenType domain.TokenType, userId string) (*domain.Token, error) {
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-
-	defer session.Close()
-
-	result, err := session.Run(
-		`MATCH (t:Token {type: $type, userId: $userId})
+func (repo *tokenRepository) FindOne(ctx context.Context, tokenType domain.TokenType, userId string) (*domain.Token, error) {
+	result, err := repo.Datasource.ExecRead(
+		ctx,
+		`// token.FindOne
+			MATCH (t:Token {type: $type, userId: $userId})
 		 RETURN t.id as id, t.token as token, t.createdAt as createdAt
 		 LIMIT 1;`,
 		map[string]interface{}{
@@ -114,15 +97,11 @@ func (repo *tokenRepository) FindOne(tokenType domain.TokenType, userId string)
 	if err != nil {
 		return nil, err
 	}
-	if !result.Next() {
+	if len(result) == 0 {
 		return nil, nil
 	}
 
-	record := result.Record()
-	if err != nil {
-		return nil, err
-	}
-
+	record := result[0]
 	id, _ := record.Get("id")
 	token, _ := record.Get("token")
 	createdAt, _ := record.Get("createdAt")
@@ -133,9 +112,11 @@ func (repo *tokenRepository) FindOne(tokenType domain.TokenType, userId string)
 	}, nil
 }
 
-func (r *tokenRepository) DeleteByTypeAndUserId(tType domain.TokenType, userId string) error {
+func (r *tokenRepository) DeleteByTypeAndUserId(ctx context.Context, tType domain.TokenType, userId string) error {
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (t:Token {type: $type, userId: $userId}) DETACH DELETE t;`,
+		ctx,
+		`// token.DeleteByTypeAndUserId
+			MATCH (t:Token {type: $type, userId: $userId}) DETACH DELETE t;`,
 		map[string]interface{}{
 			"type":   tType,
 			"userId": userId,
@@ -143,3 +124,22 @@ func (r *tokenRepository) DeleteByTypeAndUserId(tType domain.TokenType, userId s
 	)
 	return err
 }
+
+func (r *tokenRepository) DeleteCreatedBefore(ctx context.Context, tType domain.TokenType, before time.Time) (int, error) {
+	result, err := r.Datasource.ExecWrite(
+		ctx,
+		`// token.DeleteCreatedBefore
+			MATCH (t:Token {type: $type}) WHERE t.createdAt < $before
+		DETACH DELETE t
+		RETURN count(t) AS count;`,
+		map[string]interface{}{
+			"type":   string(tType),
+			"before": neo4j.LocalDateTimeOf(before),
+		},
+	)
+	if err != nil || len(result) == 0 {
+		return 0, err
+	}
+	count, _ := result[0].Get("count")
+	return int(count.(int64)), nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type tokenSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitTokenSQLiteRepository(db *datasource.SQLite) domain.TokenRepository {
+	return &tokenSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+func (repo *tokenSQLiteRepository) Store(ctx context.Context, r *domain.Token) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- token.Store
+			INSERT INTO tokens (id, user_id, type, token, created_at)
+		VALUES (?, ?, ?, ?, ?);`,
+		id, r.UserId, string(r.Type), r.Token, r.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *tokenSQLiteRepository) FindToken(ctx context.Context, tokenType domain.TokenType, token string, userId string) (res *domain.Token, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- token.FindToken
+			SELECT id, created_at FROM tokens
+		WHERE type = ? AND token = ? AND user_id = ?
+		LIMIT 1;`,
+		func(rows *sql.Rows) error {
+			res = &domain.Token{}
+			return rows.Scan(&res.Id, &res.CreatedAt)
+		},
+		string(tokenType), token, userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *tokenSQLiteRepository) FindOne(ctx context.Context, tokenType domain.TokenType, userId string) (res *domain.Token, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- token.FindOne
+			SELECT id, token, created_at FROM tokens
+		WHERE type = ? AND user_id = ?
+		LIMIT 1;`,
+		func(rows *sql.Rows) error {
+			res = &domain.Token{}
+			return rows.Scan(&res.Id, &res.Token, &res.CreatedAt)
+		},
+		string(tokenType), userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *tokenSQLiteRepository) DeleteByTypeAndUserId(ctx context.Context, tType domain.TokenType, userId string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- token.DeleteByTypeAndUserId
+			DELETE FROM tokens WHERE type = ? AND user_id = ?;`,
+		string(tType), userId,
+	)
+	return err
+}
+
+func (repo *tokenSQLiteRepository) DeleteCreatedBefore(ctx context.Context, tType domain.TokenType, before time.Time) (count int, err error) {
+	err = repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the dates are stored as text, they are compared once scanned
+		ids := []string{}
+		err := repo.Datasource.ExecRead(
+			ctx,
+			`-- token.DeleteCreatedBefore.find
+				SELECT id, created_at FROM tokens WHERE type = ?;`,
+			func(rows *sql.Rows) error {
+				var id string
+				var createdAt time.Time
+				if err := rows.Scan(&id, &createdAt); err != nil {
+					return err
+				}
+				if createdAt.Before(before) {
+					ids = append(ids, id)
+				}
+				return nil
+			},
+			string(tType),
+		)
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+
+		_ids, _ := json.Marshal(ids)
+		res, err := repo.Datasource.ExecWrite(
+			ctx,
+			`-- token.DeleteCreatedBefore
+				DELETE FROM tokens WHERE id IN (SELECT value FROM json_each(?));`,
+			string(_ids),
+		)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		count = int(n)
+		return err
+	})
+	return count, err
+}
@@ -18,25 +18,28 @@ type authUsecase struct {
 	tokenRepo    domain.TokenRepository
 	userRepo     domain.UserRepository
 	emailUsecase domain.EmailUsecase
+	transactor   domain.Transactor
 }
 
 func InitAuthUsecase(
 	tokenRepo domain.TokenRepository,
 	userRepo domain.UserRepository,
 	emailUsecase domain.EmailUsecase,
+	transactor domain.Transactor,
 ) domain.AuthUsecase {
 	return &authUsecase{
 		tokenRepo:    tokenRepo,
 		userRepo:     userRepo,
 		emailUsecase: emailUsecase,
+		transactor:   transactor,
 	}
 }
 
 func (u *authUsecase) Authentication(c context.Context, user domain.User) (res *domain.User, err error) {
-	res, err = u.userRepo.FindByEmail(user.Email)
+	res, err = u.userRepo.FindByEmail(c, user.Email)
 
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
 		return nil, err
 	}
 
@@ -80,9 +83,9 @@ func (u *authUsecase) GrantNewAccessToken(c context.Context, refreshToken string
 func (u *authUsecase) ForgotPassword(c context.Context, mailAddress string) error {
 
 	// find users
-	user, err := u.userRepo.FindByEmail(mailAddress)
+	user, err := u.userRepo.FindByEmail(c, mailAddress)
 	if err != nil {
-		log.Fatal(err)
+		log.Println(err)
 		return err
 	}
 	if user == nil {
@@ -106,15 +109,24 @@ func (u *authUsecase) ForgotPassword(c context.Context, mailAddress string) erro
 	}
 
 	// store token's secret
-	// remove old ones
-	u.tokenRepo.DeleteByTypeAndUserId(domain.TokenTypeOTP, user.Id)
-	// add created one
-	u.tokenRepo.Store(&domain.Token{
-		Type:      domain.TokenTypeOTP,
-		Token:     token.Secret(),
-		UserId:    user.Id,
-		CreatedAt: createTime,
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// remove old ones
+		if err := u.tokenRepo.DeleteByTypeAndUserId(c, domain.TokenTypeOTP, user.Id); err != nil {
+			return err
+		}
+		// add created one
+		_, err := u.tokenRepo.Store(c, &domain.Token{
+			Type:      domain.TokenTypeOTP,
+			Token:     token.Secret(),
+			UserId:    user.Id,
+			CreatedAt: createTime,
+		})
+		return err
 	})
+	if err != nil {
+		log.Println(err)
+		return common.InternalError(err)
+	}
 
 	// send email
 	email := &domain.Email{
@@ -133,20 +145,20 @@ func (u *authUsecase) ForgotPassword(c context.Context, mailAddress string) erro
 
 func (u *authUsecase) VerifyOTP(c context.Context, email string, otpCode string) (*domain.User, error) {
 
-	user, err := u.userRepo.FindByEmail(email)
+	user, err := u.userRepo.FindByEmail(c, email)
 	if err != nil {
-		log.Fatal(err)
-		return nil, common.ErrInternalServerError
+		log.Println(err)
+		return nil, common.InternalError(err)
 	}
 	if user == nil {
 		return nil, common.ErrBadParamInput
 	}
 
 	// find token
-	token, err := u.tokenRepo.FindOne(domain.TokenTypeOTP, user.Id)
+	token, err := u.tokenRepo.FindOne(c, domain.TokenTypeOTP, user.Id)
 	if err != nil {
-		log.Fatal(err)
-		return nil, common.ErrInternalServerError
+		log.Println(err)
+		return nil, common.InternalError(err)
 	}
 	if token == nil {
 		return nil, common.ErrBadParamInput
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"fmt"
+	"html"
 	"log"
 
 	"github.com/s2dio-tech/mindgra-backend/common"
@@ -21,7 +22,7 @@ func Init(sender domain.EmailSender) domain.EmailUsecase {
 
 func (u *emailUsecase) SendEmail(c context.Context, email domain.Email) error {
 	if err := u.sender.SendEmail(email); err != nil {
-		log.Fatalf("Send mail error. %v", err)
+		log.Printf("Send mail error. %v", err)
 		return common.ErrInternalServerError
 	}
 
@@ -46,3 +47,17 @@ Your login password was updated!
 If you did not make this request, please ignore this email.`, name),
 	}
 }
+
+func (u *emailUsecase) GetGraphInvitationMailTemplate(name string, inviterName string, graphName string, graphId string) *domain.EmailTemplate {
+	// the names are typed by the users, they must not add markup or links to the mail
+	subject := fmt.Sprintf("%s shared a graph with you", inviterName)
+	name, inviterName, graphName = html.EscapeString(name), html.EscapeString(inviterName), html.EscapeString(graphName)
+	return &domain.EmailTemplate{
+		Subject: subject,
+		Html: fmt.Sprintf(`Hello %s,<br/>
+%s invited you to collaborate on the graph <b>%s</b>.<br/>
+Sign in to MindGra with this email address to accept the invitation:<br/>
+<a href="https://%s/graphs/%s">https://%s/graphs/%s</a><br/>
+If you don't know %s, you can ignore this email.`, name, inviterName, graphName, common.AppConfig.AppDomain, graphId, common.AppConfig.AppDomain, graphId, inviterName),
+	}
+}
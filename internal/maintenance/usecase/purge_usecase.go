@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/exp/slog"
+)
+
+var (
+	purgedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mindgra_purged_total",
+		Help: "Graphs, words, links, revisions and tokens removed by the purges.",
+	}, []string{"kind"})
+
+	purgeLastRun = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "mindgra_purge_last_run_timestamp_seconds",
+		Help: "Time of the last purge by status.",
+	}, []string{"status"})
+)
+
+type purgeUsecase struct {
+	graphRepo domain.GraphRepository
+	tokenRepo domain.TokenRepository
+}
+
+func InitPurgeUsecase(graphRepo domain.GraphRepository, tokenRepo domain.TokenRepository) domain.PurgeUsecase {
+	return &purgeUsecase{
+		graphRepo: graphRepo,
+		tokenRepo: tokenRepo,
+	}
+}
+
+func (u *purgeUsecase) Purge(c context.Context) (*domain.PurgeReport, error) {
+	report := &domain.PurgeReport{StartedAt: time.Now()}
+	errs := []error{}
+
+	graphs, err := u.graphRepo.SelectDeletedBefore(c, report.StartedAt.Add(-common.AppConfig.GraphRetention))
+	if err != nil {
+		slog.Error("Select expired graphs error", err)
+		errs = append(errs, err)
+	}
+	// each graph is purged in its own transaction,
+	// so a failing one doesn't keep the others
+	for _, g := range graphs {
+		r, err := u.graphRepo.Purge(c, g.Id)
+		if err != nil {
+			slog.Error("Purge graph error", "graphId", g.Id, "error", err)
+			errs = append(errs, err)
+			continue
+		}
+		report.Add(*r)
+	}
+
+	// the OTPs can't be verified past their life time
+	tokens, err := u.tokenRepo.DeleteCreatedBefore(c, domain.TokenTypeOTP, report.StartedAt.Add(-common.AppConfig.OTPLifeTime))
+	if err != nil {
+		slog.Error("Purge tokens error", err)
+		errs = append(errs, err)
+	}
+	report.Tokens = tokens
+	report.Duration = time.Since(report.StartedAt)
+
+	purgedTotal.WithLabelValues("graph").Add(float64(report.Graphs))
+	purgedTotal.WithLabelValues("word").Add(float64(report.Words))
+	purgedTotal.WithLabelValues("link").Add(float64(report.Links))
+	purgedTotal.WithLabelValues("revision").Add(float64(report.Revisions))
+	purgedTotal.WithLabelValues("token").Add(float64(report.Tokens))
+	if len(errs) > 0 {
+		purgeLastRun.WithLabelValues("error").SetToCurrentTime()
+		return report, common.InternalError(errors.Join(errs...))
+	}
+	purgeLastRun.WithLabelValues("success").SetToCurrentTime()
+	return report, nil
+}
+
+// Run the purge every interval until c is done, and log what it removed
+func Schedule(c context.Context, u domain.PurgeUsecase, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		report, err := u.Purge(c)
+		if err != nil {
+			slog.Warn("purge finished with errors", "error", err)
+		}
+		slog.Info("purge done",
+			"graphs", report.Graphs,
+			"words", report.Words,
+			"links", report.Links,
+			"revisions", report.Revisions,
+			"tokens", report.Tokens,
+			"duration", report.Duration,
+		)
+
+		select {
+		case <-c.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
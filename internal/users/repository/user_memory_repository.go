@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type userMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitUserMemoryRepository(db *datasource.Memory) domain.UserRepository {
+	return &userMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func (repo *userMemoryRepository) Create(ctx context.Context, u *domain.User) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	user := *u
+	user.Id = common.NewId()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = nil
+	db.Users[user.Id] = &user
+	return &user.Id, nil
+}
+
+func (repo *userMemoryRepository) FindById(ctx context.Context, id string) (*domain.User, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	u := db.Users[id]
+	if u == nil {
+		return nil, nil
+	}
+	return &domain.User{
+		Id:    u.Id,
+		Email: u.Email,
+		Name:  u.Name,
+		Role:  u.Role,
+	}, nil
+}
+
+func (repo *userMemoryRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	for _, u := range db.Users {
+		if u.Email == email {
+			return &domain.User{
+				Id:       u.Id,
+				Name:     u.Name,
+				Email:    u.Email,
+				Password: u.Password,
+				Role:     u.Role,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *userMemoryRepository) Update(ctx context.Context, id string, data map[string]interface{}) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	u := db.Users[id]
+	if u == nil {
+		return nil
+	}
+	if password, ok := data["password"].(string); ok {
+		u.Password = password
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -11,68 +12,56 @@ import (
 )
 
 type userRepository struct {
-	Datasource *datasource.Neo4J
+	Datasource datasource.Datasource
 }
 
-func InitUserRepository(db *datasource.Neo4J) domain.UserRepository {
+func InitUserRepository(db datasource.Datasource) domain.UserRepository {
 	return &userRepository{
 		Datasource: db,
 	}
 }
 
-func (repo *userRepository) Create(u *domain.User) (*string, error) {
+func (repo *userRepository) Create(ctx context.Context, u *domain.User) (*string, error) {
 	user := *u
 	user.CreatedAt = time.Now()
 
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-
-	defer session.Close()
-
-	result, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		query := `CREATE (u:User {
-			id: apoc.create.uuid(),
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// user.Create
+			CREATE (u:User {
+			id: $id,
 			name: $name,
 			email: $email,
 			password: $password,
 			role: $role,
 			createdAt: $createdAt
 		})
-		RETURN u.id as id;`
-		parameters := map[string]interface{}{
+		RETURN u.id as id;`,
+		map[string]interface{}{
+			"id":        common.NewId(),
 			"name":      user.Name,
 			"email":     user.Email,
 			"password":  user.Password,
 			"role":      user.Role,
 			"createdAt": neo4j.LocalDateTimeOf(user.CreatedAt),
-		}
-		res, err := tx.Run(query, parameters)
-		if err != nil || !res.Next() {
-			return nil, err
-		}
-
-		record := res.Record().Values[0]
-		return record, nil
-	})
-
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
+	if len(result) == 0 {
+		return nil, common.ErrInternalServerError
+	}
 
-	return common.Nullable{Value: result}.ToStringPtr(), nil
+	_id, _ := result[0].Get("id")
+	return common.Nullable{Value: _id}.ToStringPtr(), nil
 }
 
-func (repo *userRepository) FindById(id string) (user *domain.User, err error) {
-
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-
-	defer session.Close()
-
-	result, err := session.Run(
-		`MATCH (u:User {id: $id})
+func (repo *userRepository) FindById(ctx context.Context, id string) (user *domain.User, err error) {
+	result, err := repo.Datasource.ExecRead(
+		ctx,
+		`// user.FindById
+			MATCH (u:User {id: $id})
 		RETURN u.name AS name,
 			u.email AS email,
 			u.role AS role;`,
@@ -83,14 +72,11 @@ func (repo *userRepository) FindById(id string) (user *domain.User, err error) {
 	if err != nil {
 		return nil, err
 	}
-	if !result.Next() {
+	if len(result) == 0 {
 		return nil, nil
 	}
 
-	record := result.Record()
-	if err != nil {
-		return nil, err
-	}
+	record := result[0]
 	email, _ := record.Get("email")
 	name, _ := record.Get("name")
 	role, _ := record.Get("role")
@@ -102,15 +88,11 @@ func (repo *userRepository) FindById(id string) (user *domain.User, err error) {
 	}, nil
 }
 
-func (repo *userRepository) FindByEmail(email string) (*domain.User, error) {
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeRead,
-	})
-
-	defer session.Close()
-
-	result, err := session.Run(
-		`MATCH (u:User {email: $email})
+func (repo *userRepository) FindByEmail(ctx context.Context, email string) (*domain.User, error) {
+	result, err := repo.Datasource.ExecRead(
+		ctx,
+		`// user.FindByEmail
+			MATCH (u:User {email: $email})
 			RETURN u.id AS id,
 				u.name AS name,
 				u.email AS email,
@@ -123,15 +105,11 @@ func (repo *userRepository) FindByEmail(email string) (*domain.User, error) {
 	if err != nil {
 		return nil, err
 	}
-	if !result.Next() {
+	if len(result) == 0 {
 		return nil, nil
 	}
 
-	record := result.Record()
-	if err != nil {
-		return nil, err
-	}
-
+	record := result[0]
 	id, _ := record.Get("id")
 	name, _ := record.Get("name")
 	mail, _ := record.Get("email")
@@ -146,27 +124,17 @@ func (repo *userRepository) FindByEmail(email string) (*domain.User, error) {
 	}, nil
 }
 
-func (repo *userRepository) Update(id string, data map[string]interface{}) error {
-	session := repo.Datasource.Driver.NewSession(neo4j.SessionConfig{
-		AccessMode: neo4j.AccessModeWrite,
-	})
-
-	defer session.Close()
-
-	_, err := session.WriteTransaction(func(tx neo4j.Transaction) (interface{}, error) {
-		query := `MATCH (u:User {id: $id})
-			SET u.password = $password;`
-		parameters := map[string]interface{}{
+func (repo *userRepository) Update(ctx context.Context, id string, data map[string]interface{}) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// user.Update
+			MATCH (u:User {id: $id})
+			SET u.password = $password;`,
+		map[string]interface{}{
 			"id":        id,
 			"password":  data["password"],
 			"updatedAt": neo4j.LocalDateTimeOf(time.Now()),
-		}
-		res, err := tx.Run(query, parameters)
-		if err != nil || !res.Next() {
-			return nil, err
-		}
-		return nil, nil
-	})
-
+		},
+	)
 	return err
 }
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type userSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitUserSQLiteRepository(db *datasource.SQLite) domain.UserRepository {
+	return &userSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+func (repo *userSQLiteRepository) Create(ctx context.Context, u *domain.User) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- user.Create
+			INSERT INTO users (id, name, email, password, role, created_at)
+		VALUES (?, ?, ?, ?, ?, ?);`,
+		id, u.Name, u.Email, u.Password, string(u.Role), time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *userSQLiteRepository) FindById(ctx context.Context, id string) (user *domain.User, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- user.FindById
+			SELECT name, email, role FROM users WHERE id = ?;`,
+		func(rows *sql.Rows) error {
+			var role string
+			user = &domain.User{Id: id}
+			if err := rows.Scan(&user.Name, &user.Email, &role); err != nil {
+				return err
+			}
+			user.Role = domain.RoleMap[role]
+			return nil
+		},
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *userSQLiteRepository) FindByEmail(ctx context.Context, email string) (user *domain.User, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- user.FindByEmail
+			SELECT id, name, email, password, role FROM users WHERE email = ?;`,
+		func(rows *sql.Rows) error {
+			var role string
+			user = &domain.User{}
+			if err := rows.Scan(&user.Id, &user.Name, &user.Email, &user.Password, &role); err != nil {
+				return err
+			}
+			user.Role = domain.RoleMap[role]
+			return nil
+		},
+		email,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (repo *userSQLiteRepository) Update(ctx context.Context, id string, data map[string]interface{}) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- user.Update
+			UPDATE users SET password = ?, updated_at = ? WHERE id = ?;`,
+		data["password"], time.Now(), id,
+	)
+	return err
+}
@@ -12,15 +12,18 @@ import (
 type userUsecase struct {
 	userRepo     domain.UserRepository
 	emailUsecase domain.EmailUsecase
+	transactor   domain.Transactor
 }
 
 func InitUserUsecase(
 	repo domain.UserRepository,
 	emailUsecase domain.EmailUsecase,
+	transactor domain.Transactor,
 ) domain.UserUsecase {
 	return &userUsecase{
 		userRepo:     repo,
 		emailUsecase: emailUsecase,
+		transactor:   transactor,
 	}
 }
 
@@ -33,37 +36,43 @@ func hashPassword(pass string) (string, error) {
 }
 
 func (u *userUsecase) Registration(c context.Context, user *domain.User) (res *string, err error) {
-	// check email exists
-	oldUser, err := u.userRepo.FindByEmail(user.Email)
-	if err != nil {
-		return
-	}
-	if oldUser != nil {
-		return nil, common.ErrEmailDuplicate
-	}
-
 	hashPassword, err := hashPassword(user.Password)
 	if err != nil {
 		return
 	}
-	// insert to db
-	userId, err := u.userRepo.Create(&domain.User{
-		Name:      user.Name,
-		Email:     user.Email,
-		Password:  hashPassword,
-		Role:      domain.RoleMember,
-		CreatedAt: time.Now(),
-	})
 
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// check email exists
+		oldUser, err := u.userRepo.FindByEmail(c, user.Email)
+		if err != nil {
+			return err
+		}
+		if oldUser != nil {
+			return common.ErrEmailDuplicate
+		}
+
+		// insert to db
+		res, err = u.userRepo.Create(c, &domain.User{
+			Name:      user.Name,
+			Email:     user.Email,
+			Password:  hashPassword,
+			Role:      domain.RoleMember,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, err
 	}
 
-	return userId, nil
+	return res, nil
 }
 
 func (u *userUsecase) FindByEmail(c context.Context, email string) (user *domain.User, err error) {
-	user, err = u.userRepo.FindByEmail(email)
+	user, err = u.userRepo.FindByEmail(c, email)
 	if err != nil {
 		return
 	}
@@ -75,7 +84,7 @@ func (u *userUsecase) FindByEmail(c context.Context, email string) (user *domain
 }
 
 func (u *userUsecase) FindById(c context.Context, id string) (user *domain.User, err error) {
-	user, err = u.userRepo.FindById(id)
+	user, err = u.userRepo.FindById(c, id)
 	if err != nil {
 		return
 	}
@@ -89,9 +98,9 @@ func (u *userUsecase) FindById(c context.Context, id string) (user *domain.User,
 func (u *userUsecase) UpdatePassword(c context.Context, userId string, password string) error {
 
 	// find user
-	user, err := u.userRepo.FindById(userId)
+	user, err := u.userRepo.FindById(c, userId)
 	if err != nil {
-		return common.ErrInternalServerError
+		return common.InternalError(err)
 	}
 	if user == nil {
 		return common.ErrNotFound
@@ -102,12 +111,12 @@ func (u *userUsecase) UpdatePassword(c context.Context, userId string, password
 	if err != nil {
 		return common.ErrInternalServerError
 	}
-	err = u.userRepo.Update(userId, map[string]interface{}{
+	err = u.userRepo.Update(c, userId, map[string]interface{}{
 		"password": hashPassword,
 	})
 
 	if err != nil {
-		return common.ErrInternalServerError
+		return common.InternalError(err)
 	}
 
 	// send email
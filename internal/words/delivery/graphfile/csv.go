@@ -0,0 +1,189 @@
+package graphfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// Write the rows to a csv file of the zip
+func writeCSVFile(z *zip.Writer, name string, rows [][]string) error {
+	f, err := z.Create(name)
+	if err != nil {
+		return err
+	}
+	c := csv.NewWriter(f)
+	if err := c.WriteAll(rows); err != nil {
+		return err
+	}
+	return c.Error()
+}
+
+// The headers are the ones the Gephi spreadsheet import recognizes
+func writeCSV(w io.Writer, data domain.GraphExport) error {
+	nodes := [][]string{{"Id", "Label", "Description", "Refs", "CreatedAt"}}
+	for _, word := range data.Words {
+		createdAt := ""
+		if word.CreatedAt != nil {
+			createdAt = word.CreatedAt.Format(time.RFC3339)
+		}
+		nodes = append(nodes, []string{
+			word.Id,
+			word.Content,
+			stringOf(word.Description),
+			refsString(word.Refs),
+			createdAt,
+		})
+	}
+
+	edges := [][]string{{"Source", "Target", "Type", "LinkId", "Label", "Description", "Refs"}}
+	findLink := linkFinder(data)
+	for _, r := range data.Relations {
+		row := []string{r.SourceId, r.TargetId, "Directed", "", "", "", ""}
+		if l := findLink(r); l != nil {
+			row[3], row[4], row[5], row[6] = l.Id, l.Content, stringOf(l.Description), refsString(l.Refs)
+		}
+		edges = append(edges, row)
+	}
+
+	z := zip.NewWriter(w)
+	if err := writeCSVFile(z, "nodes.csv", nodes); err != nil {
+		return err
+	}
+	if err := writeCSVFile(z, "edges.csv", edges); err != nil {
+		return err
+	}
+	return z.Close()
+}
+
+// Read a csv file into maps keyed by the lower case headers,
+// with the line of each row
+func readCSVRows(r io.Reader) ([]map[string]string, []int, error) {
+	c := csv.NewReader(r)
+	c.FieldsPerRecord = -1
+	records, err := c.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil, nil
+	}
+
+	headers := records[0]
+	rows := []map[string]string{}
+	lines := []int{}
+	for i, record := range records[1:] {
+		row := map[string]string{}
+		for j, v := range record {
+			if j < len(headers) {
+				row[strings.ToLower(strings.TrimSpace(headers[j]))] = v
+			}
+		}
+		rows = append(rows, row)
+		lines = append(lines, i+2)
+	}
+	return rows, lines, nil
+}
+
+// Read the nodes and edges csv files, the edges are optional.
+// The headers of the export and of the Gephi spreadsheets are recognized.
+func ImportCSV(nodes io.Reader, edges io.Reader) (*Document, error) {
+	doc := &Document{}
+	rows, lines, err := readCSVRows(nodes)
+	if err != nil {
+		return nil, fmt.Errorf("nodes.csv: %w", err)
+	}
+	for i, row := range rows {
+		content := row["label"]
+		if content == "" {
+			content = row["content"]
+		}
+		doc.addWord(fmt.Sprintf("nodes.csv:%d", lines[i]), domain.Word{
+			Id:          row["id"],
+			Content:     content,
+			Description: optional(row["description"]),
+			Refs:        parseRefs(row["refs"]),
+		})
+	}
+
+	if edges == nil {
+		return doc, nil
+	}
+	rows, lines, err = readCSVRows(edges)
+	if err != nil {
+		return nil, fmt.Errorf("edges.csv: %w", err)
+	}
+	for i, row := range rows {
+		line := fmt.Sprintf("edges.csv:%d", lines[i])
+		doc.addRelation(line, domain.WordsLink{SourceId: row["source"], TargetId: row["target"]})
+		if row["label"] != "" {
+			doc.addLink(line, domain.Link{
+				Id:          row["linkid"],
+				Word1Id:     row["source"],
+				Word2Id:     row["target"],
+				Content:     row["label"],
+				Description: optional(row["description"]),
+				Refs:        parseRefs(row["refs"]),
+			})
+		}
+	}
+	return doc, nil
+}
+
+// size limit of a csv file of the zip once unzipped
+const maxCSVSize = 16 << 20
+
+// Unzip a csv file of the zip, up to maxCSVSize
+func readZipCSV(f *zip.File) (io.Reader, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, maxCSVSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxCSVSize {
+		return nil, fmt.Errorf("%s: the file is too large", path.Base(f.Name))
+	}
+	return bytes.NewReader(b), nil
+}
+
+func readCSVZip(b []byte) (*Document, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	var nodes, edges io.Reader
+	for _, f := range z.File {
+		switch strings.ToLower(path.Base(f.Name)) {
+		case "nodes.csv":
+			if nodes, err = readZipCSV(f); err != nil {
+				return nil, err
+			}
+		case "edges.csv":
+			if edges, err = readZipCSV(f); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if nodes == nil {
+		// a zip of notes is read as a vault
+		for _, f := range z.File {
+			if isVaultNote(f.Name) {
+				return readVaultZip(z, VaultOptions{ResolveAliases: true})
+			}
+		}
+		return nil, errors.New("nodes.csv is missing")
+	}
+	return ImportCSV(nodes, edges)
+}
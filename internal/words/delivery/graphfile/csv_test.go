@@ -0,0 +1,77 @@
+package graphfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// Zip the files, by name in the order of the names
+func zipOf(t *testing.T, files map[string]string) []byte {
+	b := &bytes.Buffer{}
+	z := zip.NewWriter(b)
+	names := []string{}
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.Write([]byte(files[name])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.Bytes()
+}
+
+func TestImportGephiCSV(t *testing.T) {
+	nodes := "id,label,x\nn1,Rain,1\nn2,Flood\n"
+	edges := "Source,Target,Weight\nn1,n2,1\nn2,n3,1,extra\n"
+	doc, err := ImportCSV(strings.NewReader(nodes), strings.NewReader(edges))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Words) != 2 || doc.Data.Words[1].Content != "Flood" || len(doc.Data.Relations) != 2 || len(doc.Data.Links) != 0 {
+		t.Fatalf("ImportCSV() = %+v", doc.Data)
+	}
+	// the rows are the lines of the files
+	if doc.WordRows[1] != "nodes.csv:3" || doc.RelationRows[1] != "edges.csv:3" {
+		t.Fatalf("ImportCSV() rows = %v %v", doc.WordRows, doc.RelationRows)
+	}
+
+	// the edges are optional
+	doc, err = ImportCSV(strings.NewReader(nodes), nil)
+	if err != nil || len(doc.Data.Words) != 2 || len(doc.Data.Relations) != 0 {
+		t.Fatalf("ImportCSV() without edges = %+v, %v", doc, err)
+	}
+}
+
+func TestImportMalformedCSV(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"not a zip":           []byte("Id,Label\nn1,Rain\n"),
+		"no nodes.csv":        zipOf(t, map[string]string{"edges.csv": "Source,Target\n"}),
+		"unclosed quote":      zipOf(t, map[string]string{"nodes.csv": "Id,Label\nn1,\"Rain\n"}),
+		"edges unclosed":      zipOf(t, map[string]string{"nodes.csv": "Id,Label\n", "edges.csv": "Source,Target\n\"n1,n2\n"}),
+		"nodes.csv too large": zipOf(t, map[string]string{"nodes.csv": "Id,Label\n" + strings.Repeat("n,a\n", maxCSVSize/4)}),
+		"edges.csv too large": zipOf(t, map[string]string{"nodes.csv": "Id,Label\n", "edges.csv": strings.Repeat("x", maxCSVSize+1)}),
+	} {
+		if doc, err := Import(bytes.NewReader(b), FormatCSV); err == nil {
+			t.Errorf("Import() of %s = %d words, want an error", name, len(doc.Data.Words))
+		}
+	}
+
+	// up to the size limit
+	nodes := "Id,Label\n" + strings.Repeat("x", maxCSVSize-len("Id,Label\n")-len(",a\n")) + ",a\n"
+	doc, err := Import(bytes.NewReader(zipOf(t, map[string]string{"graph/nodes.csv": nodes})), FormatCSV)
+	if err != nil || len(doc.Data.Words) != 1 {
+		t.Fatalf("Import() of a nodes.csv of %d bytes = %v", len(nodes), err)
+	}
+}
@@ -0,0 +1,43 @@
+package graphfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+
+// Write a quoted DOT id
+func dotQuote(s string) string {
+	return `"` + dotEscaper.Replace(s) + `"`
+}
+
+func writeDOT(w io.Writer, data domain.GraphExport) error {
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(data.Graph.Name))
+	for _, word := range data.Words {
+		fmt.Fprintf(b, "  %s [label=%s", dotQuote(word.Id), dotQuote(word.Content))
+		if word.Description != nil && *word.Description != "" {
+			fmt.Fprintf(b, ", tooltip=%s", dotQuote(*word.Description))
+		}
+		fmt.Fprint(b, "];\n")
+	}
+	findLink := linkFinder(data)
+	for _, r := range data.Relations {
+		fmt.Fprintf(b, "  %s -> %s", dotQuote(r.SourceId), dotQuote(r.TargetId))
+		if l := findLink(r); l != nil {
+			fmt.Fprintf(b, " [label=%s", dotQuote(l.Content))
+			if l.Description != nil && *l.Description != "" {
+				fmt.Fprintf(b, ", tooltip=%s", dotQuote(*l.Description))
+			}
+			fmt.Fprint(b, "]")
+		}
+		fmt.Fprint(b, ";\n")
+	}
+	fmt.Fprint(b, "}\n")
+	return b.Flush()
+}
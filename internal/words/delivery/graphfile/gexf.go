@@ -0,0 +1,141 @@
+package graphfile
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type gexf struct {
+	XMLName xml.Name  `xml:"gexf"`
+	Xmlns   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Meta    gexfMeta  `xml:"meta"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfMeta struct {
+	LastModifiedDate string `xml:"lastmodifieddate,attr"`
+	Creator          string `xml:"creator"`
+	Description      string `xml:"description"`
+}
+
+type gexfGraph struct {
+	Mode            string           `xml:"mode,attr"`
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfNode       `xml:"nodes>node"`
+	Edges           []gexfEdge       `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	Id    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type gexfNode struct {
+	Id        string         `xml:"id,attr"`
+	Label     string         `xml:"label,attr"`
+	AttValues *gexfAttValues `xml:"attvalues,omitempty"`
+}
+
+type gexfEdge struct {
+	Id        string         `xml:"id,attr"`
+	Source    string         `xml:"source,attr"`
+	Target    string         `xml:"target,attr"`
+	Label     string         `xml:"label,attr,omitempty"`
+	AttValues *gexfAttValues `xml:"attvalues,omitempty"`
+}
+
+type gexfAttValues struct {
+	Values []gexfAttValue `xml:"attvalue"`
+}
+
+type gexfAttValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Keep the attribute values which are set
+func gexfValues(values ...string) *gexfAttValues {
+	res := gexfAttValues{}
+	for i := 0; i+1 < len(values); i += 2 {
+		if values[i+1] != "" {
+			res.Values = append(res.Values, gexfAttValue{For: values[i], Value: values[i+1]})
+		}
+	}
+	if len(res.Values) == 0 {
+		return nil
+	}
+	return &res
+}
+
+func writeGEXF(w io.Writer, data domain.GraphExport) error {
+	doc := gexf{
+		// the version read by Gephi 0.9 and later
+		Xmlns:   "http://www.gexf.net/1.2draft",
+		Version: "1.2",
+		Meta: gexfMeta{
+			LastModifiedDate: data.ExportedAt.Format("2006-01-02"),
+			Creator:          "mindgra",
+			Description:      data.Graph.Name,
+		},
+		Graph: gexfGraph{
+			Mode:            "static",
+			DefaultEdgeType: "directed",
+			Attributes: []gexfAttributes{
+				{Class: "node", Attributes: []gexfAttribute{
+					{"description", "description", "string"},
+					{"refs", "refs", "string"},
+				}},
+				{Class: "edge", Attributes: []gexfAttribute{
+					{"linkId", "linkId", "string"},
+					{"description", "description", "string"},
+					{"refs", "refs", "string"},
+				}},
+			},
+		},
+	}
+	for _, word := range data.Words {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfNode{
+			Id:    word.Id,
+			Label: word.Content,
+			AttValues: gexfValues(
+				"description", stringOf(word.Description),
+				"refs", refsString(word.Refs),
+			),
+		})
+	}
+	findLink := linkFinder(data)
+	for i, r := range data.Relations {
+		edge := gexfEdge{
+			Id:     strconv.Itoa(i),
+			Source: r.SourceId,
+			Target: r.TargetId,
+		}
+		if l := findLink(r); l != nil {
+			edge.Label = l.Content
+			edge.AttValues = gexfValues(
+				"linkId", l.Id,
+				"description", stringOf(l.Description),
+				"refs", refsString(l.Refs),
+			)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
@@ -0,0 +1,202 @@
+// Package graphfile writes the graphs to the file formats
+// read by the graph tools, and reads them back.
+package graphfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type Format string
+
+const (
+	// the lossless GraphExport document
+	FormatJSON    Format = "json"
+	FormatGraphML Format = "graphml"
+	FormatGEXF    Format = "gexf"
+	FormatDOT     Format = "dot"
+	// zip of the nodes and edges csv files
+	FormatCSV Format = "csv"
+	// the mind maps, only imported
+	FormatFreeMind Format = "freemind"
+	FormatOPML     Format = "opml"
+	// Markdown or indented text outline, only imported
+	FormatOutline Format = "outline"
+	// zip of Markdown notes with wiki links, only imported
+	FormatVault Format = "vault"
+)
+
+type encoder struct {
+	extension   string
+	contentType string
+	write       func(w io.Writer, data domain.GraphExport) error
+}
+
+var encoders = map[Format]encoder{
+	FormatJSON:    {"json", "application/json", writeJSON},
+	FormatGraphML: {"graphml", "application/graphml+xml", writeGraphML},
+	FormatGEXF:    {"gexf", "application/gexf+xml", writeGEXF},
+	FormatDOT:     {"dot", "text/vnd.graphviz", writeDOT},
+	FormatCSV:     {"zip", "application/zip", writeCSV},
+}
+
+// Whether the graphs are exported to the format
+func CanExport(format Format) bool {
+	_, ok := encoders[format]
+	return ok
+}
+
+// File extension and content type of the exported file
+func FileType(format Format) (extension string, contentType string) {
+	e := encoders[format]
+	return e.extension, e.contentType
+}
+
+// Write the graph to w in the format
+func Export(w io.Writer, format Format, data domain.GraphExport) error {
+	return encoders[format].write(w, data)
+}
+
+// Document is a graph read from a file, with the row
+// each of its words, relationships and links comes from
+type Document struct {
+	Data         domain.GraphExport
+	WordRows     []string
+	RelationRows []string
+	LinkRows     []string
+	// the rows which couldn't be read
+	Errors []domain.ImportError
+}
+
+var decoders = map[Format]func(b []byte) (*Document, error){
+	FormatJSON:     readJSON,
+	FormatGraphML:  readGraphML,
+	FormatCSV:      readCSVZip,
+	FormatFreeMind: readFreeMind,
+	FormatOPML:     readOPML,
+	FormatOutline:  readOutline,
+	FormatVault: func(b []byte) (*Document, error) {
+		return readVault(b, VaultOptions{ResolveAliases: true})
+	},
+}
+
+// Whether the graphs are imported from the format
+func CanImport(format Format) bool {
+	_, ok := decoders[format]
+	return ok
+}
+
+// Read the graph of the file in the format,
+// a csv file is the zip written by the export
+func Import(r io.Reader, format Format) (*Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return decoders[format](b)
+}
+
+// Register a word of the document with its row
+func (d *Document) addWord(row string, w domain.Word) {
+	d.Data.Words = append(d.Data.Words, w)
+	d.WordRows = append(d.WordRows, row)
+}
+
+func (d *Document) addRelation(row string, r domain.WordsLink) {
+	d.Data.Relations = append(d.Data.Relations, r)
+	d.RelationRows = append(d.RelationRows, row)
+}
+
+func (d *Document) addLink(row string, l domain.Link) {
+	d.Data.Links = append(d.Data.Links, l)
+	d.LinkRows = append(d.LinkRows, row)
+}
+
+func (d *Document) addError(row string, message string) {
+	d.Errors = append(d.Errors, domain.ImportError{Row: row, Message: message})
+}
+
+func writeJSON(w io.Writer, data domain.GraphExport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(data)
+}
+
+func readJSON(b []byte) (*Document, error) {
+	data := domain.GraphExport{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	if data.Version > domain.GraphExportVersion {
+		return nil, fmt.Errorf("unsupported version %d", data.Version)
+	}
+
+	doc := &Document{Data: domain.GraphExport{Graph: data.Graph}}
+	for i, w := range data.Words {
+		doc.addWord(fmt.Sprintf("words[%d]", i), w)
+	}
+	for i, r := range data.Relations {
+		doc.addRelation(fmt.Sprintf("relations[%d]", i), r)
+	}
+	for i, l := range data.Links {
+		doc.addLink(fmt.Sprintf("links[%d]", i), l)
+	}
+	return doc, nil
+}
+
+// Find the link annotating a relationship,
+// the links don't keep the direction of the relationship
+func linkFinder(data domain.GraphExport) func(r domain.WordsLink) *domain.Link {
+	links := map[[2]string]*domain.Link{}
+	for i := range data.Links {
+		l := &data.Links[i]
+		links[[2]string{l.Word1Id, l.Word2Id}] = l
+		links[[2]string{l.Word2Id, l.Word1Id}] = l
+	}
+	return func(r domain.WordsLink) *domain.Link {
+		return links[[2]string{r.SourceId, r.TargetId}]
+	}
+}
+
+// Write the refs as a json array,
+// so they are read back whatever characters they contain
+func refsString(refs *[]string) string {
+	if refs == nil || len(*refs) == 0 {
+		return ""
+	}
+	b, _ := json.Marshal(*refs)
+	return string(b)
+}
+
+func stringOf(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// Read the refs written by refsString,
+// any other text is taken as a single ref
+func parseRefs(s string) *[]string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	refs := []string{}
+	if strings.HasPrefix(s, "[") && json.Unmarshal([]byte(s), &refs) == nil {
+		return &refs
+	}
+	return &[]string{s}
+}
+
+// Read an optional text
+func optional(s string) *string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,240 @@
+package graphfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// A graph with the characters the formats escape,
+// a relationship annotated by a link and one which isn't
+func exportFixture() domain.GraphExport {
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	return domain.GraphExport{
+		Version:    domain.GraphExportVersion,
+		ExportedAt: createdAt,
+		Graph:      domain.Graph{Id: "g1", Name: `Rain & "floods"`},
+		Words: []domain.Word{
+			{
+				Id:          "w1",
+				Content:     `Rain <heavy> & "cold"`,
+				Description: common.ToPointer("falls\nfrom the clouds, ümlaut"),
+				Refs:        &[]string{"https://example.com/rain?a=1&b=2", `a, "quoted" ref`},
+				CreatedAt:   &createdAt,
+			},
+			{Id: "w2", Content: "Flood", CreatedAt: &createdAt},
+			{Id: "w3", Content: `back\slash`, Description: common.ToPointer("tab\tand ;"), CreatedAt: &createdAt},
+		},
+		Relations: []domain.WordsLink{
+			{SourceId: "w1", TargetId: "w2"},
+			{SourceId: "w2", TargetId: "w3"},
+		},
+		Links: []domain.Link{{
+			Id:          "l1",
+			Word1Id:     "w1",
+			Word2Id:     "w2",
+			Content:     "causes",
+			Description: common.ToPointer(`when it "pours"`),
+			Refs:        &[]string{"https://example.com/flood"},
+		}},
+	}
+}
+
+// The parts of the graph every format keeps
+func graphContent(data domain.GraphExport) domain.GraphExport {
+	res := domain.GraphExport{Graph: domain.Graph{Name: data.Graph.Name}}
+	for _, w := range data.Words {
+		res.Words = append(res.Words, domain.Word{Id: w.Id, Content: w.Content, Description: w.Description, Refs: w.Refs})
+	}
+	res.Relations = append(res.Relations, data.Relations...)
+	for _, l := range data.Links {
+		res.Links = append(res.Links, domain.Link{
+			Id:          l.Id,
+			Word1Id:     l.Word1Id,
+			Word2Id:     l.Word2Id,
+			Content:     l.Content,
+			Description: l.Description,
+			Refs:        l.Refs,
+		})
+	}
+	return res
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	for _, format := range []Format{FormatJSON, FormatGraphML, FormatCSV} {
+		data := exportFixture()
+		b := &bytes.Buffer{}
+		if err := Export(b, format, data); err != nil {
+			t.Fatalf("Export(%s) = %v", format, err)
+		}
+		doc, err := Import(b, format)
+		if err != nil {
+			t.Fatalf("Import(%s) = %v", format, err)
+		}
+		if len(doc.Errors) > 0 {
+			t.Fatalf("Import(%s) errors = %+v", format, doc.Errors)
+		}
+
+		want := graphContent(data)
+		if format == FormatCSV {
+			// the csv files don't name the graph
+			want.Graph.Name = ""
+		}
+		if got := graphContent(doc.Data); !reflect.DeepEqual(got, want) {
+			t.Errorf("Import(Export(%s)) =\n%+v\nwant\n%+v", format, got, want)
+		}
+		if len(doc.WordRows) != len(doc.Data.Words) || len(doc.RelationRows) != len(doc.Data.Relations) || len(doc.LinkRows) != len(doc.Data.Links) {
+			t.Errorf("Import(%s) rows don't match the graph: %+v", format, doc)
+		}
+	}
+
+	// the lossless document keeps the dates too
+	data := exportFixture()
+	b := &bytes.Buffer{}
+	if err := Export(b, FormatJSON, data); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := Import(b, FormatJSON)
+	if err != nil || !doc.Data.Words[0].CreatedAt.Equal(*data.Words[0].CreatedAt) {
+		t.Fatalf("Import(json) = %+v, %v, want the creation dates", doc, err)
+	}
+}
+
+func TestExportGEXF(t *testing.T) {
+	data := exportFixture()
+	b := &bytes.Buffer{}
+	if err := Export(b, FormatGEXF, data); err != nil {
+		t.Fatal(err)
+	}
+	doc := gexf{}
+	if err := xml.Unmarshal(b.Bytes(), &doc); err != nil {
+		t.Fatalf("the gexf file is not xml: %v", err)
+	}
+	if doc.Meta.Description != data.Graph.Name || len(doc.Graph.Nodes) != 3 || len(doc.Graph.Edges) != 2 {
+		t.Fatalf("gexf = %+v", doc)
+	}
+
+	// read back as a graph
+	got := domain.GraphExport{Graph: domain.Graph{Name: doc.Meta.Description}}
+	for _, n := range doc.Graph.Nodes {
+		attrs := map[string]string{}
+		if n.AttValues != nil {
+			for _, v := range n.AttValues.Values {
+				attrs[v.For] = v.Value
+			}
+		}
+		got.Words = append(got.Words, domain.Word{Id: n.Id, Content: n.Label, Description: optional(attrs["description"]), Refs: parseRefs(attrs["refs"])})
+	}
+	for _, e := range doc.Graph.Edges {
+		got.Relations = append(got.Relations, domain.WordsLink{SourceId: e.Source, TargetId: e.Target})
+		if e.Label == "" {
+			continue
+		}
+		attrs := map[string]string{}
+		for _, v := range e.AttValues.Values {
+			attrs[v.For] = v.Value
+		}
+		got.Links = append(got.Links, domain.Link{
+			Id:          attrs["linkId"],
+			Word1Id:     e.Source,
+			Word2Id:     e.Target,
+			Content:     e.Label,
+			Description: optional(attrs["description"]),
+			Refs:        parseRefs(attrs["refs"]),
+		})
+	}
+	if want := graphContent(data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("gexf graph =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+var (
+	dotQuoted = `"((?:[^"\\]|\\.)*)"`
+	dotNode   = regexp.MustCompile(`^  ` + dotQuoted + ` \[label=` + dotQuoted + `(?:, tooltip=` + dotQuoted + `)?\];$`)
+	dotEdge   = regexp.MustCompile(`^  ` + dotQuoted + ` -> ` + dotQuoted + `(?: \[label=` + dotQuoted + `(?:, tooltip=` + dotQuoted + `)?\])?;$`)
+	dotEscape = regexp.MustCompile(`\\.`)
+)
+
+func dotUnquote(s string) string {
+	return dotEscape.ReplaceAllStringFunc(s, func(e string) string {
+		if e == `\n` {
+			return "\n"
+		}
+		return e[1:]
+	})
+}
+
+func TestExportDOT(t *testing.T) {
+	data := exportFixture()
+	b := &bytes.Buffer{}
+	if err := Export(b, FormatDOT, data); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if want := "digraph " + dotQuote(data.Graph.Name) + " {"; lines[0] != want || lines[len(lines)-1] != "}" {
+		t.Fatalf("dot file =\n%s", b)
+	}
+
+	// every statement is on its own line, the quotes and new lines escaped
+	got := domain.GraphExport{Graph: data.Graph}
+	for _, l := range lines[1 : len(lines)-1] {
+		if m := dotNode.FindStringSubmatch(l); m != nil {
+			got.Words = append(got.Words, domain.Word{Id: dotUnquote(m[1]), Content: dotUnquote(m[2]), Description: optional(dotUnquote(m[3]))})
+		} else if m := dotEdge.FindStringSubmatch(l); m != nil {
+			got.Relations = append(got.Relations, domain.WordsLink{SourceId: dotUnquote(m[1]), TargetId: dotUnquote(m[2])})
+			if m[3] != "" {
+				got.Links = append(got.Links, domain.Link{Word1Id: dotUnquote(m[1]), Word2Id: dotUnquote(m[2]), Content: dotUnquote(m[3]), Description: optional(dotUnquote(m[4]))})
+			}
+		} else {
+			t.Fatalf("unexpected dot statement %q", l)
+		}
+	}
+
+	// DOT has no place for the refs and the link ids
+	want := data
+	for i := range want.Words {
+		want.Words[i].Refs, want.Words[i].CreatedAt = nil, nil
+	}
+	want.Links[0].Id, want.Links[0].Refs = "", nil
+	if !reflect.DeepEqual(graphContent(got), graphContent(want)) {
+		t.Fatalf("dot graph =\n%+v\nwant\n%+v", graphContent(got), graphContent(want))
+	}
+}
+
+func TestExportedFileTypes(t *testing.T) {
+	for format, want := range map[Format]string{
+		FormatJSON:    "json",
+		FormatGraphML: "graphml",
+		FormatGEXF:    "gexf",
+		FormatDOT:     "dot",
+		FormatCSV:     "zip",
+	} {
+		if ext, _ := FileType(format); !CanExport(format) || ext != want {
+			t.Errorf("FileType(%s) = %s, want %s", format, ext, want)
+		}
+	}
+	for _, format := range []Format{FormatFreeMind, FormatOPML, FormatOutline, FormatVault, "pdf"} {
+		if CanExport(format) {
+			t.Errorf("CanExport(%s) = true", format)
+		}
+	}
+}
+
+func TestImportMalformedJSON(t *testing.T) {
+	for name, text := range map[string]string{
+		"not json":       `{"words": [`,
+		"wrong types":    `{"words": {"id": "w1"}}`,
+		"future version": `{"version": 99, "words": []}`,
+	} {
+		if doc, err := Import(strings.NewReader(text), FormatJSON); err == nil {
+			t.Errorf("Import() of %s = %+v, want an error", name, doc)
+		}
+	}
+}
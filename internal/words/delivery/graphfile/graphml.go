@@ -0,0 +1,207 @@
+package graphfile
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	Id   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	Id     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+var graphMLKeys = []graphMLKey{
+	{"name", "graph", "name", "string"},
+	{"content", "node", "content", "string"},
+	{"description", "node", "description", "string"},
+	{"refs", "node", "refs", "string"},
+	{"linkId", "edge", "linkId", "string"},
+	{"linkContent", "edge", "content", "string"},
+	{"linkDescription", "edge", "description", "string"},
+	{"linkRefs", "edge", "refs", "string"},
+}
+
+// Keep the data which have a value
+func graphMLValues(values ...string) []graphMLData {
+	data := []graphMLData{}
+	for i := 0; i+1 < len(values); i += 2 {
+		if values[i+1] != "" {
+			data = append(data, graphMLData{Key: values[i], Value: values[i+1]})
+		}
+	}
+	return data
+}
+
+func writeGraphML(w io.Writer, data domain.GraphExport) error {
+	doc := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  graphMLKeys,
+		Graph: graphMLGraph{
+			Id:          data.Graph.Id,
+			EdgeDefault: "directed",
+			Data:        graphMLValues("name", data.Graph.Name),
+		},
+	}
+	for _, word := range data.Words {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{
+			Id: word.Id,
+			Data: graphMLValues(
+				"content", word.Content,
+				"description", stringOf(word.Description),
+				"refs", refsString(word.Refs),
+			),
+		})
+	}
+	findLink := linkFinder(data)
+	for i, r := range data.Relations {
+		edge := graphMLEdge{
+			Id:     "e" + strconv.Itoa(i),
+			Source: r.SourceId,
+			Target: r.TargetId,
+		}
+		if l := findLink(r); l != nil {
+			edge.Data = graphMLValues(
+				"linkId", l.Id,
+				"linkContent", l.Content,
+				"linkDescription", stringOf(l.Description),
+				"linkRefs", refsString(l.Refs),
+			)
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	return enc.Encode(doc)
+}
+
+type graphMLIn struct {
+	Keys  []graphMLKey `xml:"key"`
+	Graph struct {
+		Data  []graphMLDataIn `xml:"data"`
+		Nodes []struct {
+			Id   string          `xml:"id,attr"`
+			Data []graphMLDataIn `xml:"data"`
+		} `xml:"node"`
+		Edges []struct {
+			Id     string          `xml:"id,attr"`
+			Source string          `xml:"source,attr"`
+			Target string          `xml:"target,attr"`
+			Data   []graphMLDataIn `xml:"data"`
+		} `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLDataIn struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+	// the yEd labels, in the ShapeNode, GenericNode or PolyLineEdge elements
+	Graphics []struct {
+		NodeLabels []string `xml:"NodeLabel"`
+		EdgeLabels []string `xml:"EdgeLabel"`
+	} `xml:",any"`
+}
+
+// Read the data of an element by attribute name
+func graphMLAttrs(keys map[string]string, data []graphMLDataIn) map[string]string {
+	attrs := map[string]string{}
+	for _, d := range data {
+		for _, g := range d.Graphics {
+			for _, l := range append(g.NodeLabels, g.EdgeLabels...) {
+				if strings.TrimSpace(l) != "" && attrs["label"] == "" {
+					attrs["label"] = strings.TrimSpace(l)
+				}
+			}
+		}
+		if name := keys[d.Key]; name != "" && strings.TrimSpace(d.Value) != "" {
+			attrs[name] = d.Value
+		}
+	}
+	if attrs["content"] == "" {
+		attrs["content"] = attrs["label"]
+	}
+	return attrs
+}
+
+func readGraphML(b []byte) (*Document, error) {
+	in := graphMLIn{}
+	if err := xml.Unmarshal(b, &in); err != nil {
+		return nil, err
+	}
+	keys := map[string]string{}
+	for _, k := range in.Keys {
+		keys[k.Id] = strings.ToLower(k.AttrName)
+	}
+
+	doc := &Document{}
+	doc.Data.Graph.Name = graphMLAttrs(keys, in.Graph.Data)["name"]
+	for _, n := range in.Graph.Nodes {
+		attrs := graphMLAttrs(keys, n.Data)
+		doc.addWord("node "+n.Id, domain.Word{
+			Id:          n.Id,
+			Content:     attrs["content"],
+			Description: optional(attrs["description"]),
+			Refs:        parseRefs(attrs["refs"]),
+		})
+	}
+	for i, e := range in.Graph.Edges {
+		row := "edge " + e.Id
+		if e.Id == "" {
+			row = "edge " + strconv.Itoa(i)
+		}
+		doc.addRelation(row, domain.WordsLink{SourceId: e.Source, TargetId: e.Target})
+		attrs := graphMLAttrs(keys, e.Data)
+		if attrs["content"] != "" {
+			doc.addLink(row, domain.Link{
+				Id:          attrs["linkid"],
+				Word1Id:     e.Source,
+				Word2Id:     e.Target,
+				Content:     attrs["content"],
+				Description: optional(attrs["description"]),
+				Refs:        parseRefs(attrs["refs"]),
+			})
+		}
+	}
+	return doc, nil
+}
@@ -0,0 +1,61 @@
+package graphfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+func TestImportYEdGraphML(t *testing.T) {
+	// the labels of yEd, without the keys of the export
+	text := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">
+  <key for="node" id="d6" yfiles.type="nodegraphics"/>
+  <key for="edge" id="d10" yfiles.type="edgegraphics"/>
+  <key attr.name="description" attr.type="string" for="node" id="d5"/>
+  <graph edgedefault="directed" id="G">
+    <node id="n0">
+      <data key="d5">the sky cries</data>
+      <data key="d6"><y:ShapeNode><y:NodeLabel> Rain </y:NodeLabel></y:ShapeNode></data>
+    </node>
+    <node id="n1">
+      <data key="d6"><y:GenericNode><y:NodeLabel>Flood</y:NodeLabel></y:GenericNode></data>
+    </node>
+    <edge source="n0" target="n1">
+      <data key="d10"><y:PolyLineEdge><y:EdgeLabel>causes</y:EdgeLabel></y:PolyLineEdge></data>
+    </edge>
+  </graph>
+</graphml>`
+	doc, err := Import(strings.NewReader(text), FormatGraphML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.GraphExport{
+		Words: []domain.Word{
+			{Id: "n0", Content: "Rain", Description: optional("the sky cries")},
+			{Id: "n1", Content: "Flood"},
+		},
+		Relations: []domain.WordsLink{{SourceId: "n0", TargetId: "n1"}},
+		Links:     []domain.Link{{Word1Id: "n0", Word2Id: "n1", Content: "causes"}},
+	}
+	if got := graphContent(doc.Data); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Import() =\n%+v\nwant\n%+v", got, want)
+	}
+	if !reflect.DeepEqual(doc.RelationRows, []string{"edge 0"}) {
+		t.Fatalf("Import() rows of the edges = %v", doc.RelationRows)
+	}
+}
+
+func TestImportMalformedGraphML(t *testing.T) {
+	for name, text := range map[string]string{
+		"unclosed":   `<graphml><graph><node id="n0">`,
+		"not xml":    `{"words": []}`,
+		"bad entity": `<graphml><graph><node id="&nope;"/></graph></graphml>`,
+	} {
+		if doc, err := Import(strings.NewReader(text), FormatGraphML); err == nil {
+			t.Errorf("Import() of %s = %+v, want an error", name, doc)
+		}
+	}
+}
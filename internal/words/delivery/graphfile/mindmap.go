@@ -0,0 +1,165 @@
+package graphfile
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// Add a node of a tree as a word,
+// related to its parent word when it has one
+func (d *Document) addNode(row string, parentId string, content string, note string, refs *[]string) string {
+	id := "n" + strconv.Itoa(len(d.Data.Words)+1)
+	d.addWord(row, domain.Word{
+		Id:          id,
+		Content:     strings.TrimSpace(content),
+		Description: optional(strings.TrimSpace(note)),
+		Refs:        refs,
+	})
+	if parentId != "" {
+		d.addRelation(row, domain.WordsLink{SourceId: parentId, TargetId: id})
+	}
+	return id
+}
+
+var (
+	htmlBreaks = regexp.MustCompile(`(?i)<br\s*/?>|</p>|</li>|</div>`)
+	htmlTags   = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines = regexp.MustCompile(`\n\s*\n+`)
+)
+
+// Get the text of the html of the rich contents
+func htmlText(s string) string {
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = html.UnescapeString(htmlTags.ReplaceAllString(s, ""))
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		lines[i] = strings.Join(strings.Fields(l), " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n"))
+}
+
+type freeMindNode struct {
+	Id          string `xml:"ID,attr"`
+	Text        string `xml:"TEXT,attr"`
+	Link        string `xml:"LINK,attr"`
+	RichContent []struct {
+		Type  string `xml:"TYPE,attr"`
+		Inner string `xml:",innerxml"`
+	} `xml:"richcontent"`
+	// the notes of the FreeMind versions before 0.8
+	Hooks []struct {
+		Name string `xml:"NAME,attr"`
+		Text string `xml:"text"`
+	} `xml:"hook"`
+	Nodes []freeMindNode `xml:"node"`
+}
+
+func (d *Document) addFreeMindNode(n freeMindNode, parentId string, path string) {
+	content, note := n.Text, ""
+	for _, r := range n.RichContent {
+		switch strings.ToUpper(r.Type) {
+		case "NODE":
+			if content == "" {
+				content = htmlText(r.Inner)
+			}
+		case "NOTE", "DETAILS":
+			note = strings.TrimSpace(note + "\n" + htmlText(r.Inner))
+		}
+	}
+	for _, h := range n.Hooks {
+		if strings.Contains(h.Name, "NodeNote") {
+			note = strings.TrimSpace(note + "\n" + h.Text)
+		}
+	}
+	var refs *[]string
+	if n.Link != "" {
+		refs = &[]string{n.Link}
+	}
+
+	row := "node " + path
+	if n.Id != "" {
+		row = "node " + n.Id
+	}
+	id := d.addNode(row, parentId, content, note, refs)
+	for i, child := range n.Nodes {
+		d.addFreeMindNode(child, id, path+"."+strconv.Itoa(i+1))
+	}
+}
+
+func readFreeMind(b []byte) (*Document, error) {
+	in := struct {
+		Nodes []freeMindNode `xml:"node"`
+	}{}
+	if err := xml.Unmarshal(b, &in); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	for i, n := range in.Nodes {
+		doc.addFreeMindNode(n, "", strconv.Itoa(i+1))
+	}
+	// the map is named after its root
+	if len(doc.Data.Words) > 0 {
+		doc.Data.Graph.Name = doc.Data.Words[0].Content
+	}
+	return doc, nil
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	Note     string        `xml:"_note,attr"`
+	Url      string        `xml:"url,attr"`
+	HtmlUrl  string        `xml:"htmlUrl,attr"`
+	XmlUrl   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (d *Document) addOPMLOutline(o opmlOutline, parentId string, path string) {
+	content := o.Text
+	if content == "" {
+		content = o.Title
+	}
+	refs := []string{}
+	for _, url := range []string{o.Url, o.HtmlUrl, o.XmlUrl} {
+		if url != "" {
+			refs = append(refs, url)
+		}
+	}
+	var refsPtr *[]string
+	if len(refs) > 0 {
+		refsPtr = &refs
+	}
+
+	id := d.addNode("outline "+path, parentId, htmlText(content), o.Note, refsPtr)
+	for i, child := range o.Outlines {
+		d.addOPMLOutline(child, id, path+"."+strconv.Itoa(i+1))
+	}
+}
+
+func readOPML(b []byte) (*Document, error) {
+	in := struct {
+		Title    string        `xml:"head>title"`
+		Outlines []opmlOutline `xml:"body>outline"`
+	}{}
+	dec := xml.NewDecoder(bytes.NewReader(b))
+	// the OPML files often hold html entities
+	dec.Strict = false
+	dec.Entity = xml.HTMLEntity
+	if err := dec.Decode(&in); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{}
+	doc.Data.Graph.Name = strings.TrimSpace(in.Title)
+	for i, o := range in.Outlines {
+		doc.addOPMLOutline(o, "", strconv.Itoa(i+1))
+	}
+	return doc, nil
+}
@@ -0,0 +1,115 @@
+package graphfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// The tree of a document as "parent > child" lines of the contents,
+// with the notes and refs of the words
+func treeOf(doc *Document) []string {
+	contents := map[string]string{}
+	lines := []string{}
+	for _, w := range doc.Data.Words {
+		contents[w.Id] = w.Content
+		line := w.Content
+		if w.Description != nil {
+			line += " (" + *w.Description + ")"
+		}
+		if w.Refs != nil {
+			line += " " + strings.Join(*w.Refs, " ")
+		}
+		lines = append(lines, line)
+	}
+	for _, r := range doc.Data.Relations {
+		lines = append(lines, contents[r.SourceId]+" > "+contents[r.TargetId])
+	}
+	return lines
+}
+
+func TestImportFreeMind(t *testing.T) {
+	text := `<map version="1.0.1">
+<node ID="root" TEXT="Weather">
+  <node TEXT="Rain" LINK="https://example.com/rain">
+    <richcontent TYPE="NOTE"><html><body><p>falls from the <b>clouds</b></p><p>often</p></body></html></richcontent>
+    <node TEXT="Flood"/>
+  </node>
+  <node>
+    <richcontent TYPE="NODE"><html><body><p>Sun &amp; heat</p></body></html></richcontent>
+    <hook NAME="accessories/plugins/NodeNote.properties"><text>an old note</text></hook>
+  </node>
+</node>
+</map>`
+	doc, err := Import(strings.NewReader(text), FormatFreeMind)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Weather",
+		"Rain (falls from the clouds\noften) https://example.com/rain",
+		"Flood",
+		"Sun & heat (an old note)",
+		"Weather > Rain",
+		"Rain > Flood",
+		"Weather > Sun & heat",
+	}
+	if got := treeOf(doc); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Import() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if doc.Data.Graph.Name != "Weather" {
+		t.Fatalf("Import() named the graph %q", doc.Data.Graph.Name)
+	}
+	if want := []string{"node root", "node 1.1", "node 1.1.1", "node 1.2"}; !reflect.DeepEqual(doc.WordRows, want) {
+		t.Fatalf("Import() rows = %v, want %v", doc.WordRows, want)
+	}
+}
+
+func TestImportOPML(t *testing.T) {
+	text := `<?xml version="1.0"?>
+<opml version="2.0">
+<head><title> Weather &eacute;t&eacute; </title></head>
+<body>
+  <outline text="Rain" _note="falls" url="https://example.com/rain">
+    <outline text="&lt;b&gt;Flood&lt;/b&gt;"/>
+    <outline title="Feed" xmlUrl="https://example.com/feed.xml" htmlUrl="https://example.com"/>
+  </outline>
+  <outline text="Sun"/>
+</body>
+</opml>`
+	doc, err := Import(strings.NewReader(text), FormatOPML)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Rain (falls) https://example.com/rain",
+		"Flood",
+		"Feed https://example.com https://example.com/feed.xml",
+		"Sun",
+		"Rain > Flood",
+		"Rain > Feed",
+	}
+	if got := treeOf(doc); !reflect.DeepEqual(got, want) {
+		t.Fatalf("Import() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if doc.Data.Graph.Name != "Weather été" {
+		t.Fatalf("Import() named the graph %q", doc.Data.Graph.Name)
+	}
+}
+
+func TestImportMalformedMindMaps(t *testing.T) {
+	for _, test := range []struct {
+		format Format
+		text   string
+	}{
+		{FormatFreeMind, `<map><node TEXT="Rain">`},
+		{FormatFreeMind, `<map><node TEXT="a < b"/></map>`},
+		{FormatFreeMind, `Rain`},
+		{FormatOPML, `<opml><body><outline text="Rain`},
+		{FormatOPML, `{"outline": []}`},
+	} {
+		if doc, err := Import(strings.NewReader(test.text), test.format); err == nil {
+			t.Errorf("Import(%s) of %q = %v, want an error", test.format, test.text, treeOf(doc))
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package graphfile
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	outlineHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	// the bullets and the numbered items
+	outlineBullet = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.*)$`)
+)
+
+// Measure the indentation of a line, a tab is 4 spaces
+func indentOf(line string) int {
+	n := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			n++
+		case '\t':
+			n += 4
+		default:
+			return n
+		}
+	}
+	return n
+}
+
+// Read an outline, the Markdown headings and bullets or plain indented lines.
+// The other lines of a Markdown outline are the notes of the item above.
+func readOutline(b []byte) (*Document, error) {
+	lines := []string{}
+	markdown := false
+	s := bufio.NewScanner(bytes.NewReader(b))
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line := strings.TrimRight(s.Text(), " \t\r")
+		trimmed := strings.TrimSpace(line)
+		if outlineHeading.MatchString(trimmed) || outlineBullet.MatchString(trimmed) {
+			markdown = true
+		}
+		lines = append(lines, line)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	type item struct {
+		// the headings come before any indentation
+		level int
+		id    string
+		note  []string
+	}
+	doc := &Document{}
+	stack := []*item{}
+	notes := map[string][]string{}
+	var last *item
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		level, content := indentOf(line), trimmed
+		if m := outlineHeading.FindStringSubmatch(trimmed); m != nil {
+			level, content = len(m[1])-10, m[2]
+		} else if m := outlineBullet.FindStringSubmatch(trimmed); m != nil {
+			content = m[1]
+		} else if markdown {
+			if last != nil {
+				notes[last.id] = append(notes[last.id], trimmed)
+			}
+			continue
+		}
+
+		for len(stack) > 0 && stack[len(stack)-1].level >= level {
+			stack = stack[:len(stack)-1]
+		}
+		parentId := ""
+		if len(stack) > 0 {
+			parentId = stack[len(stack)-1].id
+		}
+		last = &item{level: level, id: doc.addNode(fmt.Sprintf("line %d", i+1), parentId, content, "", nil)}
+		stack = append(stack, last)
+	}
+
+	for i := range doc.Data.Words {
+		w := &doc.Data.Words[i]
+		if n := notes[w.Id]; len(n) > 0 {
+			w.Description = optional(strings.Join(n, "\n"))
+		}
+	}
+	return doc, nil
+}
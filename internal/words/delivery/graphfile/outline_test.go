@@ -0,0 +1,44 @@
+package graphfile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestImportOutline(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"indented text", "Weather\n    Rain\n\tFlood\n  \n        Drop\nSun\n", []string{
+			"Weather", "Rain", "Flood", "Drop", "Sun",
+			"Weather > Rain", "Weather > Flood", "Flood > Drop",
+		}},
+		{"markdown", "# Weather\nthe sky\n## Rain\n- Flood\n  - [x] Drop\n    more water\n1. Sun\n# Seasons\n* Summer\n", []string{
+			"Weather (the sky)", "Rain", "Flood", "Drop (more water)", "Sun", "Seasons", "Summer",
+			"Weather > Rain", "Rain > Flood", "Flood > Drop", "Rain > Sun", "Seasons > Summer",
+		}},
+		{"dedented under the root", "Weather\n        Rain\n    Flood\n", []string{
+			"Weather", "Rain", "Flood",
+			"Weather > Rain", "Weather > Flood",
+		}},
+		{"empty", "\n \n", []string{}},
+	} {
+		doc, err := Import(strings.NewReader(test.text), FormatOutline)
+		if err != nil {
+			t.Fatalf("Import() of the %s = %v", test.name, err)
+		}
+		if got := treeOf(doc); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("Import() of the %s =\n%s\nwant\n%s", test.name, strings.Join(got, "\n"), strings.Join(test.want, "\n"))
+		}
+	}
+}
+
+func TestImportOutlineRefusesTheLongLines(t *testing.T) {
+	text := "Weather\n" + strings.Repeat("a", 1024*1024) + "\n"
+	if doc, err := Import(strings.NewReader(text), FormatOutline); err == nil {
+		t.Fatalf("Import() of a line of 1MB = %d words, want an error", len(doc.Data.Words))
+	}
+}
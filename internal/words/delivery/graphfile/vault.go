@@ -0,0 +1,301 @@
+package graphfile
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// VaultOptions tell how the wiki links of the notes are resolved
+type VaultOptions struct {
+	// the links may name a note by one of its front matter aliases
+	ResolveAliases bool
+	// the links to missing notes are reported instead of
+	// being added as words without description
+	SkipUnresolved bool
+}
+
+// size limit of a note of the vault once unzipped
+const maxNoteSize = 1 << 20
+
+// the limits of the word schema
+const (
+	maxContentLength     = 50
+	maxDescriptionLength = 512
+)
+
+var (
+	vaultFence    = regexp.MustCompile("(?ms)^(```|~~~).*?^(```|~~~)[^\n]*$")
+	vaultCode     = regexp.MustCompile("`[^`\n]*`")
+	vaultWikiLink = regexp.MustCompile(`(!?)\[\[([^\]|#^]*)([#^][^\]|]*)?(?:\|([^\]]*))?\]\]`)
+	vaultMdLink   = regexp.MustCompile(`!?\[([^\]]*)\]\([^)]*\)`)
+	vaultUrl      = regexp.MustCompile(`https?://[^\s<>()\[\]"'` + "`" + `]+`)
+	vaultHeading  = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	// the files linked or embedded which aren't notes
+	vaultAttachment = regexp.MustCompile(`(?i)\.[a-z][a-z0-9]{0,4}$`)
+)
+
+type vaultNote struct {
+	path    string
+	title   string
+	body    string
+	aliases []string
+}
+
+// Tell the files of the vault which aren't notes,
+// the hidden folders hold the settings of the editors
+func isVaultNote(name string) bool {
+	for _, s := range strings.Split(name, "/") {
+		if strings.HasPrefix(s, ".") || s == "__MACOSX" {
+			return false
+		}
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+// Shorten s to n runes
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n-1]) + "…"
+}
+
+// Split the yaml front matter from the body of a note
+// and read the aliases it gives to the note
+func splitFrontMatter(text string) (aliases []string, body string, err error) {
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, text, nil
+	}
+	// the front matter ends with the next line of dashes
+	lines := strings.SplitAfter(text[4:], "\n")
+	end := -1
+	for i, l := range lines {
+		if strings.TrimRight(l, " \n") == "---" {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return nil, text, nil
+	}
+	front, body := strings.Join(lines[:end], ""), strings.Join(lines[end+1:], "")
+
+	meta := struct {
+		Aliases interface{} `yaml:"aliases"`
+		Alias   interface{} `yaml:"alias"`
+	}{}
+	if err := yaml.Unmarshal([]byte(front), &meta); err != nil {
+		return nil, body, err
+	}
+	for _, v := range []interface{}{meta.Aliases, meta.Alias} {
+		switch v := v.(type) {
+		case string:
+			// the former aliases of Obsidian are separated by commas
+			for _, a := range strings.Split(v, ",") {
+				aliases = append(aliases, strings.TrimSpace(a))
+			}
+		case []interface{}:
+			for _, a := range v {
+				if s, ok := a.(string); ok {
+					aliases = append(aliases, strings.TrimSpace(s))
+				}
+			}
+		}
+	}
+	return aliases, body, nil
+}
+
+// Get the plain text of the beginning of a note
+func noteExcerpt(body string) string {
+	s := vaultFence.ReplaceAllString(body, "")
+	s = vaultWikiLink.ReplaceAllStringFunc(s, func(l string) string {
+		m := vaultWikiLink.FindStringSubmatch(l)
+		switch {
+		case m[1] != "":
+			return ""
+		case m[4] != "":
+			return m[4]
+		}
+		return m[2]
+	})
+	s = vaultMdLink.ReplaceAllString(s, "$1")
+	s = vaultHeading.ReplaceAllString(s, "")
+	return truncate(strings.Join(strings.Fields(s), " "), maxDescriptionLength)
+}
+
+// Get the external urls of a note
+func noteRefs(body string) *[]string {
+	refs := []string{}
+	seen := map[string]bool{}
+	for _, url := range vaultUrl.FindAllString(body, -1) {
+		url = strings.TrimRight(url, ".,;:!?*_~")
+		if !seen[url] {
+			seen[url] = true
+			refs = append(refs, url)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return &refs
+}
+
+// Name the graph after the folder holding all the notes
+func vaultName(notes []vaultNote) string {
+	name := ""
+	for _, n := range notes {
+		i := strings.IndexByte(n.path, '/')
+		if i < 0 || (name != "" && name != n.path[:i]) {
+			return ""
+		}
+		name = n.path[:i]
+	}
+	return name
+}
+
+// Read a zip of Markdown notes, a word for each note and
+// a relationship for each of the wiki links between them
+func ImportVault(r io.Reader, opts VaultOptions) (*Document, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readVault(b, opts)
+}
+
+func readVault(b []byte, opts VaultOptions) (*Document, error) {
+	z, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, err
+	}
+	return readVaultZip(z, opts)
+}
+
+func readVaultZip(z *zip.Reader, opts VaultOptions) (*Document, error) {
+	doc := &Document{}
+	notes := []vaultNote{}
+	for _, f := range z.File {
+		if f.FileInfo().IsDir() || !isVaultNote(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		text, err := io.ReadAll(io.LimitReader(rc, maxNoteSize+1))
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		if len(text) > maxNoteSize {
+			doc.addError(f.Name, "the note is too large")
+			continue
+		}
+
+		s := strings.ReplaceAll(string(text), "\r\n", "\n")
+		aliases, body, err := splitFrontMatter(strings.TrimPrefix(s, "\ufeff"))
+		if err != nil {
+			doc.addError(f.Name, "invalid front matter: "+err.Error())
+		}
+		notes = append(notes, vaultNote{
+			path:    f.Name,
+			title:   strings.TrimSuffix(path.Base(f.Name), path.Ext(f.Name)),
+			body:    body,
+			aliases: aliases,
+		})
+	}
+	if len(notes) == 0 {
+		return nil, errors.New("the zip holds no Markdown notes")
+	}
+	sort.SliceStable(notes, func(i, j int) bool { return notes[i].path < notes[j].path })
+	doc.Data.Graph.Name = vaultName(notes)
+
+	// the notes are found by their path, their name or one of their aliases,
+	// the first note in the order of the paths wins
+	paths := make([]string, len(notes))
+	aliases := map[string]string{}
+	for i, n := range notes {
+		id := "n" + strconv.Itoa(i+1)
+		doc.addWord(n.path, domain.Word{
+			Id:          id,
+			Content:     truncate(n.title, maxContentLength),
+			Description: optional(noteExcerpt(n.body)),
+			Refs:        noteRefs(n.body),
+		})
+		paths[i] = "/" + strings.ToLower(strings.TrimSuffix(n.path, path.Ext(n.path)))
+		for _, a := range n.aliases {
+			if _, ok := aliases[strings.ToLower(a)]; !ok && a != "" {
+				aliases[strings.ToLower(a)] = id
+			}
+		}
+	}
+	resolve := func(target string) string {
+		// the name of the note or its path from any folder of the vault
+		key := strings.ToLower(strings.Trim(target, "/"))
+		for i, p := range paths {
+			if strings.HasSuffix(p, "/"+key) {
+				return doc.Data.Words[i].Id
+			}
+		}
+		if opts.ResolveAliases {
+			return aliases[key]
+		}
+		return ""
+	}
+
+	// the missing notes are words without description,
+	// as the editors show them
+	missing := map[string]string{}
+	for i, n := range notes {
+		sourceId := doc.Data.Words[i].Id
+		body := vaultCode.ReplaceAllString(vaultFence.ReplaceAllString(n.body, ""), "")
+		for _, m := range vaultWikiLink.FindAllStringSubmatch(body, -1) {
+			target := strings.TrimSpace(m[2])
+			if ext := strings.ToLower(path.Ext(target)); ext == ".md" || ext == ".markdown" {
+				target = strings.TrimSuffix(target, path.Ext(target))
+			}
+			if target == "" {
+				// a heading of the note itself
+				continue
+			}
+
+			row := n.path + ": " + m[0]
+			targetId := resolve(target)
+			if targetId == "" && (m[1] != "" || vaultAttachment.MatchString(target)) {
+				// the embedded images and attachments
+				continue
+			}
+			if targetId == "" {
+				if opts.SkipUnresolved {
+					doc.addError(row, "unresolved link "+target)
+					continue
+				}
+				key := strings.ToLower(target)
+				if missing[key] == "" {
+					missing[key] = doc.addNode(row, "", truncate(path.Base(target), maxContentLength), "", nil)
+				}
+				targetId = missing[key]
+			}
+			if targetId != sourceId {
+				doc.addRelation(row, domain.WordsLink{SourceId: sourceId, TargetId: targetId})
+			}
+		}
+	}
+	return doc, nil
+}
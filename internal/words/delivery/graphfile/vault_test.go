@@ -0,0 +1,127 @@
+package graphfile
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// A vault with links by name, path, alias and heading,
+// embeds, links in code and a link to a missing note
+func vaultFixture(t *testing.T) []byte {
+	return zipOf(t, map[string]string{
+		"Vault/Rain.md": "---\naliases: [Precipitation]\n---\n# Rain\n" +
+			"Falls in [[Flood|the flood]] from the [[Cloud#Types]], see [[sub/Sun.md]] and [[precipitation]].\n" +
+			"![[image.png]] ![[Missing embed]] `[[Code]]`\n```\n[[Fenced]]\n```\n" +
+			"More at https://example.com/rain. and [[Nowhere]]\n",
+		"Vault/Flood.md":         "Too much [[Wet]] water.",
+		"Vault/Cloud.md":         "---\nalias: Wet, Damp\n---\nUp there.",
+		"Vault/sub/Sun.md":       "After the [[rain]].",
+		"Vault/Bad.md":           "---\naliases: [unclosed\n---\nStill a note.",
+		"Vault/Big.md":           strings.Repeat("a", maxNoteSize+1),
+		"Vault/.obsidian/app.md": "[[Rain]]",
+		"__MACOSX/Vault/Rain.md": "[[Rain]]",
+		"Vault/image.png":        "png",
+	})
+}
+
+func TestImportVault(t *testing.T) {
+	doc, err := ImportVault(bytes.NewReader(vaultFixture(t)), VaultOptions{ResolveAliases: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"Bad (Still a note.)",
+		"Cloud (Up there.)",
+		"Flood (Too much Wet water.)",
+		"Rain (Rain Falls in the flood from the Cloud, see sub/Sun.md and precipitation. `Code` More at https://example.com/rain. and Nowhere) https://example.com/rain",
+		"Sun (After the rain.)",
+		"Nowhere",
+		"Flood > Cloud",
+		"Rain > Flood",
+		"Rain > Cloud",
+		"Rain > Sun",
+		"Rain > Nowhere",
+		"Sun > Rain",
+	}
+	if got := treeOf(doc); !reflect.DeepEqual(got, want) {
+		t.Fatalf("ImportVault() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if doc.Data.Graph.Name != "Vault" {
+		t.Fatalf("ImportVault() named the graph %q", doc.Data.Graph.Name)
+	}
+	errs := []string{}
+	for _, e := range doc.Errors {
+		errs = append(errs, e.Row+": "+strings.SplitN(e.Message, ":", 2)[0])
+	}
+	if want := []string{"Vault/Bad.md: invalid front matter", "Vault/Big.md: the note is too large"}; !reflect.DeepEqual(errs, want) {
+		t.Fatalf("ImportVault() errors = %v, want %v", errs, want)
+	}
+}
+
+func TestImportVaultOptions(t *testing.T) {
+	// the aliases are missing notes when they aren't resolved
+	doc, err := ImportVault(bytes.NewReader(vaultFixture(t)), VaultOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := strings.Join(treeOf(doc), "\n")
+	for _, line := range []string{"Flood > Wet", "Rain > precipitation", "Rain > Nowhere"} {
+		if !strings.Contains(tree, line+"\n") {
+			t.Errorf("ImportVault() without the aliases misses %q:\n%s", line, tree)
+		}
+	}
+
+	// the unresolved links are reported
+	doc, err = ImportVault(bytes.NewReader(vaultFixture(t)), VaultOptions{ResolveAliases: true, SkipUnresolved: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Data.Words) != 5 || len(doc.Data.Relations) != 5 {
+		t.Fatalf("ImportVault() skipping the unresolved links =\n%s", strings.Join(treeOf(doc), "\n"))
+	}
+	last := doc.Errors[len(doc.Errors)-1]
+	if last.Row != "Vault/Rain.md: [[Nowhere]]" || last.Message != "unresolved link Nowhere" {
+		t.Fatalf("ImportVault() errors = %+v", doc.Errors)
+	}
+}
+
+func TestImportVaultLimits(t *testing.T) {
+	title := strings.Repeat("é", maxContentLength+10)
+	body := strings.Repeat("word ", maxDescriptionLength)
+	doc, err := ImportVault(bytes.NewReader(zipOf(t, map[string]string{title + ".md": body})), VaultOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := doc.Data.Words[0]
+	if n := len([]rune(w.Content)); n != maxContentLength || !strings.HasSuffix(w.Content, "…") {
+		t.Fatalf("ImportVault() content of %d runes: %q", n, w.Content)
+	}
+	if n := len([]rune(*w.Description)); n != maxDescriptionLength {
+		t.Fatalf("ImportVault() description of %d runes", n)
+	}
+	// a note at the root doesn't name the graph
+	if doc.Data.Graph.Name != "" {
+		t.Fatalf("ImportVault() named the graph %q", doc.Data.Graph.Name)
+	}
+}
+
+func TestImportMalformedVault(t *testing.T) {
+	for name, b := range map[string][]byte{
+		"not a zip": []byte("# Rain\n[[Flood]]"),
+		"no notes":  zipOf(t, map[string]string{"Vault/image.png": "png", "Vault/.obsidian/app.md": "[[Rain]]"}),
+		"empty zip": zipOf(t, map[string]string{}),
+		"too large": zipOf(t, map[string]string{"Vault/Big.md": strings.Repeat("a", maxNoteSize+1)}),
+	} {
+		if doc, err := Import(bytes.NewReader(b), FormatVault); err == nil {
+			t.Errorf("Import() of %s = %v, want an error", name, treeOf(doc))
+		}
+	}
+
+	// a zip of notes uploaded as csv is a vault
+	doc, err := Import(bytes.NewReader(vaultFixture(t)), FormatCSV)
+	if err != nil || len(doc.Data.Words) != 6 {
+		t.Fatalf("Import(csv) of a vault = %v, %v", doc, err)
+	}
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/delivery/graphfile"
+)
+
+type ExportHandler struct {
+	exportUsecase domain.ExportUsecase
+}
+
+func InitExportHandlers(eus domain.ExportUsecase) *ExportHandler {
+	return &ExportHandler{
+		exportUsecase: eus,
+	}
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^\pL\pN_.-]+`)
+
+// Name the downloaded file after the graph
+func exportFileName(graph domain.Graph, extension string) string {
+	name := unsafeFileChars.ReplaceAllString(graph.Name, "_")
+	if name == "" || name == "_" {
+		name = "graph"
+	}
+	return name + "." + extension
+}
+
+func (h *ExportHandler) Export(c *gin.Context) {
+	id := c.Param("id")
+	format := graphfile.Format(c.DefaultQuery("format", string(graphfile.FormatJSON)))
+	if id == "" || !graphfile.CanExport(format) {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	data, err := h.exportUsecase.Export(c, id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+
+	// written to a buffer first so a failure still gets an error response
+	var buf bytes.Buffer
+	if err := graphfile.Export(&buf, format, *data); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrInternalServerError)
+		return
+	}
+	extension, contentType := graphfile.FileType(format)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, exportFileName(data.Graph, extension)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
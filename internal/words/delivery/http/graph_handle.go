@@ -38,7 +38,7 @@ func (h *GraphHandler) Detail(c *gin.Context) {
 		return
 	}
 
-	res, err := h.graphUsecase.Get(c, id)
+	res, err := h.graphUsecase.Get(c, id, authCommon.ExtractUser(c))
 	if err != nil {
 		httpCommon.ErrorResponse(c, err)
 		return
@@ -62,8 +62,9 @@ func (h *GraphHandler) CreateGraph(c *gin.Context) {
 	}
 
 	_graph := domain.Graph{
-		UserId: authCommon.ExtractUser(c).Id,
-		Name:   schema.Name,
+		UserId:     authCommon.ExtractUser(c).Id,
+		Name:       schema.Name,
+		Visibility: domain.GraphVisibilityMap[schema.Visibility],
 	}
 
 	var id *string
@@ -75,9 +76,13 @@ func (h *GraphHandler) CreateGraph(c *gin.Context) {
 		return
 	}
 
+	if _graph.Visibility == "" {
+		_graph.Visibility = domain.GraphVisibilityPrivate
+	}
 	c.JSON(http.StatusOK, domain.Graph{
-		Id:   *id,
-		Name: schema.Name,
+		Id:         *id,
+		Name:       schema.Name,
+		Visibility: _graph.Visibility,
 	})
 }
 
@@ -100,8 +105,9 @@ func (h *GraphHandler) UpdateGraph(c *gin.Context) {
 	}
 
 	err := h.graphUsecase.Update(c, id, domain.Graph{
-		Name: schema.Name,
-		Type: schema.Type,
+		Name:       schema.Name,
+		Type:       schema.Type,
+		Visibility: domain.GraphVisibilityMap[schema.Visibility],
 	},
 		authCommon.ExtractUser(c),
 	)
@@ -129,3 +135,56 @@ func (h *GraphHandler) DeleteGraph(c *gin.Context) {
 
 	c.JSON(http.StatusOK, nil)
 }
+
+// List the graphs deleted by the user, which may still be restored
+func (h *GraphHandler) ListDeleted(c *gin.Context) {
+	res, err := h.graphUsecase.ListDeleted(c, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *GraphHandler) RestoreGraph(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.graphUsecase.Restore(c, id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *GraphHandler) CloneGraph(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	// the body is optional, the clone keeps the name of the graph
+	var schema GraphCloneRequestSchema
+	if c.Request.ContentLength > 0 {
+		if err := c.Bind(&schema); err != nil {
+			httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+			return
+		}
+	}
+	if err := validator.New().Struct(schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.graphUsecase.Clone(c, id, schema.Name, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
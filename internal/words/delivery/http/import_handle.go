@@ -0,0 +1,250 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/delivery/graphfile"
+)
+
+// size limit of the imported files
+const maxImportSize = 10 << 20
+
+type ImportHandler struct {
+	importUsecase domain.ImportUsecase
+}
+
+func InitImportHandlers(ius domain.ImportUsecase) *ImportHandler {
+	return &ImportHandler{
+		importUsecase: ius,
+	}
+}
+
+// Guess the format of an uploaded file from its name
+func formatOfFile(name string) graphfile.Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".graphml", ".xml":
+		return graphfile.FormatGraphML
+	case ".zip", ".csv":
+		return graphfile.FormatCSV
+	case ".mm":
+		return graphfile.FormatFreeMind
+	case ".opml":
+		return graphfile.FormatOPML
+	case ".txt", ".md", ".markdown":
+		return graphfile.FormatOutline
+	}
+	return graphfile.FormatJSON
+}
+
+// Read the options of a vault from the query,
+// the aliases are resolved unless told otherwise
+func vaultOptions(c *gin.Context) (opts graphfile.VaultOptions, err error) {
+	opts.ResolveAliases, err = strconv.ParseBool(c.DefaultQuery("resolveAliases", "true"))
+	if err != nil {
+		return opts, errors.New("invalid resolveAliases")
+	}
+	opts.SkipUnresolved, err = strconv.ParseBool(c.DefaultQuery("skipUnresolved", "false"))
+	if err != nil {
+		return opts, errors.New("invalid skipUnresolved")
+	}
+	return opts, nil
+}
+
+// Read the file in the format,
+// the vaults are read with the options of the query
+func importFile(c *gin.Context, r io.Reader, format graphfile.Format) (*graphfile.Document, error) {
+	if format != graphfile.FormatVault {
+		return graphfile.Import(r, format)
+	}
+	opts, err := vaultOptions(c)
+	if err != nil {
+		return nil, err
+	}
+	return graphfile.ImportVault(r, opts)
+}
+
+// Read the graph of the request, either the body in the format of the query,
+// an uploaded file or the uploaded nodes and edges csv files
+func readImport(c *gin.Context) (*graphfile.Document, error) {
+	format := graphfile.Format(c.Query("format"))
+	if c.ContentType() != "multipart/form-data" {
+		if format == "" {
+			format = graphfile.FormatJSON
+		}
+		if !graphfile.CanImport(format) {
+			return nil, fmt.Errorf("unsupported format %s", format)
+		}
+		return importFile(c, c.Request.Body, format)
+	}
+
+	if nodes, err := c.FormFile("nodes"); err == nil {
+		n, err := nodes.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer n.Close()
+		var e io.Reader
+		if edges, err := c.FormFile("edges"); err == nil {
+			f, err := edges.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			e = f
+		}
+		return graphfile.ImportCSV(n, e)
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		return nil, errors.New("the file is missing")
+	}
+	if format == "" {
+		format = formatOfFile(file.Filename)
+	}
+	if !graphfile.CanImport(format) {
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var doc *graphfile.Document
+	// a single csv file holds the nodes
+	if format == graphfile.FormatCSV && strings.EqualFold(filepath.Ext(file.Filename), ".csv") {
+		doc, err = graphfile.ImportCSV(f, nil)
+	} else {
+		doc, err = importFile(c, f, format)
+	}
+	// the graph is named after the file when the file has no name for it
+	if err == nil && doc.Data.Graph.Name == "" {
+		doc.Data.Graph.Name = strings.TrimSuffix(filepath.Base(file.Filename), filepath.Ext(file.Filename))
+	}
+	return doc, err
+}
+
+// Tell which limits of the schema the row breaks
+func validationMessage(err error) string {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err.Error()
+	}
+	messages := []string{}
+	for _, e := range errs {
+		m := strings.ToLower(e.Field()) + " " + e.Tag()
+		if e.Param() != "" {
+			m += "=" + e.Param()
+		}
+		messages = append(messages, m)
+	}
+	return "invalid " + strings.Join(messages, ", ")
+}
+
+// Keep the rows of the document which pass the limits of the word and link schemas,
+// and the relationships and links between the words which are kept
+func validateImport(doc *graphfile.Document) (domain.GraphExport, []domain.ImportError) {
+	v := validator.New()
+	data := domain.GraphExport{Graph: doc.Data.Graph}
+	errs := append([]domain.ImportError{}, doc.Errors...)
+	// an edge row is read as a relationship and a link, it is reported once
+	reported := map[domain.ImportError]bool{}
+	fail := func(row string, message string) {
+		e := domain.ImportError{Row: row, Message: message}
+		if !reported[e] {
+			reported[e] = true
+			errs = append(errs, e)
+		}
+	}
+
+	words := map[string]bool{}
+	for i, w := range doc.Data.Words {
+		row := doc.WordRows[i]
+		if w.Id == "" {
+			fail(row, "missing id")
+			continue
+		}
+		if words[w.Id] {
+			fail(row, "duplicated id "+w.Id)
+			continue
+		}
+		err := v.Struct(WordUpdateRequestSchema{
+			Content:     w.Content,
+			Description: w.Description,
+			Refs:        w.Refs,
+		})
+		if err != nil {
+			fail(row, validationMessage(err))
+			continue
+		}
+		words[w.Id] = true
+		data.Words = append(data.Words, w)
+	}
+
+	for i, r := range doc.Data.Relations {
+		if !words[r.SourceId] || !words[r.TargetId] {
+			fail(doc.RelationRows[i], "unknown word "+r.SourceId+" or "+r.TargetId)
+			continue
+		}
+		data.Relations = append(data.Relations, r)
+	}
+
+	for i, l := range doc.Data.Links {
+		row := doc.LinkRows[i]
+		if !words[l.Word1Id] || !words[l.Word2Id] {
+			fail(row, "unknown word "+l.Word1Id+" or "+l.Word2Id)
+			continue
+		}
+		err := v.Struct(LinkUpdateRequestSchema{
+			Content:     l.Content,
+			Description: l.Description,
+			Refs:        l.Refs,
+		})
+		if err != nil {
+			fail(row, validationMessage(err))
+			continue
+		}
+		data.Links = append(data.Links, l)
+	}
+	return data, errs
+}
+
+func (h *ImportHandler) Import(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	doc, err := readImport(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.NewError("file", err))
+		return
+	}
+
+	data, errs := validateImport(doc)
+	if name := c.Query("name"); name != "" {
+		data.Graph.Name = name
+	}
+	if err := validator.New().Var(data.Graph.Name, "max=128"); err != nil {
+		c.JSON(http.StatusBadRequest, common.NewError("name", err))
+		return
+	}
+
+	// an id merges the file into the graph
+	res, err := h.importUsecase.Import(c, data, c.Param("id"), authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	res.Errors = append(errs, res.Errors...)
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/delivery/graphfile"
+)
+
+// importUsecase keeps the graph it is given
+type importUsecase struct {
+	data *domain.GraphExport
+}
+
+func (u *importUsecase) Import(c context.Context, data domain.GraphExport, graphId string, user domain.Profile) (*domain.ImportResult, error) {
+	u.data = &data
+	return &domain.ImportResult{}, nil
+}
+
+func postImport(body []byte, query string) (*httptest.ResponseRecorder, *importUsecase) {
+	gin.SetMode(gin.TestMode)
+	u := &importUsecase{}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/graphs/import?"+query, bytes.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Set("user", domain.Profile{Id: "u1"})
+	InitImportHandlers(u).Import(c)
+	return w, u
+}
+
+func TestImportRefusesTheLargeFiles(t *testing.T) {
+	// a valid document padded past the limit
+	body := []byte(`{"graph": {"name": "rain"}, "words": []}` + strings.Repeat(" ", maxImportSize))
+	if w, u := postImport(body, "format=json"); w.Code != http.StatusBadRequest || u.data != nil {
+		t.Fatalf("Import() of %d bytes = %d, imported %v", len(body), w.Code, u.data != nil)
+	}
+
+	body = body[:maxImportSize]
+	if w, u := postImport(body, "format=json"); w.Code != http.StatusOK || u.data == nil {
+		t.Fatalf("Import() of %d bytes = %d %s", len(body), w.Code, w.Body)
+	}
+}
+
+func TestImportMalformedFiles(t *testing.T) {
+	for _, query := range []string{"format=json", "format=graphml", "format=csv", "format=pdf"} {
+		if w, u := postImport([]byte(`<graphml><graph>{"words": [`), query); w.Code != http.StatusBadRequest || u.data != nil {
+			t.Errorf("Import(%s) of a malformed file = %d", query, w.Code)
+		}
+	}
+	// the name of the graph is checked too
+	if w, _ := postImport([]byte(`{"words": []}`), "name="+strings.Repeat("a", 129)); w.Code != http.StatusBadRequest {
+		t.Errorf("Import() with a long name = %d", w.Code)
+	}
+}
+
+func TestValidateImportKeepsTheValidRows(t *testing.T) {
+	long := strings.Repeat("a", 51)
+	doc := &graphfile.Document{}
+	doc.Data.Words = []domain.Word{
+		{Id: "w1", Content: "rain"},
+		{Id: "w2", Content: "flood"},
+		{Id: "w2", Content: "flood again"},
+		{Id: "", Content: "no id"},
+		{Id: "w3", Content: long},
+		{Id: "w4", Content: "sun", Description: common.ToPointer(strings.Repeat("a", 513))},
+		{Id: "w5", Content: "cloud", Refs: &[]string{""}},
+	}
+	doc.WordRows = []string{"w1", "w2", "w2 again", "no id", "w3", "w4", "w5"}
+	doc.Data.Relations = []domain.WordsLink{{SourceId: "w1", TargetId: "w2"}, {SourceId: "w1", TargetId: "w3"}}
+	doc.RelationRows = []string{"edge 1", "edge 2"}
+	doc.Data.Links = []domain.Link{
+		{Word1Id: "w1", Word2Id: "w2", Content: long},
+		{Word1Id: "w1", Word2Id: "w3", Content: "causes"},
+	}
+	doc.LinkRows = []string{"edge 1", "edge 2"}
+	doc.Errors = []domain.ImportError{{Row: "line 9", Message: "unreadable"}}
+
+	data, errs := validateImport(doc)
+	if len(data.Words) != 2 || len(data.Relations) != 1 || len(data.Links) != 0 {
+		t.Fatalf("validateImport() = %+v", data)
+	}
+	rows := []string{}
+	for _, e := range errs {
+		rows = append(rows, e.Row+": "+e.Message)
+	}
+	want := []string{
+		"line 9: unreadable",
+		"w2 again: duplicated id w2",
+		"no id: missing id",
+		"w3: invalid content max=50",
+		"w4: invalid description max=512",
+		"w5: invalid refs[0] required",
+		// an edge is reported once for its relationship and its link
+		"edge 2: unknown word w1 or w3",
+		"edge 1: invalid content max=50",
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Fatalf("validateImport() errors =\n%s\nwant\n%s", strings.Join(rows, "\n"), strings.Join(want, "\n"))
+	}
+}
@@ -84,7 +84,7 @@ func (h *LinkHandler) UpdateLink(c *gin.Context) {
 		return
 	}
 
-	h.linkUsecase.Update(
+	err := h.linkUsecase.Update(
 		c,
 		id,
 		domain.Link{
@@ -94,6 +94,12 @@ func (h *LinkHandler) UpdateLink(c *gin.Context) {
 		},
 		authCommon.ExtractUser(c),
 	)
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
 }
 
 func (h *LinkHandler) GetDetail(c *gin.Context) {
@@ -106,7 +112,7 @@ func (h *LinkHandler) GetDetail(c *gin.Context) {
 	if path2 == "" {
 		var id = path1
 
-		r, err = h.linkUsecase.GetDetail(id)
+		r, err = h.linkUsecase.GetDetail(c, id, authCommon.ExtractUser(c))
 		if err != nil {
 			httpCommon.ErrorResponse(c, err)
 			return
@@ -114,7 +120,7 @@ func (h *LinkHandler) GetDetail(c *gin.Context) {
 
 	} else {
 
-		r, err = h.linkUsecase.GetDetailByWordIds(path1, path2)
+		r, err = h.linkUsecase.GetDetailByWordIds(c, path1, path2, authCommon.ExtractUser(c))
 		if err != nil {
 			httpCommon.ErrorResponse(c, err)
 			return
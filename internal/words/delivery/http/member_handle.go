@@ -0,0 +1,137 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type MemberHandler struct {
+	memberUsecase domain.MemberUsecase
+}
+
+func InitMemberHandlers(mus domain.MemberUsecase) *MemberHandler {
+	return &MemberHandler{
+		memberUsecase: mus,
+	}
+}
+
+func (h *MemberHandler) List(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.memberUsecase.List(c, id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *MemberHandler) ListInvitations(c *gin.Context) {
+	res, err := h.memberUsecase.ListInvitations(c, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *MemberHandler) Invite(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	var schema MemberInviteRequestSchema
+	// bind request context to data struct
+	if err := c.Bind(&schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	if err := validator.New().Struct(schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	role := domain.GraphRoleMap[schema.Role]
+	memberId, err := h.memberUsecase.Invite(c, id, schema.Email, role, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":      memberId,
+		"graphId": id,
+		"email":   schema.Email,
+		"role":    role,
+		"status":  domain.MemberStatusPending,
+	})
+}
+
+func (h *MemberHandler) Accept(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	if err := h.memberUsecase.Accept(c, id, authCommon.ExtractUser(c)); err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *MemberHandler) ChangeRole(c *gin.Context) {
+	id := c.Param("id")
+	memberId := c.Param("memberId")
+	if id == "" || memberId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	var schema MemberUpdateRequestSchema
+	// bind request context to data struct
+	if err := c.Bind(&schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	if err := validator.New().Struct(schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.memberUsecase.ChangeRole(c, id, memberId, domain.GraphRoleMap[schema.Role], authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *MemberHandler) Remove(c *gin.Context) {
+	id := c.Param("id")
+	memberId := c.Param("memberId")
+	if id == "" || memberId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	if err := h.memberUsecase.Remove(c, id, memberId, authCommon.ExtractUser(c)); err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type RevisionHandler struct {
+	revisionUsecase domain.RevisionUsecase
+}
+
+func InitRevisionHandlers(rus domain.RevisionUsecase) *RevisionHandler {
+	return &RevisionHandler{
+		revisionUsecase: rus,
+	}
+}
+
+// Get the id of the word or link of the route,
+// the link routes share their first parameter with the link details
+func entityId(c *gin.Context) string {
+	if id := c.Param("id"); id != "" {
+		return id
+	}
+	return c.Param("path1")
+}
+
+func (h *RevisionHandler) list(c *gin.Context, entityType domain.RevisionEntity) {
+	id := entityId(c)
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.revisionUsecase.List(c, entityType, id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// Compare the revisions given by the from and to query,
+// the current values are compared when to is missing
+func (h *RevisionHandler) diff(c *gin.Context, entityType domain.RevisionEntity) {
+	id := entityId(c)
+	from := c.Query("from")
+	to := c.DefaultQuery("to", domain.RevisionCurrent)
+	if id == "" || from == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.revisionUsecase.Diff(c, entityType, id, from, to, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *RevisionHandler) revert(c *gin.Context, entityType domain.RevisionEntity) {
+	id := entityId(c)
+	revisionId := c.Param("revisionId")
+	if id == "" || revisionId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.revisionUsecase.Revert(c, entityType, id, revisionId, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *RevisionHandler) ListWord(c *gin.Context) {
+	h.list(c, domain.RevisionEntityWord)
+}
+
+func (h *RevisionHandler) DiffWord(c *gin.Context) {
+	h.diff(c, domain.RevisionEntityWord)
+}
+
+func (h *RevisionHandler) RevertWord(c *gin.Context) {
+	h.revert(c, domain.RevisionEntityWord)
+}
+
+func (h *RevisionHandler) ListLink(c *gin.Context) {
+	h.list(c, domain.RevisionEntityLink)
+}
+
+func (h *RevisionHandler) DiffLink(c *gin.Context) {
+	h.diff(c, domain.RevisionEntityLink)
+}
+
+func (h *RevisionHandler) RevertLink(c *gin.Context) {
+	h.revert(c, domain.RevisionEntityLink)
+}
@@ -1,5 +1,7 @@
 package http
 
+import "time"
+
 type WordCreateRequestSchema struct {
 	Content     string    `json:"content" validate:"required,max=50"`
 	Description *string   `json:"description" validate:"omitempty,max=512"`
@@ -39,10 +41,31 @@ type LinkRemoveRequestSchema struct {
 }
 
 type GraphCreateRequestSchema struct {
-	Name string `json:"name" validate:"required,max=128"`
+	Name       string `json:"name" validate:"required,max=128"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
 }
 
 type GraphUpdateRequestSchema struct {
+	Name       string `json:"name" validate:"max=128"`
+	Type       string `json:"type" validate:"max=20"`
+	Visibility string `json:"visibility" validate:"omitempty,oneof=private unlisted public"`
+}
+
+type MemberInviteRequestSchema struct {
+	Email string `json:"email" validate:"required,email,max=256"`
+	Role  string `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+type MemberUpdateRequestSchema struct {
+	Role string `json:"role" validate:"required,oneof=viewer editor owner"`
+}
+
+type ShareCreateRequestSchema struct {
+	Permission string     `json:"permission" validate:"omitempty,oneof=read comment"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	Password   *string    `json:"password" validate:"omitempty,min=4,max=128"`
+}
+
+type GraphCloneRequestSchema struct {
 	Name string `json:"name" validate:"max=128"`
-	Type string `json:"type" validate:"max=20"`
 }
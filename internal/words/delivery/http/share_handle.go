@@ -0,0 +1,102 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type ShareHandler struct {
+	shareUsecase domain.ShareUsecase
+}
+
+func InitShareHandlers(sus domain.ShareUsecase) *ShareHandler {
+	return &ShareHandler{
+		shareUsecase: sus,
+	}
+}
+
+func (h *ShareHandler) List(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.shareUsecase.List(c, id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *ShareHandler) Create(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	var schema ShareCreateRequestSchema
+	// bind request context to data struct
+	if err := c.Bind(&schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	if err := validator.New().Struct(schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	permission := domain.SharePermissionRead
+	if schema.Permission != "" {
+		permission = domain.SharePermissionMap[schema.Permission]
+	}
+	share, token, err := h.shareUsecase.Create(
+		c,
+		id,
+		domain.ShareLink{
+			Permission: permission,
+			ExpiresAt:  schema.ExpiresAt,
+		},
+		schema.Password,
+		authCommon.ExtractUser(c),
+	)
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"id":          share.Id,
+		"graphId":     share.GraphId,
+		"permission":  share.Permission,
+		"expiresAt":   share.ExpiresAt,
+		"hasPassword": share.PasswordHash != nil,
+		"createdBy":   share.CreatedBy,
+		"createdAt":   share.CreatedAt,
+		"token":       token,
+	})
+}
+
+func (h *ShareHandler) Revoke(c *gin.Context) {
+	id := c.Param("id")
+	shareId := c.Param("shareId")
+	if id == "" || shareId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	if err := h.shareUsecase.Revoke(c, id, shareId, authCommon.ExtractUser(c)); err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
@@ -0,0 +1,113 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	authCommon "github.com/s2dio-tech/mindgra-backend/common/auth"
+	httpCommon "github.com/s2dio-tech/mindgra-backend/common/http"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type TrashHandler struct {
+	trashUsecase domain.TrashUsecase
+}
+
+func InitTrashHandlers(tus domain.TrashUsecase) *TrashHandler {
+	return &TrashHandler{
+		trashUsecase: tus,
+	}
+}
+
+func (h *TrashHandler) List(c *gin.Context) {
+	graphId := c.Param("id")
+	if graphId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	res, err := h.trashUsecase.List(c, graphId, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+func (h *TrashHandler) RestoreWord(c *gin.Context) {
+	graphId, wordId := c.Param("id"), c.Param("wordId")
+	if graphId == "" || wordId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.trashUsecase.RestoreWord(c, graphId, wordId, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *TrashHandler) RestoreRelation(c *gin.Context) {
+	graphId, w1Id, w2Id := c.Param("id"), c.Param("word1Id"), c.Param("word2Id")
+	if graphId == "" || w1Id == "" || w2Id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.trashUsecase.RestoreRelation(c, graphId, w1Id, w2Id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *TrashHandler) PurgeWord(c *gin.Context) {
+	graphId, wordId := c.Param("id"), c.Param("wordId")
+	if graphId == "" || wordId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.trashUsecase.PurgeWord(c, graphId, wordId, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+func (h *TrashHandler) PurgeRelation(c *gin.Context) {
+	graphId, w1Id, w2Id := c.Param("id"), c.Param("word1Id"), c.Param("word2Id")
+	if graphId == "" || w1Id == "" || w2Id == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.trashUsecase.PurgeRelation(c, graphId, w1Id, w2Id, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// Purge everything in the trash of the graph
+func (h *TrashHandler) Empty(c *gin.Context) {
+	graphId := c.Param("id")
+	if graphId == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	err := h.trashUsecase.Empty(c, graphId, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
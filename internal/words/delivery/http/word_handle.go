@@ -2,6 +2,7 @@ package http
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
@@ -103,9 +104,9 @@ func (h *WordHandler) UpdateWord(c *gin.Context) {
 		Content:     schema.Content,
 		Description: schema.Description,
 		Refs:        schema.Refs,
-	})
+	}, authCommon.ExtractUser(c))
 	if err != nil {
-		httpCommon.ErrorResponse(c, common.ErrInternalServerError)
+		httpCommon.ErrorResponse(c, err)
 		return
 	}
 
@@ -119,7 +120,12 @@ func (h *WordHandler) DeleteWord(c *gin.Context) {
 		return
 	}
 
-	h.wordUsecase.Delete(c, id, authCommon.ExtractUser(c))
+	if err := h.wordUsecase.Delete(c, id, authCommon.ExtractUser(c)); err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{})
 }
 
 func (h *WordHandler) GetWordDetail(c *gin.Context) {
@@ -128,13 +134,9 @@ func (h *WordHandler) GetWordDetail(c *gin.Context) {
 		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
 		return
 	}
-	w, err := h.wordUsecase.GetWordById(c, id)
+	w, err := h.wordUsecase.GetWordById(c, id, authCommon.ExtractUser(c))
 	if err != nil {
-		httpCommon.ErrorResponse(c, common.ErrInternalServerError)
-		return
-	}
-	if w == nil {
-		httpCommon.ErrorResponse(c, common.ErrNotFound)
+		httpCommon.ErrorResponse(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, w)
@@ -147,7 +149,7 @@ func (h *WordHandler) GetGraphData(c *gin.Context) {
 		return
 	}
 
-	data, err := h.wordUsecase.GetGraphData(c, id)
+	data, err := h.wordUsecase.GetGraphData(c, id, authCommon.ExtractUser(c))
 	if err != nil {
 		httpCommon.ErrorResponse(c, err)
 		return
@@ -156,14 +158,109 @@ func (h *WordHandler) GetGraphData(c *gin.Context) {
 	c.JSON(http.StatusOK, data)
 }
 
+// largest page of the search results
+const maxSearchLimit = 100
+
 func (h *WordHandler) SearchWord(c *gin.Context) {
-	var text = c.Query("search")
-	var graphId = c.Query("graphId")
-	if graphId == "" || len(text) < 3 {
+	search := domain.WordSearch{
+		Text:    c.Query("search"),
+		GraphId: c.Query("graphId"),
+		Scope:   domain.SearchScope(c.DefaultQuery("scope", string(domain.SearchScopeAll))),
+		Cursor:  c.Query("cursor"),
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > maxSearchLimit {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	if len(search.Text) < 3 || (search.Scope != domain.SearchScopeAll && search.Scope != domain.SearchScopeMine) {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	search.Offset, search.Limit = offset, limit
+
+	data, err := h.wordUsecase.SearchWord(c, search, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// largest number of suggested words
+const maxSuggestLimit = 50
+
+func (h *WordHandler) Suggest(c *gin.Context) {
+	var id = c.Param("id")
+	var text = c.Query("q")
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 || limit > maxSuggestLimit || text == "" {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	data, err := h.wordUsecase.Suggest(c, id, text, limit, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+// largest number of similar words
+const maxSimilarLimit = 50
+
+// Read the limit of the similar words
+func similarLimit(c *gin.Context) (int, bool) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	return limit, err == nil && limit >= 1 && limit <= maxSimilarLimit
+}
+
+func (h *WordHandler) Similar(c *gin.Context) {
+	var id = c.Param("id")
+	limit, ok := similarLimit(c)
+	if !ok {
 		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
 		return
 	}
-	data, err := h.wordUsecase.SearchWord(c, text)
+
+	data, err := h.wordUsecase.Similar(c, id, limit, authCommon.ExtractUser(c))
+	if err != nil {
+		httpCommon.ErrorResponse(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, data)
+}
+
+func (h *WordHandler) SimilarToDraft(c *gin.Context) {
+	var id = c.Param("id")
+	limit, ok := similarLimit(c)
+	if !ok {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	var schema WordUpdateRequestSchema
+	if err := c.Bind(&schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+	v := validator.New()
+	if err := v.Struct(schema); err != nil {
+		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
+		return
+	}
+
+	draft := domain.Word{
+		Content:     schema.Content,
+		Description: schema.Description,
+		Refs:        schema.Refs,
+	}
+	data, err := h.wordUsecase.SimilarToDraft(c, id, draft, limit, authCommon.ExtractUser(c))
 	if err != nil {
 		httpCommon.ErrorResponse(c, err)
 		return
@@ -178,7 +275,7 @@ func (h *WordHandler) FindPath(c *gin.Context) {
 		httpCommon.ErrorResponse(c, common.ErrBadParamInput)
 		return
 	}
-	words, links, err := h.wordUsecase.FindPath(c, fromWordId, toWordId)
+	words, links, err := h.wordUsecase.FindPath(c, fromWordId, toWordId, authCommon.ExtractUser(c))
 	if err != nil {
 		httpCommon.ErrorResponse(c, err)
 		return
@@ -204,7 +301,7 @@ func (h *WordHandler) Link2Words(c *gin.Context) {
 		return
 	}
 
-	err := h.wordUsecase.Link2Words(c, schema.SourceId, schema.TargetId)
+	err := h.wordUsecase.Link2Words(c, schema.SourceId, schema.TargetId, authCommon.ExtractUser(c))
 	if err != nil {
 		httpCommon.ErrorResponse(c, err)
 		return
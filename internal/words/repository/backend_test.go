@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/datasource/datasourcetest"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// The repositories of a backend the tests run against,
+// with a user "u1" and an empty graph of theirs
+type backend struct {
+	name     string
+	graphId  string
+	graph    domain.GraphRepository
+	word     domain.WordRepository
+	link     domain.LinkRepository
+	trash    domain.TrashRepository
+	revision domain.RevisionRepository
+}
+
+func memoryBackend(t *testing.T) *backend {
+	db := datasource.InitMemory()
+	db.Users["u1"] = &domain.User{Id: "u1"}
+	b := &backend{
+		name:     "memory",
+		graph:    InitGraphMemoryRepository(db),
+		word:     InitWordMemoryRepository(db),
+		link:     InitLinkMemoryRepository(db),
+		trash:    InitTrashMemoryRepository(db),
+		revision: InitRevisionMemoryRepository(db),
+	}
+	b.storeGraph(t)
+	return b
+}
+
+func sqliteBackend(t *testing.T) *backend {
+	db := datasourcetest.NewSQLite(t)
+	_, err := db.ExecWrite(
+		context.Background(),
+		`INSERT INTO users (id, name, email, password, role, created_at) VALUES ('u1', 'u1', 'u1@example.com', '', 'user', ?);`,
+		time.Now(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &backend{
+		name:     "sqlite",
+		graph:    InitGraphSQLiteRepository(db),
+		word:     InitWordSQLiteRepository(db),
+		link:     InitLinkSQLiteRepository(db),
+		trash:    InitTrashSQLiteRepository(db),
+		revision: InitRevisionSQLiteRepository(db),
+	}
+	b.storeGraph(t)
+	return b
+}
+
+// Run the test on the memory and the sqlite backends
+func eachBackend(t *testing.T, test func(t *testing.T, b *backend)) {
+	for _, open := range []func(t *testing.T) *backend{memoryBackend, sqliteBackend} {
+		b := open(t)
+		t.Run(b.name, func(t *testing.T) {
+			test(t, b)
+		})
+	}
+}
+
+func (b *backend) storeGraph(t *testing.T) {
+	id, err := b.graph.Store(context.Background(), domain.Graph{
+		UserId:     "u1",
+		Name:       "test",
+		Visibility: domain.GraphVisibilityPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.graphId = *id
+}
+
+// Store a word of the graph, related to linkWordId when given
+func (b *backend) storeWord(t *testing.T, content string, linkWordId *string) string {
+	id, err := b.word.Store(context.Background(), domain.Word{UserId: "u1", Content: content, CreatedAt: common.ToPointer(time.Now())}, b.graphId, linkWordId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *id
+}
+
+// Store the link of the related words
+func (b *backend) storeLink(t *testing.T, w1Id string, w2Id string, content string) string {
+	id, err := b.link.Store(context.Background(), domain.Link{
+		UserId:    "u1",
+		Word1Id:   w1Id,
+		Word2Id:   w2Id,
+		Content:   content,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *id
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
+	"golang.org/x/exp/slog"
+)
+
+var cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "mindgra_cache_requests_total",
+	Help: "Cache lookups by cache and result, hit or miss.",
+}, []string{"cache", "result"})
+
+// names of the cached values, in the keys and the metrics labels
+const (
+	cacheGraph          = "graph"
+	cacheGraphData      = "graph_data"
+	cacheWordIndex      = "word_index"
+	cacheWordSimilarity = "word_similarity"
+)
+
+// GraphDataCache keeps the graphs and their words and links
+// in front of the repositories, until the usecases invalidate them
+type GraphDataCache struct {
+	cache cache.Cache
+	// bumped by every invalidation, so a value read from the database
+	// before it is not stored after it
+	mu         sync.Mutex
+	generation uint64
+	// told about the graphs whose data changed, such as the word index
+	listeners []func(graphIds []string)
+}
+
+func InitGraphDataCache(c cache.Cache) *GraphDataCache {
+	return &GraphDataCache{
+		cache: c,
+	}
+}
+
+func (g *GraphDataCache) InvalidateGraph(ctx context.Context, graphIds ...string) {
+	g.invalidate(ctx, []string{cacheGraph, cacheGraphData}, graphIds)
+}
+
+func (g *GraphDataCache) InvalidateGraphData(ctx context.Context, graphIds ...string) {
+	g.invalidate(ctx, []string{cacheGraphData}, graphIds)
+}
+
+func (g *GraphDataCache) invalidate(ctx context.Context, names []string, graphIds []string) {
+	keys := []string{}
+	for _, id := range graphIds {
+		if id == "" {
+			continue
+		}
+		for _, name := range names {
+			keys = append(keys, cacheKey(name, id))
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.generation++
+	g.cache.Delete(ctx, keys...)
+	for _, l := range g.listeners {
+		l(graphIds)
+	}
+}
+
+// Call l with the graphs whose data is invalidated, under the lock of the cache
+func (g *GraphDataCache) listen(l func(graphIds []string)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.listeners = append(g.listeners, l)
+}
+
+func (g *GraphDataCache) currentGeneration() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generation
+}
+
+// Run store unless the graphs were invalidated since generation was read
+func (g *GraphDataCache) ifCurrent(generation uint64, store func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.generation == generation {
+		store()
+	}
+}
+
+func cacheKey(name string, id string) string {
+	return name + ":" + id
+}
+
+// Decode the cached value of id into v, it returns the generation
+// to give back to set when the value is missing
+func (g *GraphDataCache) get(ctx context.Context, name string, id string, v any) (uint64, bool) {
+	g.mu.Lock()
+	generation := g.generation
+	g.mu.Unlock()
+
+	data, ok := g.cache.Get(ctx, cacheKey(name, id))
+	if ok && json.Unmarshal(data, v) == nil {
+		cacheRequests.WithLabelValues(name, "hit").Inc()
+		return generation, true
+	}
+	cacheRequests.WithLabelValues(name, "miss").Inc()
+	return generation, false
+}
+
+// Store v unless the graphs were invalidated since generation was read
+func (g *GraphDataCache) set(ctx context.Context, name string, id string, generation uint64, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		slog.Error("Encode cached value error", err)
+		return
+	}
+
+	g.ifCurrent(generation, func() {
+		g.cache.Set(ctx, cacheKey(name, id), data)
+	})
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// graphCachedRepository reads a single graph through the cache,
+// the other calls go to the wrapped repository
+type graphCachedRepository struct {
+	domain.GraphRepository
+	cache *GraphDataCache
+}
+
+func InitGraphCachedRepository(repo domain.GraphRepository, cache *GraphDataCache) domain.GraphRepository {
+	return &graphCachedRepository{
+		GraphRepository: repo,
+		cache:           cache,
+	}
+}
+
+func (r *graphCachedRepository) SelectOne(ctx context.Context, id string) (*domain.Graph, error) {
+	graph := domain.Graph{}
+	generation, ok := r.cache.get(ctx, cacheGraph, id, &graph)
+	if ok {
+		return &graph, nil
+	}
+
+	res, err := r.GraphRepository.SelectOne(ctx, id)
+	if err != nil || res == nil {
+		return res, err
+	}
+	r.cache.set(ctx, cacheGraph, id, generation, res)
+	return res, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// graphIndexes keeps an index built from the words and links of each
+// recently used graph in memory, dropped with the cached data of the graph
+type graphIndexes[T any] struct {
+	name  string
+	repo  domain.WordRepository
+	cache *GraphDataCache
+	build func(words []domain.Word, links []domain.WordsLink) T
+	// nil when the indexes are built on every call
+	graphs *lru.Cache[string, T]
+}
+
+// size is the number of graphs kept, 0 builds the index on every call
+func initGraphIndexes[T any](
+	name string,
+	repo domain.WordRepository,
+	cache *GraphDataCache,
+	size int,
+	build func(words []domain.Word, links []domain.WordsLink) T,
+) *graphIndexes[T] {
+	indexes := &graphIndexes[T]{
+		name:  name,
+		repo:  repo,
+		cache: cache,
+		build: build,
+	}
+	if size > 0 {
+		graphs, err := lru.New[string, T](size)
+		if err != nil {
+			panic(err)
+		}
+		indexes.graphs = graphs
+		cache.listen(func(graphIds []string) {
+			for _, id := range graphIds {
+				graphs.Remove(id)
+			}
+		})
+	}
+	return indexes
+}
+
+func (g *graphIndexes[T]) get(ctx context.Context, graphId string) (index T, err error) {
+	if g.graphs == nil {
+		words, links, err := g.repo.FindByGraphId(ctx, graphId)
+		if err != nil {
+			return index, err
+		}
+		return g.build(words, links), nil
+	}
+
+	if index, ok := g.graphs.Get(graphId); ok {
+		cacheRequests.WithLabelValues(g.name, "hit").Inc()
+		return index, nil
+	}
+	cacheRequests.WithLabelValues(g.name, "miss").Inc()
+	generation := g.cache.currentGeneration()
+	words, links, err := g.repo.FindByGraphId(ctx, graphId)
+	if err != nil {
+		return index, err
+	}
+	index = g.build(words, links)
+	g.cache.ifCurrent(generation, func() {
+		g.graphs.Add(graphId, index)
+	})
+	return index, nil
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type graphMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitGraphMemoryRepository(db *datasource.Memory) domain.GraphRepository {
+	return &graphMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func (repo *graphMemoryRepository) Select(ctx context.Context, userId string) ([]domain.Graph, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	graphs := []domain.Graph{}
+	if db.Users[userId] == nil {
+		return graphs, nil
+	}
+	// owned graphs and the ones shared with the user
+	shared := map[string]bool{}
+	for _, m := range db.Members {
+		if m.UserId != nil && *m.UserId == userId && m.Status == domain.MemberStatusAccepted {
+			shared[m.GraphId] = true
+		}
+	}
+	for _, g := range db.Graphs {
+		if (g.UserId == userId || shared[g.Id]) && !g.DeleteFlag {
+			graphs = append(graphs, g.Graph)
+		}
+	}
+	sort.SliceStable(graphs, func(i, j int) bool {
+		return graphs[i].CreatedAt.Before(*graphs[j].CreatedAt)
+	})
+	return graphs, nil
+}
+
+func (repo *graphMemoryRepository) Store(ctx context.Context, w domain.Graph) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	return repo.store(w)
+}
+
+// the write lock is held by the caller
+func (repo *graphMemoryRepository) store(w domain.Graph) (*string, error) {
+	db := repo.Datasource
+	if db.Users[w.UserId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	graph := domain.Graph{
+		Id:         common.NewId(),
+		UserId:     w.UserId,
+		Name:       w.Name,
+		Type:       "3d",
+		Visibility: w.Visibility,
+		OriginId:   w.OriginId,
+		CreatedAt:  common.ToPointer(time.Now()),
+	}
+	db.Graphs[graph.Id] = &datasource.MemoryGraph{Graph: graph}
+	return &graph.Id, nil
+}
+
+func (repo *graphMemoryRepository) Update(ctx context.Context, id string, s domain.Graph) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	g := db.Graphs[id]
+	if g == nil {
+		return nil
+	}
+	g.UpdatedAt = common.ToPointer(time.Now())
+	if s.Name != "" {
+		g.Name = s.Name
+	}
+	if s.Type != "" {
+		g.Type = s.Type
+	}
+	if s.Visibility != "" {
+		g.Visibility = s.Visibility
+	}
+	return nil
+}
+
+func (repo *graphMemoryRepository) Delete(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if g := db.Graphs[id]; g != nil {
+		g.DeleteFlag = true
+		g.DeletedAt = common.ToPointer(time.Now())
+	}
+	return nil
+}
+
+func (repo *graphMemoryRepository) SelectDeleted(ctx context.Context, userId string) ([]domain.Graph, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	owned := map[string]bool{}
+	for _, m := range db.Members {
+		if m.UserId != nil && *m.UserId == userId && m.Role == domain.GraphRoleOwner && m.Status == domain.MemberStatusAccepted {
+			owned[m.GraphId] = true
+		}
+	}
+	graphs := []domain.Graph{}
+	for _, g := range db.Graphs {
+		if (g.UserId == userId || owned[g.Id]) && g.DeleteFlag {
+			graphs = append(graphs, g.Graph)
+		}
+	}
+	sort.SliceStable(graphs, func(i, j int) bool {
+		return graphs[i].DeletedAt.After(*graphs[j].DeletedAt)
+	})
+	return graphs, nil
+}
+
+func (repo *graphMemoryRepository) SelectDeletedOne(ctx context.Context, id string) (*domain.Graph, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	g := db.Graphs[id]
+	if g == nil || !g.DeleteFlag {
+		return nil, nil
+	}
+	return common.ToPointer(g.Graph), nil
+}
+
+func (repo *graphMemoryRepository) Restore(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if g := db.Graphs[id]; g != nil {
+		g.DeleteFlag = false
+		g.DeletedAt = nil
+	}
+	return nil
+}
+
+func (repo *graphMemoryRepository) SelectDeletedBefore(ctx context.Context, before time.Time) ([]domain.Graph, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	graphs := []domain.Graph{}
+	for _, g := range db.Graphs {
+		if g.DeleteFlag && g.DeletedAt != nil && g.DeletedAt.Before(before) {
+			graphs = append(graphs, g.Graph)
+		}
+	}
+	return graphs, nil
+}
+
+func (repo *graphMemoryRepository) Purge(ctx context.Context, id string) (*domain.PurgeReport, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	report := &domain.PurgeReport{}
+	// a graph restored meanwhile is kept
+	if g := db.Graphs[id]; g == nil || !g.DeleteFlag {
+		return report, nil
+	}
+
+	wordIds := map[string]bool{}
+	for wId, w := range db.Words {
+		if w.GraphId == id {
+			wordIds[wId] = true
+			delete(db.Words, wId)
+		}
+	}
+	for wId, w := range db.TrashedWords {
+		if w.GraphId == id {
+			wordIds[wId] = true
+			delete(db.TrashedWords, wId)
+		}
+	}
+	report.Words = len(wordIds)
+
+	entityIds := map[string]bool{}
+	for wId := range wordIds {
+		entityIds[wId] = true
+	}
+	for _, links := range []map[string]*domain.Link{db.Links, db.TrashedLinks} {
+		for lId, l := range links {
+			if wordIds[l.Word1Id] || wordIds[l.Word2Id] {
+				entityIds[lId] = true
+				delete(links, lId)
+				report.Links++
+			}
+		}
+	}
+	for rId, r := range db.Revisions {
+		if entityIds[r.EntityId] {
+			delete(db.Revisions, rId)
+			report.Revisions++
+		}
+	}
+
+	rels := []*datasource.MemoryRelation{}
+	for _, r := range db.Relations {
+		if !wordIds[r.StartId] && !wordIds[r.EndId] {
+			rels = append(rels, r)
+		}
+	}
+	db.Relations = rels
+	trashed := []*datasource.MemoryTrashedRelation{}
+	for _, r := range db.TrashedRelations {
+		if !wordIds[r.StartId] && !wordIds[r.EndId] {
+			trashed = append(trashed, r)
+		}
+	}
+	db.TrashedRelations = trashed
+
+	for mId, m := range db.Members {
+		if m.GraphId == id {
+			delete(db.Members, mId)
+		}
+	}
+	for sId, s := range db.Shares {
+		if s.GraphId == id {
+			delete(db.Shares, sId)
+		}
+	}
+	delete(db.Graphs, id)
+	report.Graphs = 1
+	return report, nil
+}
+
+func (repo *graphMemoryRepository) SelectOne(ctx context.Context, id string) (*domain.Graph, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	g := db.Graphs[id]
+	if g == nil || g.DeleteFlag {
+		return nil, nil
+	}
+	return common.ToPointer(g.Graph), nil
+}
+
+func (repo *graphMemoryRepository) Clone(ctx context.Context, id string, graph domain.Graph) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	graphId, err := repo.store(graph)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	wordIds := map[string]string{}
+	for _, w := range db.Words {
+		if w.GraphId != id {
+			continue
+		}
+		word := copyWord(w)
+		word.Id = common.NewId()
+		word.GraphId = *graphId
+		word.UserId = graph.UserId
+		word.CreatedAt = common.ToPointer(now)
+		word.UpdatedAt = nil
+		wordIds[w.Id] = word.Id
+		db.Words[word.Id] = &word
+	}
+
+	linkIds := map[string]string{}
+	for _, r := range append([]*datasource.MemoryRelation{}, db.Relations...) {
+		startId, endId := wordIds[r.StartId], wordIds[r.EndId]
+		if startId == "" || endId == "" {
+			continue
+		}
+		relation := &datasource.MemoryRelation{StartId: startId, EndId: endId}
+		if r.Id != nil && db.Links[*r.Id] != nil {
+			if linkIds[*r.Id] == "" {
+				link := copyLink(db.Links[*r.Id])
+				link.Id = common.NewId()
+				link.UserId = graph.UserId
+				link.Word1Id = wordIds[link.Word1Id]
+				link.Word2Id = wordIds[link.Word2Id]
+				link.CreatedAt = now
+				link.UpdatedAt = nil
+				linkIds[*r.Id] = link.Id
+				db.Links[link.Id] = &link
+			}
+			relation.Id = common.ToPointer(linkIds[*r.Id])
+		}
+		db.Relations = append(db.Relations, relation)
+	}
+	return graphId, nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -10,10 +11,10 @@ import (
 )
 
 type graphRepository struct {
-	Datasource *datasource.Neo4J
+	Datasource datasource.Datasource
 }
 
-func InitGraphRepository(db *datasource.Neo4J) domain.GraphRepository {
+func InitGraphRepository(db datasource.Datasource) domain.GraphRepository {
 	return &graphRepository{
 		Datasource: db,
 	}
@@ -26,18 +27,32 @@ func recordToGraph(record map[string]any) *domain.Graph {
 		UserId: record["userId"].(string),
 		Type:   record["type"].(string),
 	}
+	w.OriginId = common.Nullable{Value: record["originId"]}.ToStringPtr()
+	// the graphs created before the visibility setting are private
+	w.Visibility = domain.GraphVisibility(common.Nullable{Value: record["visibility"]}.ToString())
+	if w.Visibility == "" {
+		w.Visibility = domain.GraphVisibilityPrivate
+	}
 	if record["createdAt"] != nil {
 		w.CreatedAt = common.ToPointer(record["createdAt"].(neo4j.LocalDateTime).Time())
 	}
+	if record["deletedAt"] != nil {
+		w.DeletedAt = common.ToPointer(record["deletedAt"].(neo4j.LocalDateTime).Time())
+	}
 	return &w
 }
 
-func (repo *graphRepository) Select(userId string) ([]domain.Graph, error) {
-	res, err := repo.Datasource.ExecRead(`
+func (repo *graphRepository) Select(ctx context.Context, userId string) ([]domain.Graph, error) {
+	// owned graphs and the ones shared with the user
+	res, err := repo.Datasource.ExecRead(ctx, `// graph.Select
 		MATCH (u:User {id: $userId})-[:OWN]->(s:Graph {deleteFlag: false})
-		RETURN s.id AS id, s.name AS name, s.userId as userId, s.type as type, s.createdAt as createdAt;`,
+		RETURN s.id AS id, s.name AS name, s.userId as userId, s.type as type, s.visibility as visibility, s.originId as originId, s.createdAt as createdAt
+		UNION
+		MATCH (s:Graph {deleteFlag: false})-[:MEMBER]->(:GraphMember {userId: $userId, status: $status})
+		RETURN s.id AS id, s.name AS name, s.userId as userId, s.type as type, s.visibility as visibility, s.originId as originId, s.createdAt as createdAt;`,
 		map[string]interface{}{
 			"userId": userId,
+			"status": string(domain.MemberStatusAccepted),
 		},
 	)
 
@@ -52,25 +67,31 @@ func (repo *graphRepository) Select(userId string) ([]domain.Graph, error) {
 	return graphs, nil
 }
 
-func (repo *graphRepository) Store(w domain.Graph) (*string, error) {
-	query := `MATCH (u:User {id: $userId})
+func (repo *graphRepository) Store(ctx context.Context, w domain.Graph) (*string, error) {
+	query := `// graph.Store
+		MATCH (u:User {id: $userId})
 		CREATE (w:Graph {
-			id: apoc.create.uuid(),
+			id: $id,
 			userId: $userId,
 			name: $name,
 			type: "3d",
+			visibility: $visibility,
+			originId: $originId,
 			createdAt: $createdAt,
 			deleteFlag: false
 		})
 		CREATE (u)-[:OWN]->(w)
 		RETURN w.id AS id;`
 	params := map[string]interface{}{
-		"userId":    w.UserId,
-		"name":      w.Name,
-		"createdAt": neo4j.LocalDateTimeOf(time.Now()),
+		"id":         common.NewId(),
+		"userId":     w.UserId,
+		"name":       w.Name,
+		"visibility": string(w.Visibility),
+		"originId":   w.OriginId,
+		"createdAt":  neo4j.LocalDateTimeOf(time.Now()),
 	}
 
-	result, err := repo.Datasource.ExecWrite(query, params)
+	result, err := repo.Datasource.ExecWrite(ctx, query, params)
 
 	if err != nil {
 		return nil, err
@@ -88,7 +109,7 @@ func (repo *graphRepository) Store(w domain.Graph) (*string, error) {
 	return common.Nullable{Value: _id}.ToStringPtr(), nil
 }
 
-func (repo *graphRepository) Update(id string, s domain.Graph) error {
+func (repo *graphRepository) Update(ctx context.Context, id string, s domain.Graph) error {
 	_set := "w.updatedAt= $updatedAt"
 	params := map[string]interface{}{
 		"id":        id,
@@ -102,17 +123,101 @@ func (repo *graphRepository) Update(id string, s domain.Graph) error {
 		_set += ", w.type= $type"
 		params["type"] = s.Type
 	}
-	query := `MATCH (w:Graph {id: $id}) SET ` + _set
+	if s.Visibility != "" {
+		_set += ", w.visibility= $visibility"
+		params["visibility"] = string(s.Visibility)
+	}
+	query := `// graph.Update
+		MATCH (w:Graph {id: $id}) SET ` + _set
 
-	_, err := repo.Datasource.ExecWrite(query, params)
+	_, err := repo.Datasource.ExecWrite(ctx, query, params)
 	return err
 }
 
-func (r *graphRepository) Delete(id string) error {
+func (r *graphRepository) Delete(ctx context.Context, id string) error {
 	// remove graph and links
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (s:Graph {id: $id})
-			SET s.deleteFlag = true;`,
+		ctx,
+		`// graph.Delete
+			MATCH (s:Graph {id: $id})
+			SET s.deleteFlag = true, s.deletedAt = $deletedAt;`,
+		map[string]interface{}{
+			"id":        id,
+			"deletedAt": neo4j.LocalDateTimeOf(time.Now()),
+		},
+	)
+	return err
+}
+
+const deletedGraphReturn = `RETURN g.id as id,
+				g.userId AS userId,
+				g.name AS name,
+				g.type AS type,
+				g.visibility AS visibility,
+				g.originId AS originId,
+				g.createdAt AS createdAt,
+				g.deletedAt AS deletedAt`
+
+func (r *graphRepository) selectDeleted(ctx context.Context, query string, params map[string]any) ([]domain.Graph, error) {
+	result, err := r.Datasource.ExecRead(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	graphs := []domain.Graph{}
+	for _, record := range result {
+		graphs = append(graphs, *recordToGraph(record.AsMap()))
+	}
+	return graphs, nil
+}
+
+func (r *graphRepository) SelectDeleted(ctx context.Context, userId string) ([]domain.Graph, error) {
+	return r.selectDeleted(ctx, `// graph.SelectDeleted
+			MATCH (g:Graph {deleteFlag: true})
+			WHERE g.userId = $userId
+				OR (g)-[:MEMBER]->(:GraphMember {userId: $userId, role: $role, status: $status})
+			`+deletedGraphReturn+`
+			ORDER BY g.deletedAt DESC;`,
+		map[string]interface{}{
+			"userId": userId,
+			"role":   string(domain.GraphRoleOwner),
+			"status": string(domain.MemberStatusAccepted),
+		},
+	)
+}
+
+func (r *graphRepository) SelectDeletedOne(ctx context.Context, id string) (*domain.Graph, error) {
+	graphs, err := r.selectDeleted(ctx, `// graph.SelectDeletedOne
+			MATCH (g:Graph {id: $id, deleteFlag: true})
+			`+deletedGraphReturn+`;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	if err != nil || len(graphs) == 0 {
+		return nil, err
+	}
+	return &graphs[0], nil
+}
+
+func (r *graphRepository) SelectDeletedBefore(ctx context.Context, before time.Time) ([]domain.Graph, error) {
+	return r.selectDeleted(ctx, `// graph.SelectDeletedBefore
+			MATCH (g:Graph {deleteFlag: true})
+			WHERE g.deletedAt < $before
+			`+deletedGraphReturn+`;`,
+		map[string]interface{}{
+			"before": neo4j.LocalDateTimeOf(before),
+		},
+	)
+}
+
+func (r *graphRepository) Restore(ctx context.Context, id string) error {
+	_, err := r.Datasource.ExecWrite(
+		ctx,
+		`// graph.Restore
+			MATCH (s:Graph {id: $id})
+			SET s.deleteFlag = false
+			REMOVE s.deletedAt;`,
 		map[string]interface{}{
 			"id": id,
 		},
@@ -120,13 +225,72 @@ func (r *graphRepository) Delete(id string) error {
 	return err
 }
 
-func (r *graphRepository) SelectOne(id string) (*domain.Graph, error) {
+func (r *graphRepository) Purge(ctx context.Context, id string) (*domain.PurgeReport, error) {
+	report := &domain.PurgeReport{}
+	params := map[string]interface{}{
+		"id": id,
+	}
+	err := r.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		// a graph restored meanwhile is kept, every step matches the deleted graph only
+		for _, step := range []struct {
+			query string
+			count *int
+		}{
+			{`// graph.Purge.revisions
+				MATCH (:Graph {id: $id, deleteFlag: true})-[:WORD]->(w)
+			WITH collect(w.id) AS ids
+			OPTIONAL MATCH (l) WHERE (l:Link OR l:TrashedLink) AND (l.word1Id IN ids OR l.word2Id IN ids)
+			WITH ids + collect(l.id) AS ids
+			MATCH (v:Revision) WHERE v.entityId IN ids
+			DETACH DELETE v
+			RETURN count(v) AS count;`, &report.Revisions},
+			{`// graph.Purge.links
+				MATCH (:Graph {id: $id, deleteFlag: true})-[:WORD]->(w)
+			WITH collect(w.id) AS ids
+			MATCH (l) WHERE (l:Link OR l:TrashedLink) AND (l.word1Id IN ids OR l.word2Id IN ids)
+			DETACH DELETE l
+			RETURN count(l) AS count;`, &report.Links},
+			// the relationships of the words, in the trash or not, go with them
+			{`// graph.Purge.words
+				MATCH (:Graph {id: $id, deleteFlag: true})-[:WORD]->(w)
+			DETACH DELETE w
+			RETURN count(w) AS count;`, &report.Words},
+			{`// graph.Purge
+				MATCH (g:Graph {id: $id, deleteFlag: true})
+			OPTIONAL MATCH (g)-[:MEMBER|SHARE]->(m)
+			WITH g, collect(m) AS ms
+			FOREACH (m IN ms | DETACH DELETE m)
+			DETACH DELETE g
+			RETURN count(g) AS count;`, &report.Graphs},
+		} {
+			result, err := r.Datasource.ExecWrite(ctx, step.query, params)
+			if err != nil {
+				return err
+			}
+			if len(result) > 0 {
+				count, _ := result[0].Get("count")
+				*step.count += int(count.(int64))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *graphRepository) SelectOne(ctx context.Context, id string) (*domain.Graph, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH (g:Graph {id: $id, deleteFlag: false})
+		ctx,
+		`// graph.SelectOne
+			MATCH (g:Graph {id: $id, deleteFlag: false})
 			RETURN g.id as id,
 				g.userId AS userId,
 				g.name AS name,
 				g.type AS type,
+				g.visibility AS visibility,
+				g.originId AS originId,
 				g.createdAt AS createdAt;`,
 		map[string]interface{}{
 			"id": id,
@@ -146,3 +310,152 @@ func (r *graphRepository) SelectOne(id string) (*domain.Graph, error) {
 
 	return recordToGraph(record.AsMap()), nil
 }
+
+func (repo *graphRepository) Clone(ctx context.Context, id string, graph domain.Graph) (*string, error) {
+	graphId, err := repo.Store(ctx, graph)
+	if err != nil {
+		return nil, err
+	}
+	createdAt := neo4j.LocalDateTimeOf(time.Now())
+
+	// fresh ids of the words
+	result, err := repo.Datasource.ExecRead(
+		ctx,
+		`// graph.Clone.words
+			MATCH (:Graph {id: $id})-[:WORD]->(w:Word)
+			RETURN w.id AS id;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	wordIds := map[string]string{}
+	words := []map[string]any{}
+	for _, record := range result {
+		_id, _ := record.Get("id")
+		wordIds[_id.(string)] = common.NewId()
+		words = append(words, map[string]any{"from": _id, "to": wordIds[_id.(string)]})
+	}
+	if len(words) == 0 {
+		return graphId, nil
+	}
+
+	_, err = repo.Datasource.ExecWrite(
+		ctx,
+		`// graph.Clone.storeWords
+			MATCH (u:User {id: $userId})
+		MATCH (g:Graph {id: $graphId})
+		UNWIND $words AS row
+		MATCH (w:Word {id: row.from})
+		CREATE (c:Word {
+			id: row.to,
+			userId: $userId,
+			graphId: $graphId,
+			content: w.content,
+			description: w.description,
+			refs: w.refs,
+			createdAt: $createdAt
+		})
+		CREATE (u)-[:OWN]->(c)
+		CREATE (g)-[:WORD]->(c);`,
+		map[string]interface{}{
+			"userId":    graph.UserId,
+			"graphId":   *graphId,
+			"words":     words,
+			"createdAt": createdAt,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// the relationships between the words of the graph and their links
+	result, err = repo.Datasource.ExecRead(
+		ctx,
+		`// graph.Clone.relations
+			MATCH (:Graph {id: $id})-[:WORD]->(w1:Word)-[r:CONCERN]->(w2:Word)
+		OPTIONAL MATCH (l:Link {id: r.id})
+		RETURN w1.id AS startId, w2.id AS endId, l.id AS linkId, l.word1Id AS word1Id, l.word2Id AS word2Id;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	linkIds := map[string]string{}
+	relations := []map[string]any{}
+	links := []map[string]any{}
+	for _, record := range result {
+		m := record.AsMap()
+		startId, endId := wordIds[m["startId"].(string)], wordIds[m["endId"].(string)]
+		if startId == "" || endId == "" {
+			continue
+		}
+		relation := map[string]any{"startId": startId, "endId": endId, "linkId": nil}
+		linkId := common.Nullable{Value: m["linkId"]}.ToString()
+		if linkId != "" {
+			if linkIds[linkId] == "" {
+				linkIds[linkId] = common.NewId()
+				links = append(links, map[string]any{
+					"from":    linkId,
+					"to":      linkIds[linkId],
+					"word1Id": wordIds[common.Nullable{Value: m["word1Id"]}.ToString()],
+					"word2Id": wordIds[common.Nullable{Value: m["word2Id"]}.ToString()],
+				})
+			}
+			relation["linkId"] = linkIds[linkId]
+		}
+		relations = append(relations, relation)
+	}
+
+	if len(links) > 0 {
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`// graph.Clone.storeLinks
+				MATCH (u:User {id: $userId})
+			UNWIND $links AS row
+			MATCH (l:Link {id: row.from})
+			CREATE (c:Link {
+				id: row.to,
+				userId: $userId,
+				word1Id: row.word1Id,
+				word2Id: row.word2Id,
+				content: l.content,
+				description: l.description,
+				refs: l.refs,
+				createdAt: $createdAt
+			})
+			CREATE (u)-[:OWN]->(c);`,
+			map[string]interface{}{
+				"userId":    graph.UserId,
+				"links":     links,
+				"createdAt": createdAt,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(relations) > 0 {
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`// graph.Clone.storeRelations
+				UNWIND $relations AS row
+			MATCH (w1:Word {id: row.startId})
+			MATCH (w2:Word {id: row.endId})
+			CREATE (w1)-[r:CONCERN]->(w2)
+			SET r.id = row.linkId;`,
+			map[string]interface{}{
+				"relations": relations,
+			},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return graphId, nil
+}
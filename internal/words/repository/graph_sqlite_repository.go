@@ -0,0 +1,367 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type graphSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitGraphSQLiteRepository(db *datasource.SQLite) domain.GraphRepository {
+	return &graphSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+func scanGraph(rows *sql.Rows) (domain.Graph, error) {
+	g := domain.Graph{}
+	var visibility string
+	err := rows.Scan(&g.Id, &g.UserId, &g.Name, &g.Type, &visibility, &g.OriginId, &g.CreatedAt)
+	g.Visibility = domain.GraphVisibility(visibility)
+	return g, err
+}
+
+func (repo *graphSQLiteRepository) Select(ctx context.Context, userId string) ([]domain.Graph, error) {
+	graphs := []domain.Graph{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- graph.Select
+			SELECT id, user_id, name, type, visibility, origin_id, created_at FROM graphs
+		WHERE NOT delete_flag AND (user_id = ?1 OR id IN (
+			SELECT graph_id FROM graph_members WHERE user_id = ?1 AND status = ?2
+		))
+		ORDER BY created_at;`,
+		func(rows *sql.Rows) error {
+			g, err := scanGraph(rows)
+			graphs = append(graphs, g)
+			return err
+		},
+		userId, string(domain.MemberStatusAccepted),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return graphs, nil
+}
+
+func (repo *graphSQLiteRepository) Store(ctx context.Context, w domain.Graph) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- graph.Store
+			INSERT INTO graphs (id, user_id, name, type, visibility, origin_id, created_at)
+		VALUES (?, ?, ?, '3d', ?, ?, ?);`,
+		id, w.UserId, w.Name, string(w.Visibility), w.OriginId, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *graphSQLiteRepository) Update(ctx context.Context, id string, s domain.Graph) error {
+	// empty fields are left unchanged
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- graph.Update
+			UPDATE graphs SET
+			updated_at = ?,
+			name = COALESCE(NULLIF(?, ''), name),
+			type = COALESCE(NULLIF(?, ''), type),
+			visibility = COALESCE(NULLIF(?, ''), visibility)
+		WHERE id = ?;`,
+		time.Now(), s.Name, s.Type, string(s.Visibility), id,
+	)
+	return err
+}
+
+func (repo *graphSQLiteRepository) Delete(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- graph.Delete
+			UPDATE graphs SET delete_flag = TRUE, deleted_at = ? WHERE id = ?;`,
+		time.Now(), id,
+	)
+	return err
+}
+
+func scanDeletedGraph(rows *sql.Rows) (domain.Graph, error) {
+	g := domain.Graph{}
+	var visibility string
+	err := rows.Scan(&g.Id, &g.UserId, &g.Name, &g.Type, &visibility, &g.OriginId, &g.CreatedAt, &g.DeletedAt)
+	g.Visibility = domain.GraphVisibility(visibility)
+	return g, err
+}
+
+func (repo *graphSQLiteRepository) SelectDeleted(ctx context.Context, userId string) ([]domain.Graph, error) {
+	graphs := []domain.Graph{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- graph.SelectDeleted
+			SELECT id, user_id, name, type, visibility, origin_id, created_at, deleted_at FROM graphs
+		WHERE delete_flag AND (user_id = ?1 OR id IN (
+			SELECT graph_id FROM graph_members WHERE user_id = ?1 AND role = ?2 AND status = ?3
+		))
+		ORDER BY deleted_at DESC;`,
+		func(rows *sql.Rows) error {
+			g, err := scanDeletedGraph(rows)
+			graphs = append(graphs, g)
+			return err
+		},
+		userId, string(domain.GraphRoleOwner), string(domain.MemberStatusAccepted),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return graphs, nil
+}
+
+func (repo *graphSQLiteRepository) SelectDeletedOne(ctx context.Context, id string) (res *domain.Graph, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- graph.SelectDeletedOne
+			SELECT id, user_id, name, type, visibility, origin_id, created_at, deleted_at FROM graphs
+		WHERE id = ? AND delete_flag;`,
+		func(rows *sql.Rows) error {
+			g, err := scanDeletedGraph(rows)
+			res = &g
+			return err
+		},
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *graphSQLiteRepository) Restore(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- graph.Restore
+			UPDATE graphs SET delete_flag = FALSE, deleted_at = NULL WHERE id = ?;`,
+		id,
+	)
+	return err
+}
+
+func (repo *graphSQLiteRepository) SelectDeletedBefore(ctx context.Context, before time.Time) ([]domain.Graph, error) {
+	graphs := []domain.Graph{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- graph.SelectDeletedBefore
+			SELECT id, user_id, name, type, visibility, origin_id, created_at, deleted_at FROM graphs
+		WHERE delete_flag AND deleted_at IS NOT NULL;`,
+		func(rows *sql.Rows) error {
+			g, err := scanDeletedGraph(rows)
+			// the dates are stored as text, they are compared once scanned
+			if err == nil && g.DeletedAt.Before(before) {
+				graphs = append(graphs, g)
+			}
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	return graphs, nil
+}
+
+// the words of the graph ?1, in the trash or not
+const graphWordIds = `SELECT id FROM words WHERE graph_id = ?1
+			UNION SELECT id FROM trashed_words WHERE graph_id = ?1`
+
+func (repo *graphSQLiteRepository) Purge(ctx context.Context, id string) (*domain.PurgeReport, error) {
+	report := &domain.PurgeReport{}
+	err := repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		deleted := false
+		err := repo.Datasource.ExecRead(
+			ctx,
+			`-- graph.Purge.find
+				SELECT delete_flag FROM graphs WHERE id = ?;`,
+			func(rows *sql.Rows) error {
+				return rows.Scan(&deleted)
+			},
+			id,
+		)
+		// a graph restored meanwhile is kept
+		if err != nil || !deleted {
+			return err
+		}
+
+		// the rest of the data goes with the graph by the foreign keys
+		for _, step := range []struct {
+			query string
+			count *int
+		}{
+			{`-- graph.Purge.revisions
+				DELETE FROM revisions
+			WHERE entity_id IN (` + graphWordIds + `)
+				OR entity_id IN (SELECT id FROM links WHERE word1_id IN (` + graphWordIds + `) OR word2_id IN (` + graphWordIds + `))
+				OR entity_id IN (SELECT id FROM trashed_links WHERE word1_id IN (` + graphWordIds + `) OR word2_id IN (` + graphWordIds + `));`, &report.Revisions},
+			{`-- graph.Purge.trashedLinks
+				DELETE FROM trashed_links
+			WHERE word1_id IN (` + graphWordIds + `) OR word2_id IN (` + graphWordIds + `);`, &report.Links},
+			{`-- graph.Purge.trashedRelations
+				DELETE FROM trashed_relations
+			WHERE start_id IN (` + graphWordIds + `) OR end_id IN (` + graphWordIds + `);`, nil},
+			{`-- graph.Purge.trashedWords
+				DELETE FROM trashed_words WHERE graph_id = ?1;`, &report.Words},
+			{`-- graph.Purge.links
+				DELETE FROM links
+			WHERE word1_id IN (` + graphWordIds + `) OR word2_id IN (` + graphWordIds + `);`, &report.Links},
+			{`-- graph.Purge.words
+				DELETE FROM words WHERE graph_id = ?1;`, &report.Words},
+			{`-- graph.Purge
+				DELETE FROM graphs WHERE id = ?1;`, &report.Graphs},
+		} {
+			res, err := repo.Datasource.ExecWrite(ctx, step.query, id)
+			if err != nil {
+				return err
+			}
+			if step.count != nil {
+				n, err := res.RowsAffected()
+				if err != nil {
+					return err
+				}
+				*step.count += int(n)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (repo *graphSQLiteRepository) SelectOne(ctx context.Context, id string) (res *domain.Graph, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- graph.SelectOne
+			SELECT id, user_id, name, type, visibility, origin_id, created_at FROM graphs
+		WHERE id = ? AND NOT delete_flag;`,
+		func(rows *sql.Rows) error {
+			g, err := scanGraph(rows)
+			res = &g
+			return err
+		},
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *graphSQLiteRepository) Clone(ctx context.Context, id string, graph domain.Graph) (res *string, err error) {
+	err = repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		graphId, err := repo.Store(ctx, graph)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		wordIds := map[string]string{}
+		err = repo.Datasource.ExecRead(
+			ctx,
+			`-- graph.Clone.words
+				SELECT id FROM words WHERE graph_id = ?;`,
+			func(rows *sql.Rows) error {
+				var wordId string
+				err := rows.Scan(&wordId)
+				wordIds[wordId] = common.NewId()
+				return err
+			},
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		for from, to := range wordIds {
+			_, err := repo.Datasource.ExecWrite(
+				ctx,
+				`-- graph.Clone.storeWord
+					INSERT INTO words (id, graph_id, user_id, content, description, refs, created_at)
+				SELECT ?, ?, ?, content, description, refs, ? FROM words WHERE id = ?;`,
+				to, *graphId, graph.UserId, now, from,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the relationships between the words of the graph and their links
+		type relation struct {
+			startId, endId   string
+			linkId           sql.NullString
+			word1Id, word2Id sql.NullString
+		}
+		relations := []relation{}
+		err = repo.Datasource.ExecRead(
+			ctx,
+			`-- graph.Clone.relations
+				SELECT r.start_id, r.end_id, l.id, l.word1_id, l.word2_id FROM relations r
+			JOIN words w ON w.id = r.start_id
+			LEFT JOIN links l ON l.id = r.link_id
+			WHERE w.graph_id = ?;`,
+			func(rows *sql.Rows) error {
+				r := relation{}
+				err := rows.Scan(&r.startId, &r.endId, &r.linkId, &r.word1Id, &r.word2Id)
+				relations = append(relations, r)
+				return err
+			},
+			id,
+		)
+		if err != nil {
+			return err
+		}
+
+		linkIds := map[string]string{}
+		for _, r := range relations {
+			startId, endId := wordIds[r.startId], wordIds[r.endId]
+			if startId == "" || endId == "" {
+				continue
+			}
+			var linkId *string
+			if r.linkId.Valid {
+				if linkIds[r.linkId.String] == "" {
+					linkIds[r.linkId.String] = common.NewId()
+					_, err := repo.Datasource.ExecWrite(
+						ctx,
+						`-- graph.Clone.storeLink
+							INSERT INTO links (id, user_id, word1_id, word2_id, content, description, refs, created_at)
+						SELECT ?, ?, ?, ?, content, description, refs, ? FROM links WHERE id = ?;`,
+						linkIds[r.linkId.String], graph.UserId, wordIds[r.word1Id.String], wordIds[r.word2Id.String], now, r.linkId.String,
+					)
+					if err != nil {
+						return err
+					}
+				}
+				linkId = common.ToPointer(linkIds[r.linkId.String])
+			}
+			_, err := repo.Datasource.ExecWrite(
+				ctx,
+				`-- graph.Clone.storeRelation
+					INSERT INTO relations (start_id, end_id, link_id) VALUES (?, ?, ?);`,
+				startId, endId, linkId,
+			)
+			if err != nil {
+				return err
+			}
+		}
+		res = graphId
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
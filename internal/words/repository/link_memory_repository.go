@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type linkMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitLinkMemoryRepository(db *datasource.Memory) domain.LinkRepository {
+	return &linkMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func copyLink(l *domain.Link) domain.Link {
+	res := *l
+	if l.Refs != nil {
+		res.Refs = common.ToPointer(append([]string{}, *l.Refs...))
+	}
+	return res
+}
+
+func (repo *linkMemoryRepository) Store(ctx context.Context, r domain.Link) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Users[r.UserId] == nil || db.Words[r.Word1Id] == nil || db.Words[r.Word2Id] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	// the link annotates the relationships between both words
+	rels := []*datasource.MemoryRelation{}
+	for _, rel := range db.Relations {
+		if (rel.StartId == r.Word1Id && rel.EndId == r.Word2Id) ||
+			(rel.StartId == r.Word2Id && rel.EndId == r.Word1Id) {
+			rels = append(rels, rel)
+		}
+	}
+	if len(rels) == 0 {
+		return nil, common.ErrInternalServerError
+	}
+
+	link := copyLink(&r)
+	link.Id = common.NewId()
+	link.UpdatedAt = nil
+	db.Links[link.Id] = &link
+	for _, rel := range rels {
+		rel.Id = common.ToPointer(link.Id)
+	}
+	return &link.Id, nil
+}
+
+func (repo *linkMemoryRepository) FindById(ctx context.Context, id string) (*domain.Link, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	l := db.Links[id]
+	if l == nil {
+		return nil, nil
+	}
+	return common.ToPointer(copyLink(l)), nil
+}
+
+func (repo *linkMemoryRepository) FindByWordIds(ctx context.Context, w1Id string, w2Id string) (*domain.Link, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	for _, l := range db.Links {
+		if (l.Word1Id == w1Id && l.Word2Id == w2Id) || (l.Word1Id == w2Id && l.Word2Id == w1Id) {
+			return common.ToPointer(copyLink(l)), nil
+		}
+	}
+	return nil, nil
+}
+
+func (repo *linkMemoryRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Link, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	links := []domain.Link{}
+	found := map[string]bool{}
+	for _, r := range db.Relations {
+		if r.Id == nil || found[*r.Id] || db.Links[*r.Id] == nil {
+			continue
+		}
+		if w := db.Words[r.StartId]; w == nil || w.GraphId != graphId {
+			continue
+		}
+		found[*r.Id] = true
+		links = append(links, copyLink(db.Links[*r.Id]))
+	}
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].CreatedAt.Before(links[j].CreatedAt)
+	})
+	return links, nil
+}
+
+func (repo *linkMemoryRepository) Update(ctx context.Context, id string, link domain.Link) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	l := db.Links[id]
+	if l == nil {
+		return nil
+	}
+	updated := copyLink(&link)
+	l.Content = updated.Content
+	l.Description = updated.Description
+	l.Refs = updated.Refs
+	l.UpdatedAt = common.ToPointer(time.Now())
+	return nil
+}
+
+func (repo *linkMemoryRepository) Delete(ctx context.Context, w1Id string, w2Id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	rels := []*datasource.MemoryRelation{}
+	for _, r := range db.Relations {
+		if (r.StartId == w1Id && r.EndId == w2Id) || (r.StartId == w2Id && r.EndId == w1Id) {
+			continue
+		}
+		rels = append(rels, r)
+	}
+	db.Relations = rels
+	return nil
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -10,24 +11,26 @@ import (
 )
 
 type linkRepository struct {
-	Datasource *datasource.Neo4J
+	Datasource datasource.Datasource
 }
 
-func InitLinkRepository(db *datasource.Neo4J) domain.LinkRepository {
+func InitLinkRepository(db datasource.Datasource) domain.LinkRepository {
 	return &linkRepository{
 		Datasource: db,
 	}
 }
 
-func (repo *linkRepository) Store(r domain.Link) (*string, error) {
+func (repo *linkRepository) Store(ctx context.Context, r domain.Link) (*string, error) {
 
 	result, err := repo.Datasource.ExecWrite(
-		`MATCH (u:User {id: $userId})
+		ctx,
+		`// link.Store
+			MATCH (u:User {id: $userId})
 			MATCH (w1:Word {id: $word1Id})
 			MATCH (w2:Word {id: $word2Id})
-			MATCH (w1)-[r]-(w2)
+			MATCH (w1)-[r:CONCERN]-(w2)
 			CREATE (w:Link {
-				id: apoc.create.uuid(),
+				id: $id,
 				userId: $userId,
 				word1Id: $word1Id,
 				word2Id: $word2Id,
@@ -40,6 +43,7 @@ func (repo *linkRepository) Store(r domain.Link) (*string, error) {
 			SET r.id = w.id
 			RETURN w.id as id;`,
 		map[string]interface{}{
+			"id":          common.NewId(),
 			"userId":      r.UserId,
 			"word1Id":     r.Word1Id,
 			"word2Id":     r.Word2Id,
@@ -64,93 +68,95 @@ func (repo *linkRepository) Store(r domain.Link) (*string, error) {
 	return common.Nullable{Value: _id}.ToStringPtr(), nil
 }
 
-func (r *linkRepository) FindById(id string) (*domain.Link, error) {
-	result, err := r.Datasource.ExecRead(
-		`MATCH (r:Link {id: $id})
-			RETURN r.id AS id,
+func recordToLink(record map[string]any) domain.Link {
+	l := domain.Link{
+		Id:          record["id"].(string),
+		UserId:      common.Nullable{Value: record["userId"]}.ToString(),
+		Word1Id:     common.Nullable{Value: record["word1Id"]}.ToString(),
+		Word2Id:     common.Nullable{Value: record["word2Id"]}.ToString(),
+		Content:     common.Nullable{Value: record["content"]}.ToString(),
+		Description: common.Nullable{Value: record["description"]}.ToStringPtr(),
+		Refs:        common.Nullable{Value: record["refs"]}.ToStringArrayPtr(),
+	}
+	if record["createdAt"] != nil {
+		l.CreatedAt = record["createdAt"].(neo4j.LocalDateTime).Time()
+	}
+	if record["updatedAt"] != nil {
+		l.UpdatedAt = common.ToPointer(record["updatedAt"].(neo4j.LocalDateTime).Time())
+	}
+	return l
+}
+
+const linkReturn = `r.id AS id,
+				r.word1Id AS word1Id,
+				r.word2Id AS word2Id,
 				r.userId AS userId,
 				r.content AS content,
 				r.description AS description,
 				r.refs AS refs,
-				r.createdAt AS createdAt;`,
+				r.createdAt AS createdAt,
+				r.updatedAt AS updatedAt`
+
+func (r *linkRepository) FindById(ctx context.Context, id string) (*domain.Link, error) {
+	result, err := r.Datasource.ExecRead(
+		ctx,
+		`// link.FindById
+			MATCH (r:Link {id: $id})
+			RETURN `+linkReturn+`;`,
 		map[string]interface{}{
 			"id": id,
 		},
 	)
-	if err != nil {
+	if err != nil || len(result) == 0 {
 		return nil, err
 	}
-	if len(result) == 0 {
-		return nil, nil
-	}
-
-	record := result[0]
-	if err != nil {
-		return nil, err
-	}
-
-	_id, _ := record.Get("id")
-	_userId, _ := record.Get("userId")
-	_content, _ := record.Get("content")
-	_description, _ := record.Get("description")
-	_refs, _ := record.Get("refs")
-	_createdAt, _ := record.Get("createdAt")
-	return &domain.Link{
-		Id:          _id.(string),
-		UserId:      _userId.(string),
-		Content:     _content.(string),
-		Description: common.Nullable{Value: _description}.ToStringPtr(),
-		Refs:        common.Nullable{Value: _refs}.ToStringArrayPtr(),
-		CreatedAt:   _createdAt.(neo4j.LocalDateTime).Time(),
-	}, nil
+	return common.ToPointer(recordToLink(result[0].AsMap())), nil
 }
 
-func (r *linkRepository) FindByWordIds(w1Id string, w2Id string) (*domain.Link, error) {
+func (r *linkRepository) FindByWordIds(ctx context.Context, w1Id string, w2Id string) (*domain.Link, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH (r:Link)
+		ctx,
+		`// link.FindByWordIds
+			MATCH (r:Link)
 			WHERE (r.word1Id = $w1Id AND r.word2Id = $w2Id)
 				 OR (r.word1Id = $w2Id AND r.word2Id = $w1Id)
-			RETURN r.id AS id,
-				r.userId AS userId,
-				r.content AS content,
-				r.description AS description,
-				r.refs AS refs,
-				r.createdAt AS createdAt;`,
+			RETURN `+linkReturn+`;`,
 		map[string]interface{}{
 			"w1Id": w1Id,
 			"w2Id": w2Id,
 		},
 	)
-	if err != nil {
+	if err != nil || len(result) == 0 {
 		return nil, err
 	}
-	if len(result) == 0 {
-		return nil, nil
-	}
+	return common.ToPointer(recordToLink(result[0].AsMap())), nil
+}
 
-	record := result[0]
+func (r *linkRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Link, error) {
+	result, err := r.Datasource.ExecRead(
+		ctx,
+		`// link.FindByGraphId
+			MATCH (:Graph {id: $graphId})-[:WORD]->(:Word)-[c:CONCERN]->(:Word)
+			MATCH (r:Link {id: c.id})
+			RETURN DISTINCT `+linkReturn+`;`,
+		map[string]interface{}{
+			"graphId": graphId,
+		},
+	)
 	if err != nil {
 		return nil, err
 	}
 
-	_id, _ := record.Get("id")
-	_userId, _ := record.Get("userId")
-	_content, _ := record.Get("content")
-	_description, _ := record.Get("description")
-	_refs, _ := record.Get("refs")
-	_createdAt, _ := record.Get("createdAt")
-	return &domain.Link{
-		Id:          _id.(string),
-		UserId:      _userId.(string),
-		Content:     _content.(string),
-		Description: common.Nullable{Value: _description}.ToStringPtr(),
-		Refs:        common.Nullable{Value: _refs}.ToStringArrayPtr(),
-		CreatedAt:   _createdAt.(neo4j.LocalDateTime).Time(),
-	}, nil
+	links := []domain.Link{}
+	for _, record := range result {
+		links = append(links, recordToLink(record.AsMap()))
+	}
+	return links, nil
 }
 
-func (repo *linkRepository) Update(id string, link domain.Link) error {
-	query := `MATCH (r:Link {id: $id})
+func (repo *linkRepository) Update(ctx context.Context, id string, link domain.Link) error {
+	query := `// link.Update
+		MATCH (r:Link {id: $id})
 	SET r.content = $content,
 			r.description = $description,
 			r.refs = $refs,
@@ -163,7 +169,7 @@ func (repo *linkRepository) Update(id string, link domain.Link) error {
 		"updatedAt":   neo4j.LocalDateTimeOf(time.Now()),
 	}
 
-	_, err := repo.Datasource.ExecWrite(query, params)
+	_, err := repo.Datasource.ExecWrite(ctx, query, params)
 
 	if err != nil {
 		return err
@@ -171,7 +177,7 @@ func (repo *linkRepository) Update(id string, link domain.Link) error {
 	return nil
 }
 
-func (r *linkRepository) Delete(w1Id string, w2Id string) error {
+func (r *linkRepository) Delete(ctx context.Context, w1Id string, w2Id string) error {
 	// _, err := r.Datasource.ExecWrite(
 	// 	`MATCH (r:Link {id: $id})
 	// 		MATCH (w1:Word) WHERE Id(w1) = r.word1Id
@@ -184,7 +190,9 @@ func (r *linkRepository) Delete(w1Id string, w2Id string) error {
 	// 	},
 	// )
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (w1:Word {id: $w1Id})-[r:CONCERN]-(w2:Word {id: $w2Id})
+		ctx,
+		`// link.Delete
+			MATCH (w1:Word {id: $w1Id})-[r:CONCERN]-(w2:Word {id: $w2Id})
 		DELETE r;
 		`,
 		map[string]interface{}{
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/datasource/datasourcetest"
+)
+
+func linkNode() map[string]any {
+	return map[string]any{
+		"id":          "l1",
+		"word1Id":     "w1",
+		"word2Id":     "w2",
+		"userId":      "u1",
+		"content":     "causes",
+		"description": nil,
+		"refs":        nil,
+		"createdAt":   neo4j.LocalDateTimeOf(time.Now()),
+		"updatedAt":   nil,
+	}
+}
+
+func TestLinkFindByIdReturnsWordIds(t *testing.T) {
+	db := datasourcetest.NewFake()
+	db.Handlers["link.FindById"] = func(params map[string]any) []map[string]any {
+		if params["id"] != "l1" {
+			return nil
+		}
+		return []map[string]any{linkNode()}
+	}
+	repo := InitLinkRepository(db)
+
+	l, err := repo.FindById(context.Background(), "l1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l == nil || l.Word1Id != "w1" || l.Word2Id != "w2" || l.Content != "causes" {
+		t.Fatalf("FindById() = %+v, want the link between w1 and w2", l)
+	}
+
+	l, err = repo.FindById(context.Background(), "l2")
+	if err != nil || l != nil {
+		t.Fatalf("FindById() = %+v, %v, want nil", l, err)
+	}
+}
+
+func TestLinkFindByWordIdsReturnsWordIds(t *testing.T) {
+	db := datasourcetest.NewFake()
+	db.Handlers["link.FindByWordIds"] = func(params map[string]any) []map[string]any {
+		return []map[string]any{linkNode()}
+	}
+	repo := InitLinkRepository(db)
+
+	l, err := repo.FindByWordIds(context.Background(), "w2", "w1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l == nil || l.Word1Id != "w1" || l.Word2Id != "w2" {
+		t.Fatalf("FindByWordIds() = %+v, want the link between w1 and w2", l)
+	}
+}
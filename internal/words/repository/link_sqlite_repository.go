@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type linkSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitLinkSQLiteRepository(db *datasource.SQLite) domain.LinkRepository {
+	return &linkSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+const linkColumns = `id, word1_id, word2_id, user_id, content, description, refs, created_at, updated_at`
+
+func scanLink(rows *sql.Rows) (domain.Link, error) {
+	l := domain.Link{}
+	var refs sql.NullString
+	err := rows.Scan(&l.Id, &l.Word1Id, &l.Word2Id, &l.UserId, &l.Content, &l.Description, &refs, &l.CreatedAt, &l.UpdatedAt)
+	l.Refs = jsonToRefs(refs)
+	return l, err
+}
+
+func (repo *linkSQLiteRepository) Store(ctx context.Context, r domain.Link) (*string, error) {
+	id := common.NewId()
+	err := repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		// the link annotates the relationships between both words
+		res, err := repo.Datasource.ExecWrite(
+			ctx,
+			`-- link.Store.relations
+				UPDATE relations SET link_id = ?1
+			WHERE (start_id = ?2 AND end_id = ?3) OR (start_id = ?3 AND end_id = ?2);`,
+			id, r.Word1Id, r.Word2Id,
+		)
+		if err != nil {
+			return err
+		}
+		if n, err := res.RowsAffected(); err != nil || n == 0 {
+			return common.ErrInternalServerError
+		}
+
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- link.Store
+				INSERT INTO links (id, user_id, word1_id, word2_id, content, description, refs, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+			id, r.UserId, r.Word1Id, r.Word2Id, r.Content, r.Description, refsToJSON(r.Refs), r.CreatedAt,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *linkSQLiteRepository) findOne(ctx context.Context, query string, args ...any) (res *domain.Link, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			l, err := scanLink(rows)
+			res = &l
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *linkSQLiteRepository) FindById(ctx context.Context, id string) (*domain.Link, error) {
+	return repo.findOne(
+		ctx,
+		`-- link.FindById
+			SELECT `+linkColumns+` FROM links WHERE id = ?;`,
+		id,
+	)
+}
+
+func (repo *linkSQLiteRepository) FindByWordIds(ctx context.Context, w1Id string, w2Id string) (*domain.Link, error) {
+	return repo.findOne(
+		ctx,
+		`-- link.FindByWordIds
+			SELECT `+linkColumns+` FROM links
+		WHERE (word1_id = ?1 AND word2_id = ?2) OR (word1_id = ?2 AND word2_id = ?1)
+		LIMIT 1;`,
+		w1Id, w2Id,
+	)
+}
+
+func (repo *linkSQLiteRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Link, error) {
+	links := []domain.Link{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- link.FindByGraphId
+			SELECT `+linkColumns+` FROM links
+		WHERE id IN (
+			SELECT r.link_id FROM relations r
+			JOIN words w ON w.id = r.start_id
+			WHERE w.graph_id = ?
+		)
+		ORDER BY created_at;`,
+		func(rows *sql.Rows) error {
+			l, err := scanLink(rows)
+			links = append(links, l)
+			return err
+		},
+		graphId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (repo *linkSQLiteRepository) Update(ctx context.Context, id string, link domain.Link) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- link.Update
+			UPDATE links SET content = ?, description = ?, refs = ?, updated_at = ?
+		WHERE id = ?;`,
+		link.Content, link.Description, refsToJSON(link.Refs), time.Now(), id,
+	)
+	return err
+}
+
+func (repo *linkSQLiteRepository) Delete(ctx context.Context, w1Id string, w2Id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- link.Delete
+			DELETE FROM relations
+		WHERE (start_id = ?1 AND end_id = ?2) OR (start_id = ?2 AND end_id = ?1);`,
+		w1Id, w2Id,
+	)
+	return err
+}
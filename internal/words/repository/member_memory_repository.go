@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type memberMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitMemberMemoryRepository(db *datasource.Memory) domain.MemberRepository {
+	return &memberMemoryRepository{
+		Datasource: db,
+	}
+}
+
+// Get the members matching fn, oldest first
+func (repo *memberMemoryRepository) filter(ctx context.Context, fn func(m *domain.GraphMember) bool) []domain.GraphMember {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	members := []domain.GraphMember{}
+	for _, m := range db.Members {
+		if fn(m) {
+			members = append(members, *m)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].CreatedAt.Before(members[j].CreatedAt)
+	})
+	return members
+}
+
+func (repo *memberMemoryRepository) first(members []domain.GraphMember) *domain.GraphMember {
+	if len(members) == 0 {
+		return nil
+	}
+	return &members[0]
+}
+
+func (repo *memberMemoryRepository) FindById(ctx context.Context, id string) (*domain.GraphMember, error) {
+	return repo.first(repo.filter(ctx, func(m *domain.GraphMember) bool {
+		return m.Id == id
+	})), nil
+}
+
+func (repo *memberMemoryRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.GraphMember, error) {
+	return repo.filter(ctx, func(m *domain.GraphMember) bool {
+		return m.GraphId == graphId
+	}), nil
+}
+
+func (repo *memberMemoryRepository) FindByGraphIdAndEmail(ctx context.Context, graphId string, email string) (*domain.GraphMember, error) {
+	return repo.first(repo.filter(ctx, func(m *domain.GraphMember) bool {
+		return m.GraphId == graphId && m.Email == email
+	})), nil
+}
+
+func (repo *memberMemoryRepository) FindByGraphIdAndUserId(ctx context.Context, graphId string, userId string) (*domain.GraphMember, error) {
+	return repo.first(repo.filter(ctx, func(m *domain.GraphMember) bool {
+		return m.GraphId == graphId && m.UserId != nil && *m.UserId == userId && m.Status == domain.MemberStatusAccepted
+	})), nil
+}
+
+func (repo *memberMemoryRepository) FindPendingByEmail(ctx context.Context, email string) ([]domain.GraphMember, error) {
+	return repo.filter(ctx, func(m *domain.GraphMember) bool {
+		g := repo.Datasource.Graphs[m.GraphId]
+		return m.Email == email && m.Status == domain.MemberStatusPending && g != nil && !g.DeleteFlag
+	}), nil
+}
+
+func (repo *memberMemoryRepository) Store(ctx context.Context, m domain.GraphMember) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Graphs[m.GraphId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	member := domain.GraphMember{
+		Id:        common.NewId(),
+		GraphId:   m.GraphId,
+		Email:     m.Email,
+		Role:      m.Role,
+		Status:    m.Status,
+		InvitedBy: m.InvitedBy,
+		CreatedAt: m.CreatedAt,
+	}
+	db.Members[member.Id] = &member
+	return &member.Id, nil
+}
+
+func (repo *memberMemoryRepository) Update(ctx context.Context, id string, m domain.GraphMember) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	member := db.Members[id]
+	if member == nil {
+		return nil
+	}
+	member.UserId = m.UserId
+	member.Role = m.Role
+	member.Status = m.Status
+	member.AcceptedAt = m.AcceptedAt
+	return nil
+}
+
+func (repo *memberMemoryRepository) Delete(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	delete(db.Members, id)
+	return nil
+}
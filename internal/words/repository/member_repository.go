@@ -0,0 +1,202 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type memberRepository struct {
+	Datasource datasource.Datasource
+}
+
+func InitMemberRepository(db datasource.Datasource) domain.MemberRepository {
+	return &memberRepository{
+		Datasource: db,
+	}
+}
+
+const memberReturn = `RETURN m.id AS id,
+				m.graphId AS graphId,
+				m.userId AS userId,
+				m.email AS email,
+				m.role AS role,
+				m.status AS status,
+				m.invitedBy AS invitedBy,
+				m.createdAt AS createdAt,
+				m.acceptedAt AS acceptedAt`
+
+func recordToMember(record map[string]any) *domain.GraphMember {
+	m := domain.GraphMember{
+		Id:        record["id"].(string),
+		GraphId:   record["graphId"].(string),
+		UserId:    common.Nullable{Value: record["userId"]}.ToStringPtr(),
+		Email:     record["email"].(string),
+		Role:      domain.GraphRole(record["role"].(string)),
+		Status:    domain.MemberStatus(record["status"].(string)),
+		InvitedBy: record["invitedBy"].(string),
+	}
+	if record["createdAt"] != nil {
+		m.CreatedAt = record["createdAt"].(neo4j.LocalDateTime).Time()
+	}
+	if record["acceptedAt"] != nil {
+		m.AcceptedAt = common.ToPointer(record["acceptedAt"].(neo4j.LocalDateTime).Time())
+	}
+	return &m
+}
+
+func (repo *memberRepository) findOne(ctx context.Context, query string, params map[string]any) (*domain.GraphMember, error) {
+	result, err := repo.Datasource.ExecRead(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return recordToMember(result[0].AsMap()), nil
+}
+
+func (repo *memberRepository) find(ctx context.Context, query string, params map[string]any) ([]domain.GraphMember, error) {
+	result, err := repo.Datasource.ExecRead(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	members := []domain.GraphMember{}
+	for _, record := range result {
+		members = append(members, *recordToMember(record.AsMap()))
+	}
+	return members, nil
+}
+
+func (repo *memberRepository) FindById(ctx context.Context, id string) (*domain.GraphMember, error) {
+	return repo.findOne(ctx, `// member.FindById
+			MATCH (m:GraphMember {id: $id})
+			`+memberReturn+`;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+}
+
+func (repo *memberRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.GraphMember, error) {
+	return repo.find(ctx, `// member.FindByGraphId
+			MATCH (m:GraphMember {graphId: $graphId})
+			`+memberReturn+`
+			ORDER BY m.createdAt;`,
+		map[string]interface{}{
+			"graphId": graphId,
+		},
+	)
+}
+
+func (repo *memberRepository) FindByGraphIdAndEmail(ctx context.Context, graphId string, email string) (*domain.GraphMember, error) {
+	return repo.findOne(ctx, `// member.FindByGraphIdAndEmail
+			MATCH (m:GraphMember {graphId: $graphId, email: $email})
+			`+memberReturn+`;`,
+		map[string]interface{}{
+			"graphId": graphId,
+			"email":   email,
+		},
+	)
+}
+
+func (repo *memberRepository) FindByGraphIdAndUserId(ctx context.Context, graphId string, userId string) (*domain.GraphMember, error) {
+	return repo.findOne(ctx, `// member.FindByGraphIdAndUserId
+			MATCH (m:GraphMember {graphId: $graphId, userId: $userId, status: $status})
+			`+memberReturn+`;`,
+		map[string]interface{}{
+			"graphId": graphId,
+			"userId":  userId,
+			"status":  string(domain.MemberStatusAccepted),
+		},
+	)
+}
+
+func (repo *memberRepository) FindPendingByEmail(ctx context.Context, email string) ([]domain.GraphMember, error) {
+	return repo.find(ctx, `// member.FindPendingByEmail
+			MATCH (m:GraphMember {email: $email, status: $status})
+			MATCH (g:Graph {id: m.graphId, deleteFlag: false})
+			`+memberReturn+`
+			ORDER BY m.createdAt;`,
+		map[string]interface{}{
+			"email":  email,
+			"status": string(domain.MemberStatusPending),
+		},
+	)
+}
+
+func (repo *memberRepository) Store(ctx context.Context, m domain.GraphMember) (*string, error) {
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// member.Store
+			MATCH (g:Graph {id: $graphId})
+		CREATE (m:GraphMember {
+			id: $id,
+			graphId: $graphId,
+			email: $email,
+			role: $role,
+			status: $status,
+			invitedBy: $invitedBy,
+			createdAt: $createdAt
+		})
+		CREATE (g)-[:MEMBER]->(m)
+		RETURN m.id AS id;`,
+		map[string]interface{}{
+			"id":        common.NewId(),
+			"graphId":   m.GraphId,
+			"email":     m.Email,
+			"role":      string(m.Role),
+			"status":    string(m.Status),
+			"invitedBy": m.InvitedBy,
+			"createdAt": neo4j.LocalDateTimeOf(m.CreatedAt),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, common.ErrInternalServerError
+	}
+
+	_id, _ := result[0].Get("id")
+	return common.Nullable{Value: _id}.ToStringPtr(), nil
+}
+
+func (repo *memberRepository) Update(ctx context.Context, id string, m domain.GraphMember) error {
+	params := map[string]interface{}{
+		"id":         id,
+		"userId":     m.UserId,
+		"role":       string(m.Role),
+		"status":     string(m.Status),
+		"acceptedAt": nil,
+	}
+	if m.AcceptedAt != nil {
+		params["acceptedAt"] = neo4j.LocalDateTimeOf(*m.AcceptedAt)
+	}
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// member.Update
+			MATCH (m:GraphMember {id: $id})
+		SET m.userId = $userId,
+			m.role = $role,
+			m.status = $status,
+			m.acceptedAt = $acceptedAt;`,
+		params,
+	)
+	return err
+}
+
+func (repo *memberRepository) Delete(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// member.Delete
+			MATCH (m:GraphMember {id: $id}) DETACH DELETE m;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	return err
+}
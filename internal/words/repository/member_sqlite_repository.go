@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type memberSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitMemberSQLiteRepository(db *datasource.SQLite) domain.MemberRepository {
+	return &memberSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+const memberColumns = `id, graph_id, user_id, email, role, status, invited_by, created_at, accepted_at`
+
+func scanMember(rows *sql.Rows) (domain.GraphMember, error) {
+	m := domain.GraphMember{}
+	var role, status string
+	err := rows.Scan(&m.Id, &m.GraphId, &m.UserId, &m.Email, &role, &status, &m.InvitedBy, &m.CreatedAt, &m.AcceptedAt)
+	m.Role = domain.GraphRole(role)
+	m.Status = domain.MemberStatus(status)
+	return m, err
+}
+
+func (repo *memberSQLiteRepository) find(ctx context.Context, query string, args ...any) ([]domain.GraphMember, error) {
+	members := []domain.GraphMember{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			m, err := scanMember(rows)
+			members = append(members, m)
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return members, nil
+}
+
+func (repo *memberSQLiteRepository) findOne(ctx context.Context, query string, args ...any) (*domain.GraphMember, error) {
+	members, err := repo.find(ctx, query, args...)
+	if err != nil || len(members) == 0 {
+		return nil, err
+	}
+	return &members[0], nil
+}
+
+func (repo *memberSQLiteRepository) FindById(ctx context.Context, id string) (*domain.GraphMember, error) {
+	return repo.findOne(
+		ctx,
+		`-- member.FindById
+			SELECT `+memberColumns+` FROM graph_members WHERE id = ?;`,
+		id,
+	)
+}
+
+func (repo *memberSQLiteRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.GraphMember, error) {
+	return repo.find(
+		ctx,
+		`-- member.FindByGraphId
+			SELECT `+memberColumns+` FROM graph_members
+		WHERE graph_id = ?
+		ORDER BY created_at;`,
+		graphId,
+	)
+}
+
+func (repo *memberSQLiteRepository) FindByGraphIdAndEmail(ctx context.Context, graphId string, email string) (*domain.GraphMember, error) {
+	return repo.findOne(
+		ctx,
+		`-- member.FindByGraphIdAndEmail
+			SELECT `+memberColumns+` FROM graph_members
+		WHERE graph_id = ? AND email = ?;`,
+		graphId, email,
+	)
+}
+
+func (repo *memberSQLiteRepository) FindByGraphIdAndUserId(ctx context.Context, graphId string, userId string) (*domain.GraphMember, error) {
+	return repo.findOne(
+		ctx,
+		`-- member.FindByGraphIdAndUserId
+			SELECT `+memberColumns+` FROM graph_members
+		WHERE graph_id = ? AND user_id = ? AND status = ?;`,
+		graphId, userId, string(domain.MemberStatusAccepted),
+	)
+}
+
+func (repo *memberSQLiteRepository) FindPendingByEmail(ctx context.Context, email string) ([]domain.GraphMember, error) {
+	return repo.find(
+		ctx,
+		`-- member.FindPendingByEmail
+			SELECT `+memberColumns+` FROM graph_members
+		WHERE email = ? AND status = ?
+			AND graph_id IN (SELECT id FROM graphs WHERE NOT delete_flag)
+		ORDER BY created_at;`,
+		email, string(domain.MemberStatusPending),
+	)
+}
+
+func (repo *memberSQLiteRepository) Store(ctx context.Context, m domain.GraphMember) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- member.Store
+			INSERT INTO graph_members (id, graph_id, email, role, status, invited_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?);`,
+		id, m.GraphId, m.Email, string(m.Role), string(m.Status), m.InvitedBy, m.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *memberSQLiteRepository) Update(ctx context.Context, id string, m domain.GraphMember) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- member.Update
+			UPDATE graph_members SET user_id = ?, role = ?, status = ?, accepted_at = ?
+		WHERE id = ?;`,
+		m.UserId, string(m.Role), string(m.Status), m.AcceptedAt, id,
+	)
+	return err
+}
+
+func (repo *memberSQLiteRepository) Delete(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- member.Delete
+			DELETE FROM graph_members WHERE id = ?;`,
+		id,
+	)
+	return err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type revisionMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitRevisionMemoryRepository(db *datasource.Memory) domain.RevisionRepository {
+	return &revisionMemoryRepository{
+		Datasource: db,
+	}
+}
+
+// Copy a revision with its refs
+func copyRevision(r *domain.Revision) domain.Revision {
+	res := *r
+	if r.Refs != nil {
+		res.Refs = common.ToPointer(append([]string{}, *r.Refs...))
+	}
+	return res
+}
+
+func (repo *revisionMemoryRepository) FindById(ctx context.Context, id string) (*domain.Revision, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	r := db.Revisions[id]
+	if r == nil {
+		return nil, nil
+	}
+	return common.ToPointer(copyRevision(r)), nil
+}
+
+func (repo *revisionMemoryRepository) FindByEntity(ctx context.Context, entityType domain.RevisionEntity, entityId string) ([]domain.Revision, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	revisions := []domain.Revision{}
+	for _, r := range db.Revisions {
+		if r.EntityType == entityType && r.EntityId == entityId {
+			revisions = append(revisions, copyRevision(r))
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Version > revisions[j].Version
+	})
+	return revisions, nil
+}
+
+func (repo *revisionMemoryRepository) Store(ctx context.Context, r domain.Revision) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	revision := copyRevision(&r)
+	revision.Id = common.NewId()
+	revision.Version = 1
+	for _, p := range db.Revisions {
+		if p.EntityType == r.EntityType && p.EntityId == r.EntityId && p.Version >= revision.Version {
+			revision.Version = p.Version + 1
+		}
+	}
+	db.Revisions[revision.Id] = &revision
+	return &revision.Id, nil
+}
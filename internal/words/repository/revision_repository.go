@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type revisionRepository struct {
+	Datasource datasource.Datasource
+}
+
+func InitRevisionRepository(db datasource.Datasource) domain.RevisionRepository {
+	return &revisionRepository{
+		Datasource: db,
+	}
+}
+
+const revisionReturn = `RETURN r.id AS id,
+				r.entityType AS entityType,
+				r.entityId AS entityId,
+				r.version AS version,
+				r.userId AS userId,
+				r.content AS content,
+				r.description AS description,
+				r.refs AS refs,
+				r.createdAt AS createdAt`
+
+func recordToRevision(record map[string]any) *domain.Revision {
+	r := domain.Revision{
+		Id:          record["id"].(string),
+		EntityType:  domain.RevisionEntity(record["entityType"].(string)),
+		EntityId:    record["entityId"].(string),
+		Version:     int(record["version"].(int64)),
+		UserId:      record["userId"].(string),
+		Content:     record["content"].(string),
+		Description: common.Nullable{Value: record["description"]}.ToStringPtr(),
+		Refs:        common.Nullable{Value: record["refs"]}.ToStringArrayPtr(),
+	}
+	if record["createdAt"] != nil {
+		r.CreatedAt = record["createdAt"].(neo4j.LocalDateTime).Time()
+	}
+	return &r
+}
+
+func (repo *revisionRepository) find(ctx context.Context, query string, params map[string]any) ([]domain.Revision, error) {
+	result, err := repo.Datasource.ExecRead(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	revisions := []domain.Revision{}
+	for _, record := range result {
+		revisions = append(revisions, *recordToRevision(record.AsMap()))
+	}
+	return revisions, nil
+}
+
+func (repo *revisionRepository) FindById(ctx context.Context, id string) (*domain.Revision, error) {
+	revisions, err := repo.find(ctx, `// revision.FindById
+			MATCH (r:Revision {id: $id})
+			`+revisionReturn+`;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
+
+func (repo *revisionRepository) FindByEntity(ctx context.Context, entityType domain.RevisionEntity, entityId string) ([]domain.Revision, error) {
+	return repo.find(ctx, `// revision.FindByEntity
+			MATCH (r:Revision {entityId: $entityId, entityType: $entityType})
+			`+revisionReturn+`
+			ORDER BY r.version DESC;`,
+		map[string]interface{}{
+			"entityType": string(entityType),
+			"entityId":   entityId,
+		},
+	)
+}
+
+func (repo *revisionRepository) Store(ctx context.Context, r domain.Revision) (*string, error) {
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// revision.Store
+			OPTIONAL MATCH (p:Revision {entityId: $entityId, entityType: $entityType})
+		WITH coalesce(max(p.version), 0) + 1 AS version
+		CREATE (r:Revision {
+			id: $id,
+			entityType: $entityType,
+			entityId: $entityId,
+			version: version,
+			userId: $userId,
+			content: $content,
+			description: $description,
+			refs: $refs,
+			createdAt: $createdAt
+		})
+		RETURN r.id AS id;`,
+		map[string]interface{}{
+			"id":          common.NewId(),
+			"entityType":  string(r.EntityType),
+			"entityId":    r.EntityId,
+			"userId":      r.UserId,
+			"content":     r.Content,
+			"description": r.Description,
+			"refs":        r.Refs,
+			"createdAt":   neo4j.LocalDateTimeOf(r.CreatedAt),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, common.ErrInternalServerError
+	}
+
+	_id, _ := result[0].Get("id")
+	return common.Nullable{Value: _id}.ToStringPtr(), nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type revisionSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitRevisionSQLiteRepository(db *datasource.SQLite) domain.RevisionRepository {
+	return &revisionSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+const revisionColumns = `id, entity_type, entity_id, version, user_id, content, description, refs, created_at`
+
+func scanRevision(rows *sql.Rows) (domain.Revision, error) {
+	r := domain.Revision{}
+	var entityType string
+	var refs sql.NullString
+	err := rows.Scan(&r.Id, &entityType, &r.EntityId, &r.Version, &r.UserId, &r.Content, &r.Description, &refs, &r.CreatedAt)
+	r.EntityType = domain.RevisionEntity(entityType)
+	r.Refs = jsonToRefs(refs)
+	return r, err
+}
+
+func (repo *revisionSQLiteRepository) find(ctx context.Context, query string, args ...any) ([]domain.Revision, error) {
+	revisions := []domain.Revision{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			r, err := scanRevision(rows)
+			revisions = append(revisions, r)
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return revisions, nil
+}
+
+func (repo *revisionSQLiteRepository) FindById(ctx context.Context, id string) (*domain.Revision, error) {
+	revisions, err := repo.find(
+		ctx,
+		`-- revision.FindById
+			SELECT `+revisionColumns+` FROM revisions WHERE id = ?;`,
+		id,
+	)
+	if err != nil || len(revisions) == 0 {
+		return nil, err
+	}
+	return &revisions[0], nil
+}
+
+func (repo *revisionSQLiteRepository) FindByEntity(ctx context.Context, entityType domain.RevisionEntity, entityId string) ([]domain.Revision, error) {
+	return repo.find(
+		ctx,
+		`-- revision.FindByEntity
+			SELECT `+revisionColumns+` FROM revisions
+		WHERE entity_type = ? AND entity_id = ?
+		ORDER BY version DESC;`,
+		string(entityType), entityId,
+	)
+}
+
+func (repo *revisionSQLiteRepository) Store(ctx context.Context, r domain.Revision) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- revision.Store
+			INSERT INTO revisions (id, entity_type, entity_id, version, user_id, content, description, refs, created_at)
+		SELECT ?, ?, ?, coalesce(max(version), 0) + 1, ?, ?, ?, ?, ?
+		FROM revisions WHERE entity_type = ? AND entity_id = ?;`,
+		id, string(r.EntityType), r.EntityId, r.UserId, r.Content, r.Description, refsToJSON(r.Refs), r.CreatedAt,
+		string(r.EntityType), r.EntityId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"sort"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type shareMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitShareMemoryRepository(db *datasource.Memory) domain.ShareRepository {
+	return &shareMemoryRepository{
+		Datasource: db,
+	}
+}
+
+// Get the share links matching fn, oldest first
+func (repo *shareMemoryRepository) filter(ctx context.Context, fn func(s *domain.ShareLink) bool) []domain.ShareLink {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	shares := []domain.ShareLink{}
+	for _, s := range db.Shares {
+		if fn(s) {
+			shares = append(shares, *s)
+		}
+	}
+	sort.SliceStable(shares, func(i, j int) bool {
+		return shares[i].CreatedAt.Before(shares[j].CreatedAt)
+	})
+	return shares
+}
+
+func (repo *shareMemoryRepository) first(shares []domain.ShareLink) *domain.ShareLink {
+	if len(shares) == 0 {
+		return nil
+	}
+	return &shares[0]
+}
+
+func (repo *shareMemoryRepository) FindById(ctx context.Context, id string) (*domain.ShareLink, error) {
+	return repo.first(repo.filter(ctx, func(s *domain.ShareLink) bool {
+		return s.Id == id
+	})), nil
+}
+
+func (repo *shareMemoryRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.ShareLink, error) {
+	return repo.filter(ctx, func(s *domain.ShareLink) bool {
+		return s.GraphId == graphId
+	}), nil
+}
+
+func (repo *shareMemoryRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.ShareLink, error) {
+	return repo.first(repo.filter(ctx, func(s *domain.ShareLink) bool {
+		return s.TokenHash == tokenHash
+	})), nil
+}
+
+func (repo *shareMemoryRepository) Store(ctx context.Context, s domain.ShareLink) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Graphs[s.GraphId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	share := s
+	share.Id = common.NewId()
+	db.Shares[share.Id] = &share
+	return &share.Id, nil
+}
+
+func (repo *shareMemoryRepository) Delete(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	delete(db.Shares, id)
+	return nil
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type shareRepository struct {
+	Datasource datasource.Datasource
+}
+
+func InitShareRepository(db datasource.Datasource) domain.ShareRepository {
+	return &shareRepository{
+		Datasource: db,
+	}
+}
+
+const shareReturn = `RETURN s.id AS id,
+				s.graphId AS graphId,
+				s.tokenHash AS tokenHash,
+				s.permission AS permission,
+				s.passwordHash AS passwordHash,
+				s.expiresAt AS expiresAt,
+				s.createdBy AS createdBy,
+				s.createdAt AS createdAt`
+
+func recordToShare(record map[string]any) *domain.ShareLink {
+	s := domain.ShareLink{
+		Id:           record["id"].(string),
+		GraphId:      record["graphId"].(string),
+		TokenHash:    record["tokenHash"].(string),
+		Permission:   domain.SharePermission(record["permission"].(string)),
+		PasswordHash: common.Nullable{Value: record["passwordHash"]}.ToStringPtr(),
+		CreatedBy:    record["createdBy"].(string),
+	}
+	if record["expiresAt"] != nil {
+		s.ExpiresAt = common.ToPointer(record["expiresAt"].(neo4j.LocalDateTime).Time())
+	}
+	if record["createdAt"] != nil {
+		s.CreatedAt = record["createdAt"].(neo4j.LocalDateTime).Time()
+	}
+	return &s
+}
+
+func (repo *shareRepository) find(ctx context.Context, query string, params map[string]any) ([]domain.ShareLink, error) {
+	result, err := repo.Datasource.ExecRead(ctx, query, params)
+	if err != nil {
+		return nil, err
+	}
+	shares := []domain.ShareLink{}
+	for _, record := range result {
+		shares = append(shares, *recordToShare(record.AsMap()))
+	}
+	return shares, nil
+}
+
+func (repo *shareRepository) findOne(ctx context.Context, query string, params map[string]any) (*domain.ShareLink, error) {
+	shares, err := repo.find(ctx, query, params)
+	if err != nil || len(shares) == 0 {
+		return nil, err
+	}
+	return &shares[0], nil
+}
+
+func (repo *shareRepository) FindById(ctx context.Context, id string) (*domain.ShareLink, error) {
+	return repo.findOne(ctx, `// share.FindById
+			MATCH (s:ShareLink {id: $id})
+			`+shareReturn+`;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+}
+
+func (repo *shareRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.ShareLink, error) {
+	return repo.find(ctx, `// share.FindByGraphId
+			MATCH (s:ShareLink {graphId: $graphId})
+			`+shareReturn+`
+			ORDER BY s.createdAt;`,
+		map[string]interface{}{
+			"graphId": graphId,
+		},
+	)
+}
+
+func (repo *shareRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.ShareLink, error) {
+	return repo.findOne(ctx, `// share.FindByTokenHash
+			MATCH (s:ShareLink {tokenHash: $tokenHash})
+			`+shareReturn+`;`,
+		map[string]interface{}{
+			"tokenHash": tokenHash,
+		},
+	)
+}
+
+func (repo *shareRepository) Store(ctx context.Context, s domain.ShareLink) (*string, error) {
+	params := map[string]interface{}{
+		"id":           common.NewId(),
+		"graphId":      s.GraphId,
+		"tokenHash":    s.TokenHash,
+		"permission":   string(s.Permission),
+		"passwordHash": s.PasswordHash,
+		"expiresAt":    nil,
+		"createdBy":    s.CreatedBy,
+		"createdAt":    neo4j.LocalDateTimeOf(s.CreatedAt),
+	}
+	if s.ExpiresAt != nil {
+		// stored in UTC so the expiry read back compares with the current time
+		params["expiresAt"] = neo4j.LocalDateTimeOf(s.ExpiresAt.UTC())
+	}
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// share.Store
+			MATCH (g:Graph {id: $graphId})
+		CREATE (s:ShareLink {
+			id: $id,
+			graphId: $graphId,
+			tokenHash: $tokenHash,
+			permission: $permission,
+			passwordHash: $passwordHash,
+			expiresAt: $expiresAt,
+			createdBy: $createdBy,
+			createdAt: $createdAt
+		})
+		CREATE (g)-[:SHARE]->(s)
+		RETURN s.id AS id;`,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, common.ErrInternalServerError
+	}
+
+	_id, _ := result[0].Get("id")
+	return common.Nullable{Value: _id}.ToStringPtr(), nil
+}
+
+func (repo *shareRepository) Delete(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// share.Delete
+			MATCH (s:ShareLink {id: $id}) DETACH DELETE s;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	return err
+}
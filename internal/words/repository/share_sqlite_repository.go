@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type shareSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitShareSQLiteRepository(db *datasource.SQLite) domain.ShareRepository {
+	return &shareSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+const shareColumns = `id, graph_id, token_hash, permission, password_hash, expires_at, created_by, created_at`
+
+func scanShare(rows *sql.Rows) (domain.ShareLink, error) {
+	s := domain.ShareLink{}
+	var permission string
+	err := rows.Scan(&s.Id, &s.GraphId, &s.TokenHash, &permission, &s.PasswordHash, &s.ExpiresAt, &s.CreatedBy, &s.CreatedAt)
+	s.Permission = domain.SharePermission(permission)
+	return s, err
+}
+
+func (repo *shareSQLiteRepository) find(ctx context.Context, query string, args ...any) ([]domain.ShareLink, error) {
+	shares := []domain.ShareLink{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			s, err := scanShare(rows)
+			shares = append(shares, s)
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return shares, nil
+}
+
+func (repo *shareSQLiteRepository) findOne(ctx context.Context, query string, args ...any) (*domain.ShareLink, error) {
+	shares, err := repo.find(ctx, query, args...)
+	if err != nil || len(shares) == 0 {
+		return nil, err
+	}
+	return &shares[0], nil
+}
+
+func (repo *shareSQLiteRepository) FindById(ctx context.Context, id string) (*domain.ShareLink, error) {
+	return repo.findOne(
+		ctx,
+		`-- share.FindById
+			SELECT `+shareColumns+` FROM share_links WHERE id = ?;`,
+		id,
+	)
+}
+
+func (repo *shareSQLiteRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.ShareLink, error) {
+	return repo.find(
+		ctx,
+		`-- share.FindByGraphId
+			SELECT `+shareColumns+` FROM share_links
+		WHERE graph_id = ?
+		ORDER BY created_at;`,
+		graphId,
+	)
+}
+
+func (repo *shareSQLiteRepository) FindByTokenHash(ctx context.Context, tokenHash string) (*domain.ShareLink, error) {
+	return repo.findOne(
+		ctx,
+		`-- share.FindByTokenHash
+			SELECT `+shareColumns+` FROM share_links WHERE token_hash = ?;`,
+		tokenHash,
+	)
+}
+
+func (repo *shareSQLiteRepository) Store(ctx context.Context, s domain.ShareLink) (*string, error) {
+	id := common.NewId()
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- share.Store
+			INSERT INTO share_links (id, graph_id, token_hash, permission, password_hash, expires_at, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?);`,
+		id, s.GraphId, s.TokenHash, string(s.Permission), s.PasswordHash, s.ExpiresAt, s.CreatedBy, s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *shareSQLiteRepository) Delete(ctx context.Context, id string) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- share.Delete
+			DELETE FROM share_links WHERE id = ?;`,
+		id,
+	)
+	return err
+}
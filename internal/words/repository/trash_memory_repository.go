@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type trashMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitTrashMemoryRepository(db *datasource.Memory) domain.TrashRepository {
+	return &trashMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func isRelationOf(r datasource.MemoryRelation, w1Id string, w2Id string) bool {
+	return (r.StartId == w1Id && r.EndId == w2Id) || (r.StartId == w2Id && r.EndId == w1Id)
+}
+
+func (repo *trashMemoryRepository) FindByGraphId(ctx context.Context, graphId string) (*domain.Trash, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	trash := &domain.Trash{Words: []domain.TrashedWord{}, Relations: []domain.TrashedRelation{}}
+	for _, w := range db.TrashedWords {
+		if w.GraphId == graphId {
+			word := *w
+			word.Word = copyWord(&w.Word)
+			trash.Words = append(trash.Words, word)
+		}
+	}
+	sort.SliceStable(trash.Words, func(i, j int) bool {
+		return trash.Words[i].DeletedAt.After(trash.Words[j].DeletedAt)
+	})
+
+	inGraph := func(id string) bool {
+		if w := db.Words[id]; w != nil {
+			return w.GraphId == graphId
+		}
+		w := db.TrashedWords[id]
+		return w != nil && w.GraphId == graphId
+	}
+	for _, r := range db.TrashedRelations {
+		if r.TrashedWith != nil || (!inGraph(r.StartId) && !inGraph(r.EndId)) {
+			continue
+		}
+		relation := domain.TrashedRelation{
+			SourceId:  r.StartId,
+			TargetId:  r.EndId,
+			DeletedBy: r.DeletedBy,
+			DeletedAt: r.DeletedAt,
+		}
+		if r.Id != nil && db.TrashedLinks[*r.Id] != nil {
+			relation.Link = common.ToPointer(copyLink(db.TrashedLinks[*r.Id]))
+		}
+		trash.Relations = append(trash.Relations, relation)
+	}
+	// the relationships are kept in the order they were trashed
+	for i, j := 0, len(trash.Relations)-1; i < j; i, j = i+1, j-1 {
+		trash.Relations[i], trash.Relations[j] = trash.Relations[j], trash.Relations[i]
+	}
+	return trash, nil
+}
+
+func (repo *trashMemoryRepository) FindWordById(ctx context.Context, id string) (*domain.TrashedWord, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	w := db.TrashedWords[id]
+	if w == nil {
+		return nil, nil
+	}
+	word := *w
+	word.Word = copyWord(&w.Word)
+	return &word, nil
+}
+
+// the write lock is held by the caller
+func (repo *trashMemoryRepository) trashRelations(match func(r *datasource.MemoryRelation) bool, trashedWith *string, userId string) {
+	db := repo.Datasource
+	now := time.Now()
+	rels := []*datasource.MemoryRelation{}
+	for _, r := range db.Relations {
+		if !match(r) {
+			rels = append(rels, r)
+			continue
+		}
+		if r.Id != nil && db.Links[*r.Id] != nil {
+			db.TrashedLinks[*r.Id] = db.Links[*r.Id]
+			delete(db.Links, *r.Id)
+		}
+		db.TrashedRelations = append(db.TrashedRelations, &datasource.MemoryTrashedRelation{
+			MemoryRelation: *r,
+			TrashedWith:    trashedWith,
+			DeletedBy:      userId,
+			DeletedAt:      now,
+		})
+	}
+	db.Relations = rels
+}
+
+func (repo *trashMemoryRepository) TrashWord(ctx context.Context, id string, userId string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	w := db.Words[id]
+	if w == nil {
+		return nil
+	}
+	repo.trashRelations(func(r *datasource.MemoryRelation) bool {
+		return r.StartId == id || r.EndId == id
+	}, common.ToPointer(id), userId)
+	// the links left without a relationship are removed with the word
+	for lId, l := range db.Links {
+		if l.Word1Id == id || l.Word2Id == id {
+			delete(db.Links, lId)
+		}
+	}
+	delete(db.Words, id)
+	db.TrashedWords[id] = &domain.TrashedWord{
+		Word:      *w,
+		DeletedBy: userId,
+		DeletedAt: time.Now(),
+	}
+	return nil
+}
+
+func (repo *trashMemoryRepository) TrashRelation(ctx context.Context, w1Id string, w2Id string, userId string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	repo.trashRelations(func(r *datasource.MemoryRelation) bool {
+		return isRelationOf(*r, w1Id, w2Id)
+	}, nil, userId)
+	return nil
+}
+
+// the write lock is held by the caller
+func (repo *trashMemoryRepository) restoreRelation(r *datasource.MemoryTrashedRelation) {
+	db := repo.Datasource
+	if r.Id != nil && db.TrashedLinks[*r.Id] != nil {
+		db.Links[*r.Id] = db.TrashedLinks[*r.Id]
+		delete(db.TrashedLinks, *r.Id)
+	}
+	relation := r.MemoryRelation
+	db.Relations = append(db.Relations, &relation)
+}
+
+func (repo *trashMemoryRepository) RestoreWord(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	w := db.TrashedWords[id]
+	if w == nil {
+		return nil
+	}
+	word := w.Word
+	db.Words[id] = &word
+	delete(db.TrashedWords, id)
+
+	rels := []*datasource.MemoryTrashedRelation{}
+	for _, r := range db.TrashedRelations {
+		if r.TrashedWith == nil || *r.TrashedWith != id {
+			rels = append(rels, r)
+			continue
+		}
+		if db.Words[r.StartId] != nil && db.Words[r.EndId] != nil {
+			repo.restoreRelation(r)
+			continue
+		}
+		// the relationships going to a word still in the trash come back with it
+		if r.StartId == id {
+			r.TrashedWith = common.ToPointer(r.EndId)
+		} else {
+			r.TrashedWith = common.ToPointer(r.StartId)
+		}
+		rels = append(rels, r)
+	}
+	db.TrashedRelations = rels
+	return nil
+}
+
+func (repo *trashMemoryRepository) RestoreRelation(ctx context.Context, w1Id string, w2Id string) (bool, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Words[w1Id] == nil || db.Words[w2Id] == nil {
+		return false, nil
+	}
+	// the relationships are kept in the order they were trashed
+	for i := len(db.TrashedRelations) - 1; i >= 0; i-- {
+		r := db.TrashedRelations[i]
+		if r.TrashedWith != nil || !isRelationOf(r.MemoryRelation, w1Id, w2Id) {
+			continue
+		}
+		repo.restoreRelation(r)
+		db.TrashedRelations = append(db.TrashedRelations[:i:i], db.TrashedRelations[i+1:]...)
+		return true, nil
+	}
+	return false, nil
+}
+
+// the write lock is held by the caller
+func (repo *trashMemoryRepository) deleteRelations(match func(r *datasource.MemoryTrashedRelation) bool) {
+	db := repo.Datasource
+	rels := []*datasource.MemoryTrashedRelation{}
+	for _, r := range db.TrashedRelations {
+		if !match(r) {
+			rels = append(rels, r)
+			continue
+		}
+		if r.Id != nil {
+			delete(db.TrashedLinks, *r.Id)
+			repo.deleteRevisions(*r.Id)
+		}
+	}
+	db.TrashedRelations = rels
+}
+
+// Delete the revisions of the word or the link
+func (repo *trashMemoryRepository) deleteRevisions(entityId string) {
+	db := repo.Datasource
+	for id, r := range db.Revisions {
+		if r.EntityId == entityId {
+			delete(db.Revisions, id)
+		}
+	}
+}
+
+func (repo *trashMemoryRepository) DeleteWord(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	repo.deleteRelations(func(r *datasource.MemoryTrashedRelation) bool {
+		return r.StartId == id || r.EndId == id
+	})
+	delete(db.TrashedWords, id)
+	repo.deleteRevisions(id)
+	return nil
+}
+
+func (repo *trashMemoryRepository) DeleteRelation(ctx context.Context, w1Id string, w2Id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	repo.deleteRelations(func(r *datasource.MemoryTrashedRelation) bool {
+		return r.TrashedWith == nil && isRelationOf(r.MemoryRelation, w1Id, w2Id)
+	})
+	return nil
+}
@@ -0,0 +1,334 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// The trashed words and links are relabelled TrashedWord and TrashedLink,
+// and their CONCERN relationships are replaced with TRASHED_CONCERN ones,
+// so the queries of the graphs never see them.
+// trashedWith is the id of the word a relationship was trashed with,
+// it is missing on the relationships deleted on their own.
+type trashRepository struct {
+	Datasource datasource.Datasource
+}
+
+func InitTrashRepository(db datasource.Datasource) domain.TrashRepository {
+	return &trashRepository{
+		Datasource: db,
+	}
+}
+
+// Run the queries one after the other in a transaction
+func (repo *trashRepository) execAll(ctx context.Context, queries []string, params map[string]any) error {
+	return repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, q := range queries {
+			if _, err := repo.Datasource.ExecWrite(ctx, q, params); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func recordToTrashedWord(record map[string]any) domain.TrashedWord {
+	w := domain.TrashedWord{
+		Word:      *recordToWord(record),
+		DeletedBy: common.Nullable{Value: record["deletedBy"]}.ToString(),
+	}
+	if record["updatedAt"] != nil {
+		w.UpdatedAt = common.ToPointer(record["updatedAt"].(neo4j.LocalDateTime).Time())
+	}
+	if record["deletedAt"] != nil {
+		w.DeletedAt = record["deletedAt"].(neo4j.LocalDateTime).Time()
+	}
+	return w
+}
+
+const trashedWordReturn = `RETURN w.id AS id,
+				w.userId AS userId,
+				w.graphId AS graphId,
+				w.content AS content,
+				w.description AS description,
+				w.refs AS refs,
+				w.createdAt AS createdAt,
+				w.updatedAt AS updatedAt,
+				w.deletedBy AS deletedBy,
+				w.deletedAt AS deletedAt`
+
+func (repo *trashRepository) FindByGraphId(ctx context.Context, graphId string) (*domain.Trash, error) {
+	trash := &domain.Trash{Words: []domain.TrashedWord{}, Relations: []domain.TrashedRelation{}}
+	params := map[string]interface{}{
+		"graphId": graphId,
+	}
+	result, err := repo.Datasource.ExecRead(ctx, `// trash.FindByGraphId.words
+			MATCH (w:TrashedWord {graphId: $graphId})
+			`+trashedWordReturn+`
+			ORDER BY w.deletedAt DESC;`,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range result {
+		trash.Words = append(trash.Words, recordToTrashedWord(record.AsMap()))
+	}
+
+	result, err = repo.Datasource.ExecRead(ctx, `// trash.FindByGraphId.relations
+			MATCH (:Graph {id: $graphId})-[:WORD]->()-[t:TRASHED_CONCERN]-()
+			WHERE t.trashedWith IS NULL
+			WITH DISTINCT t
+			OPTIONAL MATCH (l:TrashedLink {id: t.id})
+			RETURN startNode(t).id AS sourceId,
+				endNode(t).id AS targetId,
+				t.deletedBy AS deletedBy,
+				t.deletedAt AS deletedAt,
+				l.id AS id,
+				l.userId AS userId,
+				l.word1Id AS word1Id,
+				l.word2Id AS word2Id,
+				l.content AS content,
+				l.description AS description,
+				l.refs AS refs,
+				l.createdAt AS createdAt,
+				l.updatedAt AS updatedAt
+			ORDER BY t.deletedAt DESC;`,
+		params,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range result {
+		m := record.AsMap()
+		r := domain.TrashedRelation{
+			SourceId:  m["sourceId"].(string),
+			TargetId:  m["targetId"].(string),
+			DeletedBy: common.Nullable{Value: m["deletedBy"]}.ToString(),
+		}
+		if m["deletedAt"] != nil {
+			r.DeletedAt = m["deletedAt"].(neo4j.LocalDateTime).Time()
+		}
+		if m["id"] != nil {
+			l := domain.Link{
+				Id:          m["id"].(string),
+				UserId:      common.Nullable{Value: m["userId"]}.ToString(),
+				Word1Id:     common.Nullable{Value: m["word1Id"]}.ToString(),
+				Word2Id:     common.Nullable{Value: m["word2Id"]}.ToString(),
+				Content:     common.Nullable{Value: m["content"]}.ToString(),
+				Description: common.Nullable{Value: m["description"]}.ToStringPtr(),
+				Refs:        common.Nullable{Value: m["refs"]}.ToStringArrayPtr(),
+			}
+			if m["createdAt"] != nil {
+				l.CreatedAt = m["createdAt"].(neo4j.LocalDateTime).Time()
+			}
+			if m["updatedAt"] != nil {
+				l.UpdatedAt = common.ToPointer(m["updatedAt"].(neo4j.LocalDateTime).Time())
+			}
+			r.Link = &l
+		}
+		trash.Relations = append(trash.Relations, r)
+	}
+	return trash, nil
+}
+
+func (repo *trashRepository) FindWordById(ctx context.Context, id string) (*domain.TrashedWord, error) {
+	result, err := repo.Datasource.ExecRead(ctx, `// trash.FindWordById
+			MATCH (w:TrashedWord {id: $id})
+			`+trashedWordReturn+`;`,
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+	if err != nil || len(result) == 0 {
+		return nil, err
+	}
+	return common.ToPointer(recordToTrashedWord(result[0].AsMap())), nil
+}
+
+func (repo *trashRepository) TrashWord(ctx context.Context, id string, userId string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`// trash.TrashWord.links
+				MATCH (:Word {id: $id})-[r:CONCERN]-()
+			WHERE r.id IS NOT NULL
+			MATCH (l:Link {id: r.id})
+			REMOVE l:Link
+			SET l:TrashedLink;`,
+			`// trash.TrashWord.relations
+				MATCH (:Word {id: $id})-[r:CONCERN]-()
+			WITH DISTINCT r, startNode(r) AS a, endNode(r) AS b
+			CREATE (a)-[t:TRASHED_CONCERN]->(b)
+			SET t = properties(r),
+				t.trashedWith = $id,
+				t.deletedBy = $userId,
+				t.deletedAt = $deletedAt
+			DELETE r;`,
+			`// trash.TrashWord.word
+				MATCH (w:Word {id: $id})
+			REMOVE w:Word
+			SET w:TrashedWord,
+				w.deletedBy = $userId,
+				w.deletedAt = $deletedAt;`,
+		},
+		map[string]interface{}{
+			"id":        id,
+			"userId":    userId,
+			"deletedAt": neo4j.LocalDateTimeOf(time.Now()),
+		},
+	)
+}
+
+func (repo *trashRepository) TrashRelation(ctx context.Context, w1Id string, w2Id string, userId string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`// trash.TrashRelation.links
+				MATCH (:Word {id: $w1Id})-[r:CONCERN]-(:Word {id: $w2Id})
+			WHERE r.id IS NOT NULL
+			MATCH (l:Link {id: r.id})
+			REMOVE l:Link
+			SET l:TrashedLink;`,
+			`// trash.TrashRelation
+				MATCH (:Word {id: $w1Id})-[r:CONCERN]-(:Word {id: $w2Id})
+			WITH DISTINCT r, startNode(r) AS a, endNode(r) AS b
+			CREATE (a)-[t:TRASHED_CONCERN]->(b)
+			SET t = properties(r),
+				t.deletedBy = $userId,
+				t.deletedAt = $deletedAt
+			DELETE r;`,
+		},
+		map[string]interface{}{
+			"w1Id":      w1Id,
+			"w2Id":      w2Id,
+			"userId":    userId,
+			"deletedAt": neo4j.LocalDateTimeOf(time.Now()),
+		},
+	)
+}
+
+func (repo *trashRepository) RestoreWord(ctx context.Context, id string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`// trash.RestoreWord.word
+				MATCH (w:TrashedWord {id: $id})
+			REMOVE w:TrashedWord, w.deletedBy, w.deletedAt
+			SET w:Word;`,
+			`// trash.RestoreWord.links
+				MATCH (:Word)-[t:TRASHED_CONCERN {trashedWith: $id}]->(:Word)
+			WHERE t.id IS NOT NULL
+			MATCH (l:TrashedLink {id: t.id})
+			REMOVE l:TrashedLink
+			SET l:Link;`,
+			`// trash.RestoreWord.relations
+				MATCH (a:Word)-[t:TRASHED_CONCERN {trashedWith: $id}]->(b:Word)
+			CREATE (a)-[r:CONCERN]->(b)
+			SET r = properties(t)
+			REMOVE r.trashedWith, r.deletedBy, r.deletedAt
+			DELETE t;`,
+			// the relationships going to a word still in the trash come back with it
+			`// trash.RestoreWord.handOver
+				MATCH (:Word {id: $id})-[t:TRASHED_CONCERN {trashedWith: $id}]-(o)
+			SET t.trashedWith = o.id;`,
+		},
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+}
+
+func (repo *trashRepository) RestoreRelation(ctx context.Context, w1Id string, w2Id string) (bool, error) {
+	result, err := repo.Datasource.ExecWrite(
+		ctx,
+		`// trash.RestoreRelation
+			MATCH (a:Word)-[t:TRASHED_CONCERN]->(b:Word)
+		WHERE t.trashedWith IS NULL
+			AND ((a.id = $w1Id AND b.id = $w2Id) OR (a.id = $w2Id AND b.id = $w1Id))
+		WITH a, b, t
+		ORDER BY t.deletedAt DESC
+		LIMIT 1
+		OPTIONAL MATCH (l:TrashedLink {id: t.id})
+		FOREACH (x IN CASE WHEN l IS NULL THEN [] ELSE [l] END |
+			REMOVE x:TrashedLink
+			SET x:Link)
+		CREATE (a)-[r:CONCERN]->(b)
+		SET r = properties(t)
+		REMOVE r.deletedBy, r.deletedAt
+		DELETE t
+		RETURN count(r) AS count;`,
+		map[string]interface{}{
+			"w1Id": w1Id,
+			"w2Id": w2Id,
+		},
+	)
+	if err != nil || len(result) == 0 {
+		return false, err
+	}
+	count, _ := result[0].Get("count")
+	return count.(int64) > 0, nil
+}
+
+func (repo *trashRepository) DeleteWord(ctx context.Context, id string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`// trash.DeleteWord.revisions
+				MATCH (w:TrashedWord {id: $id})
+			OPTIONAL MATCH (w)-[t:TRASHED_CONCERN]-()
+			WITH w.id AS wordId, collect(t.id) AS linkIds
+			MATCH (v:Revision) WHERE v.entityId = wordId OR v.entityId IN linkIds
+			DETACH DELETE v;`,
+			`// trash.DeleteWord.links
+				MATCH (:TrashedWord {id: $id})-[t:TRASHED_CONCERN]-()
+			WHERE t.id IS NOT NULL
+			WITH DISTINCT t.id AS linkId
+			MATCH (l:TrashedLink {id: linkId})
+			DETACH DELETE l;`,
+			`// trash.DeleteWord
+				MATCH (w:TrashedWord {id: $id})
+			DETACH DELETE w;`,
+		},
+		map[string]interface{}{
+			"id": id,
+		},
+	)
+}
+
+func (repo *trashRepository) DeleteRelation(ctx context.Context, w1Id string, w2Id string) error {
+	// the ends of the relationships may have been trashed since
+	return repo.execAll(
+		ctx,
+		[]string{
+			`// trash.DeleteRelation.revisions
+				MATCH (a)-[t:TRASHED_CONCERN]-(b)
+			WHERE (a:Word OR a:TrashedWord) AND a.id = $w1Id AND b.id = $w2Id
+				AND t.trashedWith IS NULL AND t.id IS NOT NULL
+			WITH DISTINCT t.id AS linkId
+			MATCH (v:Revision {entityId: linkId})
+			DETACH DELETE v;`,
+			`// trash.DeleteRelation.links
+				MATCH (a)-[t:TRASHED_CONCERN]-(b)
+			WHERE (a:Word OR a:TrashedWord) AND a.id = $w1Id AND b.id = $w2Id
+				AND t.trashedWith IS NULL AND t.id IS NOT NULL
+			WITH DISTINCT t.id AS linkId
+			MATCH (l:TrashedLink {id: linkId})
+			DETACH DELETE l;`,
+			`// trash.DeleteRelation
+				MATCH (a)-[t:TRASHED_CONCERN]-(b)
+			WHERE (a:Word OR a:TrashedWord) AND a.id = $w1Id AND b.id = $w2Id
+				AND t.trashedWith IS NULL
+			DELETE t;`,
+		},
+		map[string]interface{}{
+			"w1Id": w1Id,
+			"w2Id": w2Id,
+		},
+	)
+}
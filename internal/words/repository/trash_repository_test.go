@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/datasource/datasourcetest"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+func TestTrashedLinkRestoredAfterRelinking(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := context.Background()
+		w1 := b.storeWord(t, "rain", nil)
+		w2 := b.storeWord(t, "flood", &w1)
+		old := b.storeLink(t, w1, w2, "causes")
+
+		if err := b.trash.TrashRelation(ctx, w1, w2, "u1"); err != nil {
+			t.Fatal(err)
+		}
+		// the words are related and linked again
+		if err := b.word.StoreRelation(ctx, w1, w2); err != nil {
+			t.Fatal(err)
+		}
+		relinked := b.storeLink(t, w1, w2, "feeds")
+		if relinked == old {
+			t.Fatal("Store() reused the id of the trashed link")
+		}
+
+		restored, err := b.trash.RestoreRelation(ctx, w1, w2)
+		if err != nil || !restored {
+			t.Fatalf("RestoreRelation() = %v, %v", restored, err)
+		}
+		for id, content := range map[string]string{old: "causes", relinked: "feeds"} {
+			l, err := b.link.FindById(ctx, id)
+			if err != nil || l == nil || l.Content != content {
+				t.Fatalf("FindById(%s) = %+v, %v, want %q", id, l, err, content)
+			}
+		}
+	})
+}
+
+// neo4j keeps the trashed relationships next to the live ones,
+// a link is stored on the live relationships only
+func TestLinkStoreMatchesTheLiveRelationships(t *testing.T) {
+	db := datasourcetest.NewFake()
+	db.Handlers["link.Store"] = func(params map[string]any) []map[string]any {
+		return []map[string]any{{"id": params["id"]}}
+	}
+	if _, err := InitLinkRepository(db).Store(context.Background(), domain.Link{UserId: "u1", Word1Id: "w1", Word2Id: "w2", Content: "causes"}); err != nil {
+		t.Fatal(err)
+	}
+	if !regexp.MustCompile(`MATCH \(w1\)-\[r:CONCERN\]-\(w2\)`).MatchString(db.Statements["link.Store"]) {
+		t.Fatalf("link.Store matches the trashed relationships:\n%s", db.Statements["link.Store"])
+	}
+}
+
+func TestTrashDeleteRemovesTheRevisions(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := context.Background()
+		w1 := b.storeWord(t, "rain", nil)
+		w2 := b.storeWord(t, "flood", &w1)
+		w3 := b.storeWord(t, "cloud", &w1)
+		l12 := b.storeLink(t, w1, w2, "causes")
+		l13 := b.storeLink(t, w1, w3, "comes from")
+		for _, r := range []domain.Revision{
+			{EntityType: domain.RevisionEntityWord, EntityId: w1},
+			{EntityType: domain.RevisionEntityWord, EntityId: w2},
+			{EntityType: domain.RevisionEntityLink, EntityId: l12},
+			{EntityType: domain.RevisionEntityLink, EntityId: l13},
+		} {
+			r.UserId, r.Content, r.CreatedAt = "u1", "before", time.Now()
+			if _, err := b.revision.Store(ctx, r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		revisions := func(entityType domain.RevisionEntity, id string) int {
+			rs, err := b.revision.FindByEntity(ctx, entityType, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return len(rs)
+		}
+
+		if err := b.trash.TrashWord(ctx, w2, "u1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.trash.DeleteWord(ctx, w2); err != nil {
+			t.Fatal(err)
+		}
+		if revisions(domain.RevisionEntityWord, w2) != 0 || revisions(domain.RevisionEntityLink, l12) != 0 {
+			t.Fatal("DeleteWord() kept the revisions of the word and its link")
+		}
+
+		if err := b.trash.TrashRelation(ctx, w1, w3, "u1"); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.trash.DeleteRelation(ctx, w1, w3); err != nil {
+			t.Fatal(err)
+		}
+		if revisions(domain.RevisionEntityLink, l13) != 0 {
+			t.Fatal("DeleteRelation() kept the revisions of the link")
+		}
+		if revisions(domain.RevisionEntityWord, w1) != 1 {
+			t.Fatal("the revisions of the word left out of the trash were deleted")
+		}
+	})
+}
+
+func TestTrashDeleteRemovesTheRevisionsOnNeo4j(t *testing.T) {
+	db := datasourcetest.NewFake()
+	repo := InitTrashRepository(db)
+	if err := repo.DeleteWord(context.Background(), "w1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.DeleteRelation(context.Background(), "w1", "w2"); err != nil {
+		t.Fatal(err)
+	}
+	// the revisions are found from the trashed nodes, before they are deleted
+	want := []string{
+		"trash.DeleteWord.revisions", "trash.DeleteWord.links", "trash.DeleteWord",
+		"trash.DeleteRelation.revisions", "trash.DeleteRelation.links", "trash.DeleteRelation",
+	}
+	if !slices.Equal(db.Queries, want) {
+		t.Fatalf("queries = %v, want %v", db.Queries, want)
+	}
+}
@@ -0,0 +1,341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type trashSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitTrashSQLiteRepository(db *datasource.SQLite) domain.TrashRepository {
+	return &trashSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+// the relationships between the two words, given as ?1 and ?2
+const relationPair = `((start_id = ?1 AND end_id = ?2) OR (start_id = ?2 AND end_id = ?1))`
+
+func scanTrashedWord(rows *sql.Rows) (domain.TrashedWord, error) {
+	w := domain.TrashedWord{}
+	var refs sql.NullString
+	err := rows.Scan(&w.Id, &w.GraphId, &w.UserId, &w.Content, &w.Description, &refs, &w.CreatedAt, &w.UpdatedAt, &w.DeletedBy, &w.DeletedAt)
+	w.Refs = jsonToRefs(refs)
+	return w, err
+}
+
+// Run the statements one after the other in a transaction
+func (repo *trashSQLiteRepository) execAll(ctx context.Context, statements []string, args ...any) error {
+	return repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		for _, s := range statements {
+			if _, err := repo.Datasource.ExecWrite(ctx, s, args...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (repo *trashSQLiteRepository) FindByGraphId(ctx context.Context, graphId string) (*domain.Trash, error) {
+	trash := &domain.Trash{Words: []domain.TrashedWord{}, Relations: []domain.TrashedRelation{}}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- trash.FindByGraphId.words
+			SELECT `+wordColumns+`, deleted_by, deleted_at FROM trashed_words
+		WHERE graph_id = ?
+		ORDER BY deleted_at DESC;`,
+		func(rows *sql.Rows) error {
+			w, err := scanTrashedWord(rows)
+			trash.Words = append(trash.Words, w)
+			return err
+		},
+		graphId,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	linkIds := []string{}
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- trash.FindByGraphId.relations
+			WITH ids(id) AS (
+			SELECT id FROM words WHERE graph_id = ?1
+			UNION
+			SELECT id FROM trashed_words WHERE graph_id = ?1
+		)
+		SELECT start_id, end_id, link_id, deleted_by, deleted_at FROM trashed_relations
+		WHERE trashed_with IS NULL
+			AND (start_id IN (SELECT id FROM ids) OR end_id IN (SELECT id FROM ids))
+		ORDER BY deleted_at DESC, id DESC;`,
+		func(rows *sql.Rows) error {
+			r := domain.TrashedRelation{}
+			var linkId sql.NullString
+			err := rows.Scan(&r.SourceId, &r.TargetId, &linkId, &r.DeletedBy, &r.DeletedAt)
+			if linkId.Valid {
+				r.Link = &domain.Link{Id: linkId.String}
+				linkIds = append(linkIds, linkId.String)
+			}
+			trash.Relations = append(trash.Relations, r)
+			return err
+		},
+		graphId,
+	)
+	if err != nil || len(linkIds) == 0 {
+		return trash, err
+	}
+
+	_ids, _ := json.Marshal(linkIds)
+	links := map[string]domain.Link{}
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- trash.FindByGraphId.links
+			SELECT `+linkColumns+` FROM trashed_links
+		WHERE id IN (SELECT value FROM json_each(?));`,
+		func(rows *sql.Rows) error {
+			l, err := scanLink(rows)
+			links[l.Id] = l
+			return err
+		},
+		string(_ids),
+	)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range trash.Relations {
+		if r.Link == nil {
+			continue
+		}
+		if l, ok := links[r.Link.Id]; ok {
+			trash.Relations[i].Link = &l
+		} else {
+			trash.Relations[i].Link = nil
+		}
+	}
+	return trash, nil
+}
+
+func (repo *trashSQLiteRepository) FindWordById(ctx context.Context, id string) (res *domain.TrashedWord, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		`-- trash.FindWordById
+			SELECT `+wordColumns+`, deleted_by, deleted_at FROM trashed_words WHERE id = ?;`,
+		func(rows *sql.Rows) error {
+			w, err := scanTrashedWord(rows)
+			res = &w
+			return err
+		},
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *trashSQLiteRepository) TrashWord(ctx context.Context, id string, userId string) error {
+	now := time.Now()
+	return repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashWord.links
+				INSERT INTO trashed_links (`+linkColumns+`)
+			SELECT `+linkColumns+` FROM links
+			WHERE id IN (SELECT link_id FROM relations WHERE start_id = ?1 OR end_id = ?1);`,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashWord.relations
+				INSERT INTO trashed_relations (start_id, end_id, link_id, trashed_with, deleted_by, deleted_at)
+			SELECT start_id, end_id, link_id, ?1, ?2, ?3 FROM relations
+			WHERE start_id = ?1 OR end_id = ?1;`,
+			id, userId, now,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashWord.word
+				INSERT INTO trashed_words (`+wordColumns+`, deleted_by, deleted_at)
+			SELECT `+wordColumns+`, ?2, ?3 FROM words
+			WHERE id = ?1;`,
+			id, userId, now,
+		)
+		if err != nil {
+			return err
+		}
+		// the relationships and links of the word are removed with it by the foreign keys
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashWord.delete
+				DELETE FROM words WHERE id = ?;`,
+			id,
+		)
+		return err
+	})
+}
+
+func (repo *trashSQLiteRepository) TrashRelation(ctx context.Context, w1Id string, w2Id string, userId string) error {
+	return repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashRelation.links
+				INSERT INTO trashed_links (`+linkColumns+`)
+			SELECT `+linkColumns+` FROM links
+			WHERE id IN (SELECT link_id FROM relations WHERE `+relationPair+`);`,
+			w1Id, w2Id,
+		)
+		if err != nil {
+			return err
+		}
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- trash.TrashRelation.relations
+				INSERT INTO trashed_relations (start_id, end_id, link_id, deleted_by, deleted_at)
+			SELECT start_id, end_id, link_id, ?3, ?4 FROM relations
+			WHERE `+relationPair+`;`,
+			w1Id, w2Id, userId, time.Now(),
+		)
+		if err != nil {
+			return err
+		}
+		return repo.execAll(
+			ctx,
+			[]string{
+				`-- trash.TrashRelation.deleteLinks
+					DELETE FROM links
+				WHERE id IN (SELECT link_id FROM relations WHERE ` + relationPair + `);`,
+				`-- trash.TrashRelation.delete
+					DELETE FROM relations WHERE ` + relationPair + `;`,
+			},
+			w1Id, w2Id,
+		)
+	})
+}
+
+// the relationships trashed with the word ?1 whose both ends are words again
+const restorableRelations = `SELECT id FROM trashed_relations
+			WHERE trashed_with = ?1
+				AND start_id IN (SELECT id FROM words)
+				AND end_id IN (SELECT id FROM words)`
+
+func (repo *trashSQLiteRepository) RestoreWord(ctx context.Context, id string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`-- trash.RestoreWord.word
+				INSERT INTO words (` + wordColumns + `)
+			SELECT ` + wordColumns + ` FROM trashed_words WHERE id = ?1;`,
+			`-- trash.RestoreWord.relations
+				INSERT INTO relations (start_id, end_id, link_id)
+			SELECT start_id, end_id, link_id FROM trashed_relations
+			WHERE id IN (` + restorableRelations + `)
+			ORDER BY id;`,
+			`-- trash.RestoreWord.links
+				INSERT INTO links (` + linkColumns + `)
+			SELECT ` + linkColumns + ` FROM trashed_links
+			WHERE id IN (SELECT link_id FROM trashed_relations WHERE id IN (` + restorableRelations + `));`,
+			`-- trash.RestoreWord.deleteLinks
+				DELETE FROM trashed_links
+			WHERE id IN (SELECT link_id FROM trashed_relations WHERE id IN (` + restorableRelations + `));`,
+			`-- trash.RestoreWord.deleteRelations
+				DELETE FROM trashed_relations WHERE id IN (` + restorableRelations + `);`,
+			// the relationships going to a word still in the trash come back with it
+			`-- trash.RestoreWord.handOver
+				UPDATE trashed_relations
+			SET trashed_with = CASE WHEN start_id = ?1 THEN end_id ELSE start_id END
+			WHERE trashed_with = ?1;`,
+			`-- trash.RestoreWord.delete
+				DELETE FROM trashed_words WHERE id = ?1;`,
+		},
+		id,
+	)
+}
+
+func (repo *trashSQLiteRepository) RestoreRelation(ctx context.Context, w1Id string, w2Id string) (bool, error) {
+	var relationId int64
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- trash.RestoreRelation.latest
+			SELECT id FROM trashed_relations
+		WHERE trashed_with IS NULL AND `+relationPair+`
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT 1;`,
+		func(rows *sql.Rows) error {
+			return rows.Scan(&relationId)
+		},
+		w1Id, w2Id,
+	)
+	if err != nil || relationId == 0 {
+		return false, err
+	}
+
+	err = repo.execAll(
+		ctx,
+		[]string{
+			`-- trash.RestoreRelation.relation
+				INSERT INTO relations (start_id, end_id, link_id)
+			SELECT start_id, end_id, link_id FROM trashed_relations WHERE id = ?1;`,
+			`-- trash.RestoreRelation.link
+				INSERT INTO links (` + linkColumns + `)
+			SELECT ` + linkColumns + ` FROM trashed_links
+			WHERE id = (SELECT link_id FROM trashed_relations WHERE id = ?1);`,
+			`-- trash.RestoreRelation.deleteLink
+				DELETE FROM trashed_links
+			WHERE id = (SELECT link_id FROM trashed_relations WHERE id = ?1);`,
+			`-- trash.RestoreRelation.delete
+				DELETE FROM trashed_relations WHERE id = ?1;`,
+		},
+		relationId,
+	)
+	return err == nil, err
+}
+
+func (repo *trashSQLiteRepository) DeleteWord(ctx context.Context, id string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`-- trash.DeleteWord.revisions
+				DELETE FROM revisions
+			WHERE entity_id = ?1
+				OR entity_id IN (SELECT link_id FROM trashed_relations WHERE start_id = ?1 OR end_id = ?1);`,
+			`-- trash.DeleteWord.links
+				DELETE FROM trashed_links
+			WHERE id IN (SELECT link_id FROM trashed_relations WHERE start_id = ?1 OR end_id = ?1);`,
+			`-- trash.DeleteWord.relations
+				DELETE FROM trashed_relations WHERE start_id = ?1 OR end_id = ?1;`,
+			`-- trash.DeleteWord
+				DELETE FROM trashed_words WHERE id = ?1;`,
+		},
+		id,
+	)
+}
+
+func (repo *trashSQLiteRepository) DeleteRelation(ctx context.Context, w1Id string, w2Id string) error {
+	return repo.execAll(
+		ctx,
+		[]string{
+			`-- trash.DeleteRelation.revisions
+				DELETE FROM revisions
+			WHERE entity_id IN (SELECT link_id FROM trashed_relations WHERE trashed_with IS NULL AND ` + relationPair + `);`,
+			`-- trash.DeleteRelation.links
+				DELETE FROM trashed_links
+			WHERE id IN (SELECT link_id FROM trashed_relations WHERE trashed_with IS NULL AND ` + relationPair + `);`,
+			`-- trash.DeleteRelation
+				DELETE FROM trashed_relations WHERE trashed_with IS NULL AND ` + relationPair + `;`,
+		},
+		w1Id, w2Id,
+	)
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// wordCachedRepository reads the graph data through the cache,
+// the other calls go to the wrapped repository
+type wordCachedRepository struct {
+	domain.WordRepository
+	cache *GraphDataCache
+}
+
+func InitWordCachedRepository(repo domain.WordRepository, cache *GraphDataCache) domain.WordRepository {
+	return &wordCachedRepository{
+		WordRepository: repo,
+		cache:          cache,
+	}
+}
+
+func (r *wordCachedRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Word, []domain.WordsLink, error) {
+	data := domain.WordsGraphData{}
+	generation, ok := r.cache.get(ctx, cacheGraphData, graphId, &data)
+	if ok {
+		return data.Words, data.Links, nil
+	}
+
+	words, links, err := r.WordRepository.FindByGraphId(ctx, graphId)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.cache.set(ctx, cacheGraphData, graphId, generation, domain.WordsGraphData{
+		Words: words,
+		Links: links,
+	})
+	return words, links, nil
+}
@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// wordIndex keeps the folded contents of the words of the recently used graphs
+// in memory, sorted for the prefix lookups
+type wordIndex struct {
+	graphs *graphIndexes[*graphWordIndex]
+}
+
+// size is the number of graphs kept, 0 builds the index on every call
+func InitWordIndex(repo domain.WordRepository, cache *GraphDataCache, size int) domain.WordIndex {
+	return &wordIndex{
+		graphs: initGraphIndexes(cacheWordIndex, repo, cache, size, buildGraphWordIndex),
+	}
+}
+
+func (i *wordIndex) Suggest(ctx context.Context, graphId string, text string, limit int) ([]domain.WordSuggestion, error) {
+	index, err := i.graphs.get(ctx, graphId)
+	if err != nil {
+		return nil, err
+	}
+	return index.suggest(text, limit), nil
+}
+
+type indexedWord struct {
+	id      string
+	content string
+	degree  int
+}
+
+// the folded content of a word from one of its words to the end
+type indexedTerm struct {
+	text  string
+	runes []rune
+	word  int
+	// whether the term is the whole content
+	start bool
+}
+
+type graphWordIndex struct {
+	words []indexedWord
+	// sorted by text
+	terms []indexedTerm
+}
+
+func buildGraphWordIndex(words []domain.Word, links []domain.WordsLink) *graphWordIndex {
+	degrees := map[string]int{}
+	for _, l := range links {
+		degrees[l.SourceId]++
+		degrees[l.TargetId]++
+	}
+
+	index := &graphWordIndex{}
+	for _, w := range words {
+		index.words = append(index.words, indexedWord{
+			id:      w.Id,
+			content: w.Content,
+			degree:  degrees[w.Id],
+		})
+		folded := []rune(strings.Join(strings.Fields(common.Fold(w.Content)), " "))
+		for start := range folded {
+			if start > 0 && (!isWordRune(folded[start]) || isWordRune(folded[start-1])) {
+				continue
+			}
+			index.terms = append(index.terms, indexedTerm{
+				text:  string(folded[start:]),
+				runes: folded[start:],
+				word:  len(index.words) - 1,
+				start: start == 0,
+			})
+		}
+	}
+	sort.Slice(index.terms, func(a, b int) bool {
+		return index.terms[a].text < index.terms[b].text
+	})
+	return index
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r)
+}
+
+// best match of each word, the lower the better
+type suggestCandidate struct {
+	match    domain.SuggestMatch
+	distance int
+}
+
+var suggestMatchRank = map[domain.SuggestMatch]int{
+	domain.SuggestMatchExact:  0,
+	domain.SuggestMatchPrefix: 1,
+	domain.SuggestMatchWord:   2,
+	domain.SuggestMatchFuzzy:  3,
+}
+
+func (c suggestCandidate) better(o suggestCandidate) bool {
+	if suggestMatchRank[c.match] != suggestMatchRank[o.match] {
+		return suggestMatchRank[c.match] < suggestMatchRank[o.match]
+	}
+	return c.distance < o.distance
+}
+
+// typos allowed in a typed text of n runes
+func maxTypos(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+func (index *graphWordIndex) suggest(text string, limit int) []domain.WordSuggestion {
+	query := strings.Join(strings.Fields(common.Fold(text)), " ")
+	if query == "" {
+		return []domain.WordSuggestion{}
+	}
+	candidates := map[int]suggestCandidate{}
+	add := func(word int, c suggestCandidate) {
+		if old, ok := candidates[word]; !ok || c.better(old) {
+			candidates[word] = c
+		}
+	}
+
+	from := sort.Search(len(index.terms), func(i int) bool {
+		return index.terms[i].text >= query
+	})
+	for _, t := range index.terms[from:] {
+		if !strings.HasPrefix(t.text, query) {
+			break
+		}
+		switch {
+		case t.start && t.text == query:
+			add(t.word, suggestCandidate{match: domain.SuggestMatchExact})
+		case t.start:
+			add(t.word, suggestCandidate{match: domain.SuggestMatchPrefix})
+		default:
+			add(t.word, suggestCandidate{match: domain.SuggestMatchWord})
+		}
+	}
+	// the typos are looked for when the prefixes are not enough
+	if typos := maxTypos(len([]rune(query))); len(candidates) < limit && typos > 0 {
+		index.fuzzy([]rune(query), typos, func(term int, distance int) {
+			add(index.terms[term].word, suggestCandidate{match: domain.SuggestMatchFuzzy, distance: distance})
+		})
+	}
+
+	order := make([]int, 0, len(candidates))
+	for w := range candidates {
+		order = append(order, w)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		ca, cb := candidates[order[a]], candidates[order[b]]
+		if ca.better(cb) || cb.better(ca) {
+			return ca.better(cb)
+		}
+		wa, wb := index.words[order[a]], index.words[order[b]]
+		if wa.degree != wb.degree {
+			return wa.degree > wb.degree
+		}
+		if len(wa.content) != len(wb.content) {
+			return len(wa.content) < len(wb.content)
+		}
+		if wa.content != wb.content {
+			return wa.content < wb.content
+		}
+		return wa.id < wb.id
+	})
+	if len(order) > limit {
+		order = order[:limit]
+	}
+
+	res := []domain.WordSuggestion{}
+	for _, w := range order {
+		res = append(res, domain.WordSuggestion{
+			Id:       index.words[w].id,
+			Content:  index.words[w].content,
+			Match:    candidates[w].match,
+			Distance: candidates[w].distance,
+			Degree:   index.words[w].degree,
+		})
+	}
+	return res
+}
+
+// Call found with the terms starting with the query give or take typos edits,
+// insertions, deletions, substitutions and transpositions of two runes.
+// The terms being sorted, the rows of the edit distances of the prefix
+// they share with the previous term are kept, like walking down a trie.
+func (index *graphWordIndex) fuzzy(query []rune, typos int, found func(term int, distance int)) {
+	m := len(query)
+	depth := m + typos
+	// rows[d][j] is the distance between the d first runes of the term and the j first of the query
+	rows := make([][]int, depth+1)
+	for d := range rows {
+		rows[d] = make([]int, m+1)
+		rows[d][0] = d
+	}
+	for j := 0; j <= m; j++ {
+		rows[0][j] = j
+	}
+
+	var previous []rune
+	computed := 0
+	for i := 0; i < len(index.terms); {
+		term := index.terms[i].runes
+		shared := 0
+		for shared < computed && shared < len(term) && shared < len(previous) && term[shared] == previous[shared] {
+			shared++
+		}
+
+		best := -1
+		for d := 1; d <= shared; d++ {
+			if rows[d][m] <= typos && (best < 0 || rows[d][m] < best) {
+				best = rows[d][m]
+			}
+		}
+		// past this many runes no edit keeps the distance low enough
+		dead := 0
+		d := shared + 1
+		for ; d <= len(term) && d <= depth; d++ {
+			low := rows[d][0]
+			for j := 1; j <= m; j++ {
+				cost := 1
+				if term[d-1] == query[j-1] {
+					cost = 0
+				}
+				v := min(rows[d-1][j]+1, rows[d][j-1]+1, rows[d-1][j-1]+cost)
+				if d > 1 && j > 1 && term[d-1] == query[j-2] && term[d-2] == query[j-1] {
+					v = min(v, rows[d-2][j-2]+1)
+				}
+				rows[d][j] = v
+				low = min(low, v)
+			}
+			if rows[d][m] <= typos && (best < 0 || rows[d][m] < best) {
+				best = rows[d][m]
+			}
+			if low > typos {
+				dead = d
+				break
+			}
+		}
+		if dead > 0 {
+			computed = dead - 1
+		} else {
+			computed = d - 1
+		}
+		previous = term
+
+		if best >= 0 {
+			found(i, best)
+		}
+		i++
+		if dead == 0 {
+			continue
+		}
+		// the terms sharing the dead prefix match as this one did
+		for ; i < len(index.terms) && hasRunePrefix(index.terms[i].runes, term[:dead]); i++ {
+			if best >= 0 {
+				found(i, best)
+			}
+		}
+	}
+}
+
+func hasRunePrefix(s []rune, prefix []rune) bool {
+	if len(s) < len(prefix) {
+		return false
+	}
+	for i, r := range prefix {
+		if s[i] != r {
+			return false
+		}
+	}
+	return true
+}
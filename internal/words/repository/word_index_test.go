@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+var indexVocabulary = []string{
+	"rain", "rainbow", "raining season", "brain", "train", "grain",
+	"flood", "floor", "flow", "flower", "fold",
+	"Élan vital", "élite", "Straße", "cafe au lait",
+	"acid rain", "arid", "radar",
+}
+
+// Optimal string alignment distance, the edits counted by fuzzy
+func damerau(a []rune, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// Distance of each term to the query by the closest of its prefixes
+func bruteFuzzy(index *graphWordIndex, query []rune, typos int) map[int]int {
+	found := map[int]int{}
+	for i, t := range index.terms {
+		best := -1
+		for d := 1; d <= len(t.runes); d++ {
+			if v := damerau(t.runes[:d], query); v <= typos && (best < 0 || v < best) {
+				best = v
+			}
+		}
+		if best >= 0 {
+			found[i] = best
+		}
+	}
+	return found
+}
+
+func TestWordIndexFuzzyMatchesTheBruteForce(t *testing.T) {
+	words := []domain.Word{}
+	for i, c := range indexVocabulary {
+		words = append(words, domain.Word{Id: fmt.Sprint("w", i), Content: c})
+	}
+	index := buildGraphWordIndex(words, nil)
+
+	queries := []string{"rian", "rayn", "brian", "trian", "folod", "flwo", "flowre", "elna", "strasse", "lait", "acdi", "zzz", "radra"}
+	// the prefixes of the vocabulary, with their first runes swapped or dropped
+	for _, c := range indexVocabulary {
+		r := []rune(c)
+		for n := 3; n <= len(r) && n <= 7; n++ {
+			p := slices.Clone(r[:n])
+			queries = append(queries, string(p), string(p[1:]))
+			p[0], p[1] = p[1], p[0]
+			queries = append(queries, string(p))
+		}
+	}
+
+	for _, q := range queries {
+		query := []rune(q)
+		for typos := 1; typos <= 2; typos++ {
+			got := map[int]int{}
+			index.fuzzy(query, typos, func(term int, distance int) {
+				if _, ok := got[term]; ok {
+					t.Errorf("fuzzy(%q, %d) found the term %q twice", q, typos, index.terms[term].text)
+				}
+				got[term] = distance
+			})
+			if want := bruteFuzzy(index, query, typos); !maps.Equal(got, want) {
+				t.Errorf("fuzzy(%q, %d) = %v, want %v", q, typos, got, want)
+			}
+		}
+	}
+}
+
+func TestWordIndexSuggest(t *testing.T) {
+	words := []domain.Word{
+		{Id: "rain", Content: "Rain"},
+		{Id: "rainbow", Content: "rainbow"},
+		{Id: "acid", Content: "acid rain"},
+		{Id: "elan", Content: "Élan vital"},
+		{Id: "flood", Content: "flood"},
+		{Id: "floor", Content: "floor"},
+	}
+	// floor is linked twice, flood once
+	links := []domain.WordsLink{
+		{SourceId: "floor", TargetId: "rain"},
+		{SourceId: "floor", TargetId: "acid"},
+		{SourceId: "flood", TargetId: "rainbow"},
+	}
+	index := buildGraphWordIndex(words, links)
+
+	type match struct {
+		id       string
+		match    domain.SuggestMatch
+		distance int
+	}
+	for _, test := range []struct {
+		name  string
+		text  string
+		limit int
+		want  []match
+	}{
+		{"exact, prefix then word", "rain", 10, []match{
+			{"rain", domain.SuggestMatchExact, 0},
+			{"rainbow", domain.SuggestMatchPrefix, 0},
+			{"acid", domain.SuggestMatchWord, 0},
+		}},
+		{"limit", "rain", 2, []match{
+			{"rain", domain.SuggestMatchExact, 0},
+			{"rainbow", domain.SuggestMatchPrefix, 0},
+		}},
+		{"transposition", "rian", 10, []match{
+			{"rain", domain.SuggestMatchFuzzy, 1},
+			{"rainbow", domain.SuggestMatchFuzzy, 1},
+			{"acid", domain.SuggestMatchFuzzy, 1},
+		}},
+		{"folded text", "  ÉLAN   Vi", 10, []match{
+			{"elan", domain.SuggestMatchPrefix, 0},
+		}},
+		{"accents ignored", "elan vital", 10, []match{
+			{"elan", domain.SuggestMatchExact, 0},
+		}},
+		{"ranked by degree", "flo", 10, []match{
+			{"floor", domain.SuggestMatchPrefix, 0},
+			{"flood", domain.SuggestMatchPrefix, 0},
+		}},
+		{"one typo under 6 runes", "fxood", 10, []match{
+			{"flood", domain.SuggestMatchFuzzy, 1},
+		}},
+		{"past the typos", "fxxod", 10, []match{}},
+		{"two typos from 6 runes", "rxinbxw", 10, []match{
+			{"rainbow", domain.SuggestMatchFuzzy, 2},
+		}},
+		{"no typos under 3 runes", "ri", 10, []match{}},
+		{"blank", "  ", 10, []match{}},
+	} {
+		got := []match{}
+		for _, s := range index.suggest(test.text, test.limit) {
+			got = append(got, match{s.Id, s.Match, s.Distance})
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("suggest(%q) %s = %v, want %v", test.text, test.name, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,354 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type wordMemoryRepository struct {
+	Datasource *datasource.Memory
+}
+
+func InitWordMemoryRepository(db *datasource.Memory) domain.WordRepository {
+	return &wordMemoryRepository{
+		Datasource: db,
+	}
+}
+
+func copyWord(w *domain.Word) domain.Word {
+	res := *w
+	if w.Refs != nil {
+		res.Refs = common.ToPointer(append([]string{}, *w.Refs...))
+	}
+	return res
+}
+
+func (repo *wordMemoryRepository) Store(ctx context.Context, w domain.Word, graphId string, linkWordId *string) (*string, error) {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Users[w.UserId] == nil || db.Graphs[graphId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+	if linkWordId != nil && db.Words[*linkWordId] == nil {
+		return nil, common.ErrInternalServerError
+	}
+
+	word := copyWord(&w)
+	word.Id = common.NewId()
+	word.GraphId = graphId
+	word.CreatedAt = common.ToPointer(time.Now())
+	word.UpdatedAt = nil
+	db.Words[word.Id] = &word
+
+	if linkWordId != nil {
+		db.Relations = append(db.Relations, &datasource.MemoryRelation{
+			StartId: word.Id,
+			EndId:   *linkWordId,
+		})
+	}
+	return &word.Id, nil
+}
+
+func (repo *wordMemoryRepository) Update(ctx context.Context, w domain.Word) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	word := db.Words[w.Id]
+	if word == nil {
+		return nil
+	}
+	updated := copyWord(&w)
+	word.Content = updated.Content
+	word.Description = updated.Description
+	word.Refs = updated.Refs
+	word.UpdatedAt = common.ToPointer(time.Now())
+	return nil
+}
+
+func (repo *wordMemoryRepository) Delete(ctx context.Context, id string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	// remove word and links
+	if db.Words[id] == nil {
+		return nil
+	}
+	delete(db.Words, id)
+	for lId, l := range db.Links {
+		if l.Word1Id == id || l.Word2Id == id {
+			delete(db.Links, lId)
+		}
+	}
+	rels := []*datasource.MemoryRelation{}
+	for _, r := range db.Relations {
+		if r.StartId != id && r.EndId != id {
+			rels = append(rels, r)
+		}
+	}
+	db.Relations = rels
+	return nil
+}
+
+func (repo *wordMemoryRepository) FindById(ctx context.Context, id string) (*domain.Word, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	w := db.Words[id]
+	if w == nil {
+		return nil, nil
+	}
+	return common.ToPointer(copyWord(w)), nil
+}
+
+func (repo *wordMemoryRepository) FindByRandomId(ctx context.Context) (*domain.Word, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	if len(db.Words) == 0 {
+		return nil, nil
+	}
+	i := rand.Intn(len(db.Words))
+	for _, w := range db.Words {
+		if i == 0 {
+			return common.ToPointer(copyWord(w)), nil
+		}
+		i--
+	}
+	return nil, nil
+}
+
+func (repo *wordMemoryRepository) FindByIds(ctx context.Context, ids []string) ([]domain.Word, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	words := []domain.Word{}
+	for _, id := range ids {
+		if w := db.Words[id]; w != nil {
+			words = append(words, copyWord(w))
+		}
+	}
+	return words, nil
+}
+
+func (repo *wordMemoryRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Word, []domain.WordsLink, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	words := []domain.Word{}
+	links := []domain.WordsLink{}
+	if db.Graphs[graphId] == nil {
+		return words, links, nil
+	}
+
+	for _, w := range db.Words {
+		if w.GraphId == graphId {
+			words = append(words, copyWord(w))
+		}
+	}
+	sortWords(words)
+
+	for _, r := range db.Relations {
+		w1, w2 := db.Words[r.StartId], db.Words[r.EndId]
+		if (w1 != nil && w1.GraphId == graphId) || (w2 != nil && w2.GraphId == graphId) {
+			links = append(links, domain.WordsLink{
+				SourceId: r.StartId,
+				TargetId: r.EndId,
+			})
+		}
+	}
+	return words, links, nil
+}
+
+func (repo *wordMemoryRepository) FindNeighborIds(ctx context.Context, id string, depth int) ([]domain.WordsLink, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	res := []domain.WordsLink{}
+	if db.Words[id] == nil {
+		return res, nil
+	}
+
+	// a relationship is on a path of at most depth hops
+	// when one of its ends is reached in less than depth hops
+	dist := map[string]int{id: 0}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if dist[cur] >= depth {
+			continue
+		}
+		for _, r := range db.Relations {
+			next := ""
+			if r.StartId == cur {
+				next = r.EndId
+			} else if r.EndId == cur {
+				next = r.StartId
+			} else {
+				continue
+			}
+			if _, ok := dist[next]; !ok {
+				dist[next] = dist[cur] + 1
+				queue = append(queue, next)
+			}
+		}
+	}
+
+	for _, r := range db.Relations {
+		d1, ok1 := dist[r.StartId]
+		d2, ok2 := dist[r.EndId]
+		if (ok1 && d1 < depth) || (ok2 && d2 < depth) {
+			res = append(res, domain.WordsLink{
+				SourceId: r.StartId,
+				TargetId: r.EndId,
+			})
+		}
+	}
+	return res, nil
+}
+
+func (repo *wordMemoryRepository) Search(ctx context.Context, search string, graphs domain.WordSearchGraphs, offset int, limit int) ([]domain.WordSearchHit, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	inGraphs := map[string]bool{}
+	for _, id := range graphs.Ids {
+		inGraphs[id] = true
+	}
+	terms := common.Tokenize(search)
+	hits := []domain.WordSearchHit{}
+	for _, w := range db.Words {
+		g := db.Graphs[w.GraphId]
+		if g == nil || g.DeleteFlag {
+			continue
+		}
+		if !graphs.All && !inGraphs[g.Id] && !(graphs.Public && g.Visibility == domain.GraphVisibilityPublic) {
+			continue
+		}
+		text := w.Content
+		if w.Description != nil {
+			text += " " + *w.Description
+		}
+		tokens := common.Tokenize(text)
+		if len(tokens) == 0 {
+			continue
+		}
+		matched := 0
+		for _, t := range tokens {
+			for _, term := range terms {
+				if t == term {
+					matched++
+				}
+			}
+		}
+		if matched > 0 {
+			hits = append(hits, domain.WordSearchHit{
+				Word:  copyWord(w),
+				Score: float64(matched) / math.Sqrt(float64(len(tokens))),
+			})
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Id < hits[j].Id
+	})
+
+	if offset >= len(hits) {
+		return []domain.WordSearchHit{}, nil
+	}
+	hits = hits[offset:]
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+func (repo *wordMemoryRepository) FindPath(ctx context.Context, fromId string, toId string) ([]domain.Word, []domain.WordsLink, error) {
+	db := repo.Datasource
+	defer db.ReadLock(ctx)()
+
+	if db.Words[fromId] == nil || db.Words[toId] == nil {
+		return []domain.Word{}, []domain.WordsLink{}, nil
+	}
+
+	// breadth first search, keeping the relationship used to reach each word
+	prev := map[string]*datasource.MemoryRelation{fromId: nil}
+	queue := []string{fromId}
+	for len(queue) > 0 && prev[toId] == nil && fromId != toId {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, r := range db.Relations {
+			next := ""
+			if r.StartId == cur {
+				next = r.EndId
+			} else if r.EndId == cur {
+				next = r.StartId
+			} else {
+				continue
+			}
+			if _, ok := prev[next]; !ok {
+				prev[next] = r
+				queue = append(queue, next)
+			}
+		}
+	}
+	if _, ok := prev[toId]; !ok {
+		return []domain.Word{}, []domain.WordsLink{}, nil
+	}
+
+	words := []domain.Word{}
+	links := []domain.WordsLink{}
+	cur := toId
+	for {
+		words = append([]domain.Word{copyWord(db.Words[cur])}, words...)
+		r := prev[cur]
+		if r == nil {
+			break
+		}
+		links = append([]domain.WordsLink{{SourceId: r.StartId, TargetId: r.EndId}}, links...)
+		if r.StartId == cur {
+			cur = r.EndId
+		} else {
+			cur = r.StartId
+		}
+	}
+	return words, links, nil
+}
+
+func (repo *wordMemoryRepository) StoreRelation(ctx context.Context, sourceId string, targetId string) error {
+	db := repo.Datasource
+	defer db.WriteLock(ctx)()
+
+	if db.Words[sourceId] == nil || db.Words[targetId] == nil {
+		return nil
+	}
+	db.Relations = append(db.Relations, &datasource.MemoryRelation{
+		StartId: sourceId,
+		EndId:   targetId,
+	})
+	return nil
+}
+
+func (repo *wordMemoryRepository) Lock(ctx context.Context, ids []string) error {
+	// transactions already hold the lock of the whole store
+	return nil
+}
+
+func sortWords(words []domain.Word) {
+	sort.SliceStable(words, func(i, j int) bool {
+		if words[i].CreatedAt == nil || words[j].CreatedAt == nil {
+			return words[i].Id < words[j].Id
+		}
+		return words[i].CreatedAt.Before(*words[j].CreatedAt)
+	})
+}
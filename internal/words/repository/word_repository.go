@@ -1,7 +1,9 @@
 package repository
 
 import (
+	"context"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
@@ -13,10 +15,10 @@ import (
 )
 
 type wordRepository struct {
-	Datasource *datasource.Neo4J
+	Datasource datasource.Datasource
 }
 
-func InitWordRepository(db *datasource.Neo4J) domain.WordRepository {
+func InitWordRepository(db datasource.Datasource) domain.WordRepository {
 	return &wordRepository{
 		Datasource: db,
 	}
@@ -25,6 +27,7 @@ func InitWordRepository(db *datasource.Neo4J) domain.WordRepository {
 func recordToWord(record map[string]any) *domain.Word {
 	w := domain.Word{
 		Id:          record["id"].(string),
+		GraphId:     common.Nullable{Value: record["graphId"]}.ToString(),
 		UserId:      record["userId"].(string),
 		Content:     record["content"].(string),
 		Description: common.Nullable{Value: record["description"]}.ToStringPtr(),
@@ -36,11 +39,11 @@ func recordToWord(record map[string]any) *domain.Word {
 	return &w
 }
 
-func (repo *wordRepository) Store(w domain.Word, graphId string, linkWordId *string) (*string, error) {
+func (repo *wordRepository) Store(ctx context.Context, w domain.Word, graphId string, linkWordId *string) (*string, error) {
 	query := `MATCH (u:User {id: $userId})
 		MATCH (s:Graph {id: $graphId})
 		CREATE (w:Word {
-			id: apoc.create.uuid(),
+			id: $id,
 			userId: $userId,
 			graphId: $graphId,
 			content: $content,
@@ -51,6 +54,7 @@ func (repo *wordRepository) Store(w domain.Word, graphId string, linkWordId *str
 		CREATE (u)-[:OWN]->(w)
 		CREATE (s)-[:WORD]->(w)`
 	params := map[string]interface{}{
+		"id":          common.NewId(),
 		"userId":      w.UserId,
 		"graphId":     graphId,
 		"content":     w.Content,
@@ -63,9 +67,9 @@ func (repo *wordRepository) Store(w domain.Word, graphId string, linkWordId *str
 		query = "MATCH (r:Word {id: $linkWordId})\n" + query + "\nCREATE (w)-[:CONCERN]->(r)"
 		params["linkWordId"] = linkWordId
 	}
-	query += "\nRETURN w.id AS id;"
+	query = "// word.Store\n" + query + "\nRETURN w.id AS id;"
 
-	result, err := repo.Datasource.ExecWrite(query, params)
+	result, err := repo.Datasource.ExecWrite(ctx, query, params)
 
 	if err != nil {
 		return nil, err
@@ -83,9 +87,11 @@ func (repo *wordRepository) Store(w domain.Word, graphId string, linkWordId *str
 	return common.Nullable{Value: _id}.ToStringPtr(), nil
 }
 
-func (r *wordRepository) Update(w domain.Word) error {
+func (r *wordRepository) Update(ctx context.Context, w domain.Word) error {
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (w:Word {id: $id})
+		ctx,
+		`// word.Update
+			MATCH (w:Word {id: $id})
 		SET w.content= $content,
 			w.description= $description,
 			w.refs= $refs,
@@ -101,10 +107,12 @@ func (r *wordRepository) Update(w domain.Word) error {
 	return err
 }
 
-func (r *wordRepository) Delete(id string) error {
+func (r *wordRepository) Delete(ctx context.Context, id string) error {
 	// remove word and links
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (w:Word {id: $id})
+		ctx,
+		`// word.Delete
+			MATCH (w:Word {id: $id})
 			MATCH (r:Link) WHERE r.word1Id = $id OR r.word2Id = $id
 			DETACH DELETE w,r;`,
 		map[string]interface{}{
@@ -114,11 +122,14 @@ func (r *wordRepository) Delete(id string) error {
 	return err
 }
 
-func (r *wordRepository) FindById(id string) (*domain.Word, error) {
+func (r *wordRepository) FindById(ctx context.Context, id string) (*domain.Word, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH (w:Word {id: $id})
+		ctx,
+		`// word.FindById
+			MATCH (w:Word {id: $id})
 			RETURN w.id as id,
 				w.userId AS userId,
+				w.graphId AS graphId,
 				w.content AS content,
 				w.description AS description,
 				w.refs AS refs,
@@ -142,12 +153,15 @@ func (r *wordRepository) FindById(id string) (*domain.Word, error) {
 	return recordToWord(record.AsMap()), nil
 }
 
-func (r *wordRepository) FindByRandomId() (*domain.Word, error) {
+func (r *wordRepository) FindByRandomId(ctx context.Context) (*domain.Word, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH (w:Word) 
+		ctx,
+		`// word.FindByRandomId
+			MATCH (w:Word) 
 			RETURN rand() as r,
 				w.id as id,
 				w.userId AS userId,
+				w.graphId AS graphId,
 				w.content AS content,
 				w.description AS description,
 				w.refs AS refs,
@@ -171,11 +185,14 @@ func (r *wordRepository) FindByRandomId() (*domain.Word, error) {
 	return recordToWord(record.AsMap()), nil
 }
 
-func (r *wordRepository) FindByIds(ids []string) ([]domain.Word, error) {
+func (r *wordRepository) FindByIds(ctx context.Context, ids []string) ([]domain.Word, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH (w:Word) WHERE w.id IN $ids
+		ctx,
+		`// word.FindByIds
+			MATCH (w:Word) WHERE w.id IN $ids
 			RETURN w.id as id,
 				w.userId AS userId,
+				w.graphId AS graphId,
 				w.content AS content;`,
 		map[string]interface{}{
 			"ids": ids,
@@ -192,9 +209,9 @@ func (r *wordRepository) FindByIds(ids []string) ([]domain.Word, error) {
 	return words, nil
 }
 
-func (r *wordRepository) FindByGraphId(graphId string) ([]domain.Word, []domain.WordsLink, error) {
+func (r *wordRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Word, []domain.WordsLink, error) {
 	// get words
-	ws, err := r.Datasource.ExecRead(`
+	ws, err := r.Datasource.ExecRead(ctx, `// word.FindByGraphId.words
 		MATCH(s:Graph {id: $graphId})-[:WORD]-(w:Word)
 		RETURN distinct w as ws`,
 		map[string]interface{}{
@@ -205,12 +222,9 @@ func (r *wordRepository) FindByGraphId(graphId string) ([]domain.Word, []domain.
 		return nil, nil, err
 	}
 	// get links
-	ls, err := r.Datasource.ExecRead(`
+	ls, err := r.Datasource.ExecRead(ctx, `// word.FindByGraphId.links
 		MATCH(s:Graph {id: $graphId})-[:WORD]-(w:Word)-[r:CONCERN]-()
-		WITH collect(r) as rls
-		UNWIND rls as list
-		UNWIND list as ritem
-		RETURN distinct ritem as ls`,
+		RETURN distinct r as ls`,
 		map[string]interface{}{
 			"graphId": graphId,
 		},
@@ -246,10 +260,12 @@ func (r *wordRepository) FindByGraphId(graphId string) ([]domain.Word, []domain.
 	return words, links, nil
 }
 
-func (r *wordRepository) FindNeighborIds(id string, depth int) ([]domain.WordsLink, error) {
+func (r *wordRepository) FindNeighborIds(ctx context.Context, id string, depth int) ([]domain.WordsLink, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH path = (w1:Word {id: $id})-[:CONCERN*0..`+strconv.Itoa(depth)+`]-(w2:Word)
-			UNWIND relationShips(path) AS r
+		ctx,
+		`// word.FindNeighborIds
+			MATCH path = (w1:Word {id: $id})-[:CONCERN*1..`+strconv.Itoa(depth)+`]-(w2:Word)
+			UNWIND relationships(path) AS r
 			RETURN startNode(r).id AS id1, endNode(r).id as id2;
 		`,
 		map[string]interface{}{
@@ -274,37 +290,62 @@ func (r *wordRepository) FindNeighborIds(id string, depth int) ([]domain.WordsLi
 	return res, nil
 }
 
-func (r *wordRepository) FindByContentOrDescription(search string, limit int) ([]domain.Word, error) {
+func (r *wordRepository) Search(ctx context.Context, search string, graphs domain.WordSearchGraphs, offset int, limit int) ([]domain.WordSearchHit, error) {
+	text := r.Datasource.Dialect().FullTextQuery(search)
+	if strings.TrimSpace(text) == "" {
+		return []domain.WordSearchHit{}, nil
+	}
 	result, err := r.Datasource.ExecRead(
-		`CALL db.index.fulltext.queryNodes("contentAndDescriptions", $text) YIELD node, score
+		ctx,
+		"// word.Search\n"+
+			r.Datasource.Dialect().FullTextSearch("contentAndDescriptions", "Word", []string{"content", "description"}, "text")+`
+			WITH node, score
+			MATCH (g:Graph {id: node.graphId, deleteFlag: false})
+			WHERE $all OR g.id IN $graphIds OR ($public AND g.visibility = $visibility)
 			RETURN node.id as id,
+				node.userId as userId,
+				node.graphId as graphId,
 				node.content as content,
 				node.description as description,
-				score
-			ORDER BY score DESC
+				node.refs as refs,
+				node.createdAt as createdAt,
+				toFloat(score) as score
+			ORDER BY score DESC, id
+			SKIP $offset
 			LIMIT $limit;
 		`,
 		map[string]interface{}{
-			"text":  search,
-			"limit": limit,
+			"text":       text,
+			"all":        graphs.All,
+			"graphIds":   append([]string{}, graphs.Ids...),
+			"public":     graphs.Public,
+			"visibility": string(domain.GraphVisibilityPublic),
+			"offset":     offset,
+			"limit":      limit,
 		},
 	)
 	if err != nil {
 		return nil, err
 	}
-	words := []domain.Word{}
+	hits := []domain.WordSearchHit{}
 	for _, record := range result {
-		words = append(words, *recordToWord(record.AsMap()))
+		m := record.AsMap()
+		hits = append(hits, domain.WordSearchHit{
+			Word:  *recordToWord(m),
+			Score: m["score"].(float64),
+		})
 	}
-	return words, nil
+	return hits, nil
 }
 
-func (r *wordRepository) FindPath(fromId string, toId string) ([]domain.Word, []domain.WordsLink, error) {
+func (r *wordRepository) FindPath(ctx context.Context, fromId string, toId string) ([]domain.Word, []domain.WordsLink, error) {
 	result, err := r.Datasource.ExecRead(
-		`MATCH
+		ctx,
+		`// word.FindPath
+			MATCH
 			(w1:Word {id: $fromId}),
-			(w2:Word {id: $toId}),
-			p = shortestPath((w1)-[:CONCERN*]-(w2))
+			(w2:Word {id: $toId})
+		MATCH `+r.Datasource.Dialect().ShortestPath("p", "w1", "w2", "CONCERN")+`
 		RETURN nodes(p) as nodes, relationships(p) as relationships`,
 		map[string]interface{}{
 			"fromId": fromId,
@@ -340,10 +381,12 @@ func (r *wordRepository) FindPath(fromId string, toId string) ([]domain.Word, []
 	return words, links, nil
 }
 
-func (r *wordRepository) StoreRelation(sourceId string, targetId string) error {
+func (r *wordRepository) StoreRelation(ctx context.Context, sourceId string, targetId string) error {
 	// remove word and links
 	_, err := r.Datasource.ExecWrite(
-		`MATCH (w1:Word {id: $id1})
+		ctx,
+		`// word.StoreRelation
+			MATCH (w1:Word {id: $id1})
 		MATCH (w2:Word {id: $id2})
 		CREATE (w1)-[:CONCERN]->(w2);`,
 		map[string]interface{}{
@@ -353,3 +396,18 @@ func (r *wordRepository) StoreRelation(sourceId string, targetId string) error {
 	)
 	return err
 }
+
+func (r *wordRepository) Lock(ctx context.Context, ids []string) error {
+	// writing a property takes the node write lock
+	_, err := r.Datasource.ExecWrite(
+		ctx,
+		`// word.Lock
+			MATCH (w:Word) WHERE w.id IN $ids
+		SET w._lock = true
+		REMOVE w._lock;`,
+		map[string]interface{}{
+			"ids": ids,
+		},
+	)
+	return err
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/datasource/datasourcetest"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+func TestWordSearchOfTheGraphs(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ctx := context.Background()
+		private := b.graphId
+		b.storeWord(t, "rain in private", nil)
+		ids := map[string]string{private: "private"}
+		for _, visibility := range []domain.GraphVisibility{domain.GraphVisibilityPublic, domain.GraphVisibilityUnlisted} {
+			b.storeGraph(t)
+			b.storeWord(t, "rain in "+string(visibility), nil)
+			ids[b.graphId] = string(visibility)
+			if err := b.graph.Update(ctx, b.graphId, domain.Graph{Name: "test", Visibility: visibility}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// a deleted public graph is not searched
+		b.storeGraph(t)
+		b.storeWord(t, "rain in deleted", nil)
+		if err := b.graph.Update(ctx, b.graphId, domain.Graph{Name: "test", Visibility: domain.GraphVisibilityPublic}); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.graph.Delete(ctx, b.graphId); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, test := range []struct {
+			graphs domain.WordSearchGraphs
+			want   []string
+		}{
+			{domain.WordSearchGraphs{All: true}, []string{"private", "public", "unlisted"}},
+			{domain.WordSearchGraphs{Ids: []string{private}}, []string{"private"}},
+			{domain.WordSearchGraphs{Public: true}, []string{"public"}},
+			{domain.WordSearchGraphs{Ids: []string{private}, Public: true}, []string{"private", "public"}},
+			{domain.WordSearchGraphs{}, []string{}},
+		} {
+			hits, err := b.word.Search(ctx, "rain", test.graphs, 0, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, h := range hits {
+				got = append(got, ids[h.GraphId])
+			}
+			sort.Strings(got)
+			if !slices.Equal(got, test.want) {
+				t.Errorf("Search(%+v) = %v, want %v", test.graphs, got, test.want)
+			}
+		}
+	})
+}
+
+func TestWordSearchQuotesTheLuceneQuery(t *testing.T) {
+	db := datasourcetest.NewFake()
+	var text any
+	db.Handlers["word.Search"] = func(params map[string]any) []map[string]any {
+		text = params["text"]
+		return nil
+	}
+	repo := InitWordRepository(db)
+	if _, err := repo.Search(context.Background(), `foo) a:"b`, domain.WordSearchGraphs{All: true}, 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if want := `"foo)" "a:\"b"`; text != want {
+		t.Fatalf("word.Search text = %v, want %s", text, want)
+	}
+
+	// nothing is searched without a word
+	db.Queries = nil
+	if hits, err := repo.Search(context.Background(), " ", domain.WordSearchGraphs{All: true}, 0, 10); err != nil || len(hits) != 0 || len(db.Queries) != 0 {
+		t.Fatalf("Search() of a blank text = %v, %v, ran %v", hits, err, db.Queries)
+	}
+}
+
+// Words of the graph by name, related by the "a-b" pairs
+func (b *backend) storeRelated(t *testing.T, names []string, relations []string) map[string]string {
+	ids := map[string]string{}
+	for _, n := range names {
+		ids[n] = b.storeWord(t, n, nil)
+	}
+	for _, r := range relations {
+		ends := strings.Split(r, "-")
+		if err := b.word.StoreRelation(context.Background(), ids[ends[0]], ids[ends[1]]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids
+}
+
+// The links as sorted "a-b" pairs of the names of their words
+func linkNames(ids map[string]string, links []domain.WordsLink) []string {
+	names := map[string]string{}
+	for n, id := range ids {
+		names[id] = n
+	}
+	pairs := []string{}
+	for _, l := range links {
+		pairs = append(pairs, names[l.SourceId]+"-"+names[l.TargetId])
+	}
+	sort.Strings(pairs)
+	return pairs
+}
+
+// a cycle a-b-c with a tail c-d-e and a word z on its own
+var pathNames, pathRelations = []string{"a", "b", "c", "d", "e", "z"}, []string{"a-b", "b-c", "c-a", "c-d", "d-e"}
+
+func TestWordFindNeighborIds(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ids := b.storeRelated(t, pathNames, pathRelations)
+		for _, test := range []struct {
+			from  string
+			depth int
+			want  []string
+		}{
+			{"a", 1, []string{"a-b", "c-a"}},
+			{"a", 2, []string{"a-b", "b-c", "c-a", "c-d"}},
+			{"a", 10, []string{"a-b", "b-c", "c-a", "c-d", "d-e"}},
+			{"e", 2, []string{"c-d", "d-e"}},
+			{"z", 3, []string{}},
+		} {
+			links, err := b.word.FindNeighborIds(context.Background(), ids[test.from], test.depth)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := linkNames(ids, links); !slices.Equal(got, test.want) {
+				t.Errorf("FindNeighborIds(%s, %d) = %v, want %v", test.from, test.depth, got, test.want)
+			}
+		}
+	})
+}
+
+func TestWordFindPath(t *testing.T) {
+	eachBackend(t, func(t *testing.T, b *backend) {
+		ids := b.storeRelated(t, pathNames, pathRelations)
+		for _, test := range []struct {
+			from, to string
+			want     []string
+		}{
+			// around the cycle the short way
+			{"a", "e", []string{"a", "c", "d", "e"}},
+			{"e", "b", []string{"e", "d", "c", "b"}},
+			{"a", "b", []string{"a", "b"}},
+			{"a", "z", []string{}},
+			{"z", "a", []string{}},
+		} {
+			words, links, err := b.word.FindPath(context.Background(), ids[test.from], ids[test.to])
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, w := range words {
+				got = append(got, w.Content)
+			}
+			if !slices.Equal(got, test.want) || len(links) != max(len(got)-1, 0) {
+				t.Errorf("FindPath(%s, %s) = %v with %d links, want %v", test.from, test.to, got, len(links), test.want)
+			}
+		}
+	})
+}
+
+func TestWordSQLiteFindPathUpToTheDepthCap(t *testing.T) {
+	b := sqliteBackend(t)
+	names, relations := []string{}, []string{}
+	for i := 0; i <= maxPathDepth+1; i++ {
+		names = append(names, fmt.Sprint("w", i))
+		if i > 0 {
+			relations = append(relations, fmt.Sprintf("w%d-w%d", i-1, i))
+		}
+	}
+	ids := b.storeRelated(t, names, relations)
+
+	words, links, err := b.word.FindPath(context.Background(), ids["w0"], ids[fmt.Sprint("w", maxPathDepth)])
+	if err != nil || len(words) != maxPathDepth+1 || len(links) != maxPathDepth {
+		t.Fatalf("FindPath() of %d hops = %d words, %d links, %v", maxPathDepth, len(words), len(links), err)
+	}
+	words, links, err = b.word.FindPath(context.Background(), ids["w0"], ids[fmt.Sprint("w", maxPathDepth+1)])
+	if err != nil || len(words) != 0 || len(links) != 0 {
+		t.Fatalf("FindPath() past the cap = %d words, %d links, %v", len(words), len(links), err)
+	}
+}
+
+func TestWordSQLiteSearchRanksByBm25(t *testing.T) {
+	b := sqliteBackend(t)
+	ctx := context.Background()
+	short := b.storeWord(t, "rain", nil)
+	long := b.storeWord(t, "a long walk in the rain of the north valley", nil)
+	twice := b.storeWord(t, "rain after rain", nil)
+	b.storeWord(t, "flood", nil)
+	all := domain.WordSearchGraphs{All: true}
+
+	hits, err := b.word.Search(ctx, "rain", all, 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := []string{}
+	for _, h := range hits {
+		got = append(got, h.Id)
+	}
+	if want := []string{twice, short, long}; !slices.Equal(got, want) {
+		t.Fatalf("Search(rain) = %v, want %v", got, want)
+	}
+	if hits[0].Score <= hits[2].Score {
+		t.Fatalf("Search(rain) scores %f then %f, want the best first", hits[0].Score, hits[2].Score)
+	}
+
+	// paged by the database
+	hits, err = b.word.Search(ctx, "rain", all, 1, 1)
+	if err != nil || len(hits) != 1 || hits[0].Id != short {
+		t.Fatalf("Search(rain) at offset 1 = %+v, %v", hits, err)
+	}
+
+	// the fts5 syntax is read as text
+	for _, text := range []string{`rain OR "`, "rain)", "NEAR(rain", "*", "the"} {
+		if _, err := b.word.Search(ctx, text, all, 0, 10); err != nil {
+			t.Errorf("Search(%q) = %v", text, err)
+		}
+	}
+}
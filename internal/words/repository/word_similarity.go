@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"sort"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// weight of the terms of the content against the ones of the description and refs
+const contentTermWeight = 2
+
+// parts of the refs found in most of them
+var refStopWords = map[string]bool{
+	"http": true, "https": true, "www": true, "com": true, "org": true, "html": true,
+}
+
+// wordSimilarity compares the words of the recently used graphs
+// by TF-IDF vectors of the terms of their content, description and refs.
+// The document frequencies depending on every word of the graph,
+// the vectors are built again once the words of the graph change.
+type wordSimilarity struct {
+	graphs *graphIndexes[*graphSimilarityIndex]
+}
+
+// size is the number of graphs kept, 0 builds the vectors on every call
+func InitWordSimilarity(repo domain.WordRepository, cache *GraphDataCache, size int) domain.WordSimilarity {
+	return &wordSimilarity{
+		graphs: initGraphIndexes(cacheWordSimilarity, repo, cache, size, buildGraphSimilarityIndex),
+	}
+}
+
+func (s *wordSimilarity) Similar(ctx context.Context, graphId string, w domain.Word, exclude []string, limit int) ([]domain.SimilarWord, error) {
+	index, err := s.graphs.get(ctx, graphId)
+	if err != nil {
+		return nil, err
+	}
+	return index.similar(w, exclude, limit), nil
+}
+
+type similarityPosting struct {
+	word   int
+	weight float64
+}
+
+type graphSimilarityIndex struct {
+	words []domain.Word
+	ids   map[string]int
+	// unit vector of each word
+	vectors []map[string]float64
+	// words containing each term
+	frequencies map[string]int
+	// the words whose vector has the term, with its weight
+	postings map[string][]similarityPosting
+}
+
+// Count the terms of the text of the word, the content weighing more
+func termFrequencies(w domain.Word) map[string]float64 {
+	tf := map[string]float64{}
+	for _, t := range common.Tokenize(w.Content) {
+		tf[t] += contentTermWeight
+	}
+	if w.Description != nil {
+		for _, t := range common.Tokenize(*w.Description) {
+			tf[t]++
+		}
+	}
+	if w.Refs != nil {
+		for _, ref := range *w.Refs {
+			for _, t := range common.Tokenize(ref) {
+				if !refStopWords[t] {
+					tf[t]++
+				}
+			}
+		}
+	}
+	return tf
+}
+
+// Weigh the terms by their smoothed inverse document frequency, to a unit vector
+func (index *graphSimilarityIndex) vector(tf map[string]float64) map[string]float64 {
+	n := float64(len(index.words))
+	v := map[string]float64{}
+	norm := 0.0
+	for t, f := range tf {
+		idf := math.Log((1+n)/(1+float64(index.frequencies[t]))) + 1
+		v[t] = (1 + math.Log(f)) * idf
+		norm += v[t] * v[t]
+	}
+	norm = math.Sqrt(norm)
+	for t := range v {
+		v[t] /= norm
+	}
+	return v
+}
+
+func buildGraphSimilarityIndex(words []domain.Word, links []domain.WordsLink) *graphSimilarityIndex {
+	index := &graphSimilarityIndex{
+		words:       words,
+		ids:         map[string]int{},
+		frequencies: map[string]int{},
+		postings:    map[string][]similarityPosting{},
+	}
+	tfs := []map[string]float64{}
+	for i, w := range words {
+		index.ids[w.Id] = i
+		tf := termFrequencies(w)
+		for t := range tf {
+			index.frequencies[t]++
+		}
+		tfs = append(tfs, tf)
+	}
+	for i, tf := range tfs {
+		v := index.vector(tf)
+		index.vectors = append(index.vectors, v)
+		for t, weight := range v {
+			index.postings[t] = append(index.postings[t], similarityPosting{word: i, weight: weight})
+		}
+	}
+	return index
+}
+
+func (index *graphSimilarityIndex) similar(w domain.Word, exclude []string, limit int) []domain.SimilarWord {
+	var v map[string]float64
+	if i, ok := index.ids[w.Id]; ok && w.Id != "" {
+		v = index.vectors[i]
+	} else {
+		v = index.vector(termFrequencies(w))
+	}
+
+	excluded := map[int]bool{}
+	for _, id := range exclude {
+		if i, ok := index.ids[id]; ok {
+			excluded[i] = true
+		}
+	}
+	if i, ok := index.ids[w.Id]; ok {
+		excluded[i] = true
+	}
+	// the dot products of the unit vectors sharing a term
+	scores := map[int]float64{}
+	for t, weight := range v {
+		for _, p := range index.postings[t] {
+			if !excluded[p.word] {
+				scores[p.word] += weight * p.weight
+			}
+		}
+	}
+
+	res := []domain.SimilarWord{}
+	for i, score := range scores {
+		res = append(res, domain.SimilarWord{
+			Word:  index.words[i],
+			Score: math.Min(score, 1),
+		})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Score != res[j].Score {
+			return res[i].Score > res[j].Score
+		}
+		return res[i].Id < res[j].Id
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+	return res
+}
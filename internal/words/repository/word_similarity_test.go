@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"maps"
+	"math"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+func similarityWords(contents map[string]string) []domain.Word {
+	words := []domain.Word{}
+	for id, c := range contents {
+		words = append(words, domain.Word{Id: id, Content: c})
+	}
+	return words
+}
+
+func similarIds(res []domain.SimilarWord) []string {
+	ids := []string{}
+	for _, s := range res {
+		ids = append(ids, s.Id)
+	}
+	return ids
+}
+
+func TestTermFrequenciesWeighTheContent(t *testing.T) {
+	tf := termFrequencies(domain.Word{
+		Content:     "Rain floods",
+		Description: common.ToPointer("the rain"),
+		Refs:        &[]string{"https://www.rain.org/flood.html"},
+	})
+	want := map[string]float64{"rain": 4, "flood": 3}
+	if !maps.Equal(tf, want) {
+		t.Fatalf("termFrequencies() = %v, want %v", tf, want)
+	}
+}
+
+func TestSimilarityVectors(t *testing.T) {
+	index := buildGraphSimilarityIndex(similarityWords(map[string]string{
+		"storm": "rain storm",
+		"water": "rain water",
+		"coat":  "rain coat",
+	}), nil)
+
+	for i, v := range index.vectors {
+		norm := 0.0
+		for _, w := range v {
+			norm += w * w
+		}
+		if math.Abs(norm-1) > 1e-9 {
+			t.Errorf("vector of %s has the norm %f", index.words[i].Id, math.Sqrt(norm))
+		}
+	}
+	// the term of every word weighs less than the one of a single word
+	v := index.vectors[index.ids["storm"]]
+	if v["rain"] >= v["storm"] {
+		t.Fatalf("vector of storm = %v, want rain under storm", v)
+	}
+}
+
+func TestSimilarRanksTheRareTermsFirst(t *testing.T) {
+	index := buildGraphSimilarityIndex(similarityWords(map[string]string{
+		"cloud": "storm cloud",
+		"water": "rain water",
+		"coat":  "rain coat",
+		"drop":  "rain drop",
+		"sun":   "sun",
+	}), nil)
+
+	draft := domain.Word{Content: "rain storm"}
+	res := index.similar(draft, nil, 10)
+	if len(res) != 4 || res[0].Id != "cloud" {
+		t.Fatalf("similar() of the draft = %v, want cloud then the rain words", similarIds(res))
+	}
+	if res[0].Score <= res[1].Score {
+		t.Fatalf("similar() scores cloud %f, not above %s %f", res[0].Score, res[1].Id, res[1].Score)
+	}
+
+	if res := index.similar(draft, nil, 2); len(res) != 2 {
+		t.Fatalf("similar() with a limit of 2 = %v", similarIds(res))
+	}
+}
+
+func TestSimilarLeavesOutTheWordAndTheExcluded(t *testing.T) {
+	index := buildGraphSimilarityIndex(similarityWords(map[string]string{
+		"rain":  "rain",
+		"copy":  "rain",
+		"storm": "rain storm",
+		"coat":  "rain coat",
+	}), nil)
+
+	res := index.similar(index.words[index.ids["rain"]], []string{"storm"}, 10)
+	ids := similarIds(res)
+	if len(ids) != 2 || ids[0] != "copy" || ids[1] != "coat" {
+		t.Fatalf("similar() of rain = %v, want copy then coat", ids)
+	}
+	// the same terms are the same unit vector
+	if math.Abs(res[0].Score-1) > 1e-9 {
+		t.Fatalf("similar() scores the copy %f, want 1", res[0].Score)
+	}
+}
@@ -0,0 +1,388 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+// deepest path FindPath looks for, the breadth first search
+// of the recursive query grows with the depth
+const maxPathDepth = 10
+
+type wordSQLiteRepository struct {
+	Datasource *datasource.SQLite
+}
+
+func InitWordSQLiteRepository(db *datasource.SQLite) domain.WordRepository {
+	return &wordSQLiteRepository{
+		Datasource: db,
+	}
+}
+
+const wordColumns = `id, graph_id, user_id, content, description, refs, created_at, updated_at`
+
+// Store refs as a json array
+func refsToJSON(refs *[]string) any {
+	if refs == nil {
+		return nil
+	}
+	b, _ := json.Marshal(*refs)
+	return string(b)
+}
+
+// Read refs stored by refsToJSON
+func jsonToRefs(s sql.NullString) *[]string {
+	if !s.Valid {
+		return nil
+	}
+	refs := []string{}
+	if err := json.Unmarshal([]byte(s.String), &refs); err != nil {
+		return nil
+	}
+	return &refs
+}
+
+func scanWord(rows *sql.Rows) (domain.Word, error) {
+	w := domain.Word{}
+	var refs sql.NullString
+	err := rows.Scan(&w.Id, &w.GraphId, &w.UserId, &w.Content, &w.Description, &refs, &w.CreatedAt, &w.UpdatedAt)
+	w.Refs = jsonToRefs(refs)
+	return w, err
+}
+
+func (repo *wordSQLiteRepository) Store(ctx context.Context, w domain.Word, graphId string, linkWordId *string) (*string, error) {
+	id := common.NewId()
+	err := repo.Datasource.WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := repo.Datasource.ExecWrite(
+			ctx,
+			`-- word.Store
+				INSERT INTO words (id, graph_id, user_id, content, description, refs, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?);`,
+			id, graphId, w.UserId, w.Content, w.Description, refsToJSON(w.Refs), time.Now(),
+		)
+		if err != nil || linkWordId == nil {
+			return err
+		}
+		_, err = repo.Datasource.ExecWrite(
+			ctx,
+			`-- word.Store.relation
+				INSERT INTO relations (start_id, end_id) VALUES (?, ?);`,
+			id, *linkWordId,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (repo *wordSQLiteRepository) Update(ctx context.Context, w domain.Word) error {
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- word.Update
+			UPDATE words SET content = ?, description = ?, refs = ?, updated_at = ?
+		WHERE id = ?;`,
+		w.Content, w.Description, refsToJSON(w.Refs), time.Now(), w.Id,
+	)
+	return err
+}
+
+func (repo *wordSQLiteRepository) Delete(ctx context.Context, id string) error {
+	// relations and links are removed by the foreign keys
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- word.Delete
+			DELETE FROM words WHERE id = ?;`,
+		id,
+	)
+	return err
+}
+
+func (repo *wordSQLiteRepository) findOne(ctx context.Context, query string, args ...any) (res *domain.Word, err error) {
+	err = repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			w, err := scanWord(rows)
+			res = &w
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (repo *wordSQLiteRepository) FindById(ctx context.Context, id string) (*domain.Word, error) {
+	return repo.findOne(
+		ctx,
+		`-- word.FindById
+			SELECT `+wordColumns+` FROM words WHERE id = ?;`,
+		id,
+	)
+}
+
+func (repo *wordSQLiteRepository) FindByRandomId(ctx context.Context) (*domain.Word, error) {
+	return repo.findOne(
+		ctx,
+		`-- word.FindByRandomId
+			SELECT `+wordColumns+` FROM words ORDER BY random() LIMIT 1;`,
+	)
+}
+
+func (repo *wordSQLiteRepository) findMany(ctx context.Context, query string, args ...any) ([]domain.Word, error) {
+	words := []domain.Word{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			w, err := scanWord(rows)
+			words = append(words, w)
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return words, nil
+}
+
+func (repo *wordSQLiteRepository) FindByIds(ctx context.Context, ids []string) ([]domain.Word, error) {
+	_ids, _ := json.Marshal(ids)
+	return repo.findMany(
+		ctx,
+		`-- word.FindByIds
+			SELECT `+wordColumns+` FROM words
+		WHERE id IN (SELECT value FROM json_each(?));`,
+		string(_ids),
+	)
+}
+
+func (repo *wordSQLiteRepository) findLinks(ctx context.Context, query string, args ...any) ([]domain.WordsLink, error) {
+	links := []domain.WordsLink{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		query,
+		func(rows *sql.Rows) error {
+			l := domain.WordsLink{}
+			err := rows.Scan(&l.SourceId, &l.TargetId)
+			links = append(links, l)
+			return err
+		},
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return links, nil
+}
+
+func (repo *wordSQLiteRepository) FindByGraphId(ctx context.Context, graphId string) ([]domain.Word, []domain.WordsLink, error) {
+	words, err := repo.findMany(
+		ctx,
+		`-- word.FindByGraphId.words
+			SELECT `+wordColumns+` FROM words
+		WHERE graph_id = ?
+		ORDER BY created_at;`,
+		graphId,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	links, err := repo.findLinks(
+		ctx,
+		`-- word.FindByGraphId.links
+			SELECT r.start_id, r.end_id FROM relations r
+		WHERE r.start_id IN (SELECT id FROM words WHERE graph_id = ?1)
+			OR r.end_id IN (SELECT id FROM words WHERE graph_id = ?1)
+		ORDER BY r.id;`,
+		graphId,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return words, links, nil
+}
+
+func (repo *wordSQLiteRepository) FindNeighborIds(ctx context.Context, id string, depth int) ([]domain.WordsLink, error) {
+	// a relationship is on a path of at most depth hops
+	// when one of its ends is reached in less than depth hops
+	return repo.findLinks(
+		ctx,
+		`-- word.FindNeighborIds
+			WITH RECURSIVE reached(id, depth) AS (
+			SELECT ?1, 0
+			UNION
+			SELECT r.end_id, reached.depth + 1 FROM reached
+			JOIN relations r ON r.start_id = reached.id
+			WHERE reached.depth < ?2 - 1
+			UNION
+			SELECT r.start_id, reached.depth + 1 FROM reached
+			JOIN relations r ON r.end_id = reached.id
+			WHERE reached.depth < ?2 - 1
+		)
+		SELECT r.start_id, r.end_id FROM relations r
+		WHERE r.start_id IN (SELECT id FROM reached WHERE depth < ?2)
+			OR r.end_id IN (SELECT id FROM reached WHERE depth < ?2)
+		ORDER BY r.id;`,
+		id, depth,
+	)
+}
+
+func (repo *wordSQLiteRepository) Search(ctx context.Context, search string, graphs domain.WordSearchGraphs, offset int, limit int) ([]domain.WordSearchHit, error) {
+	hits := []domain.WordSearchHit{}
+	// quote the terms so they are never read as fts5 operators
+	terms := common.Tokenize(search)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+	for i, t := range terms {
+		terms[i] = `"` + t + `"`
+	}
+	ids, _ := json.Marshal(append([]string{}, graphs.Ids...))
+
+	// bm25 is negative, the better the match the lower
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- word.Search
+			SELECT w.id, w.graph_id, w.user_id, w.content, w.description, w.refs, w.created_at, w.updated_at,
+			-bm25(words_fts) AS score
+		FROM words_fts f
+		JOIN words w ON w.id = f.id
+		JOIN graphs g ON g.id = w.graph_id AND NOT g.delete_flag
+		WHERE words_fts MATCH ?1
+			AND (?5 OR g.id IN (SELECT value FROM json_each(?2)) OR (?6 AND g.visibility = ?7))
+		ORDER BY score DESC, w.id
+		LIMIT ?3 OFFSET ?4;`,
+		func(rows *sql.Rows) error {
+			h := domain.WordSearchHit{}
+			var refs sql.NullString
+			err := rows.Scan(&h.Id, &h.GraphId, &h.UserId, &h.Content, &h.Description, &refs, &h.CreatedAt, &h.UpdatedAt, &h.Score)
+			h.Refs = jsonToRefs(refs)
+			hits = append(hits, h)
+			return err
+		},
+		strings.Join(terms, " OR "), string(ids), limit, offset,
+		graphs.All, graphs.Public, string(domain.GraphVisibilityPublic),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
+
+func (repo *wordSQLiteRepository) FindPath(ctx context.Context, fromId string, toId string) ([]domain.Word, []domain.WordsLink, error) {
+	// distance of every word reached from fromId by a breadth first search,
+	// then the relationships going one step closer to toId
+	type step struct {
+		startId, endId     string
+		startDist, endDist int
+	}
+	steps := []step{}
+	err := repo.Datasource.ExecRead(
+		ctx,
+		`-- word.FindPath
+			WITH RECURSIVE bfs(id, depth) AS (
+			SELECT ?1, 0
+			UNION
+			SELECT r.end_id, bfs.depth + 1 FROM bfs
+			JOIN relations r ON r.start_id = bfs.id
+			WHERE bfs.depth < ?3 AND bfs.id <> ?2
+			UNION
+			SELECT r.start_id, bfs.depth + 1 FROM bfs
+			JOIN relations r ON r.end_id = bfs.id
+			WHERE bfs.depth < ?3 AND bfs.id <> ?2
+		),
+		dist(id, depth) AS (
+			SELECT id, MIN(depth) FROM bfs GROUP BY id
+		)
+		SELECT r.start_id, r.end_id, ds.depth, de.depth FROM relations r
+		JOIN dist ds ON ds.id = r.start_id
+		JOIN dist de ON de.id = r.end_id
+		WHERE abs(ds.depth - de.depth) = 1
+			AND max(ds.depth, de.depth) <= (SELECT depth FROM dist WHERE id = ?2)
+		ORDER BY r.id;`,
+		func(rows *sql.Rows) error {
+			s := step{}
+			err := rows.Scan(&s.startId, &s.endId, &s.startDist, &s.endDist)
+			steps = append(steps, s)
+			return err
+		},
+		fromId, toId, maxPathDepth,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// walk back from toId, one word closer to fromId at each step
+	ids := []string{toId}
+	links := []domain.WordsLink{}
+	cur := toId
+	for cur != fromId {
+		found := false
+		for _, s := range steps {
+			if s.startId == cur && s.endDist == s.startDist-1 {
+				cur = s.endId
+			} else if s.endId == cur && s.startDist == s.endDist-1 {
+				cur = s.startId
+			} else {
+				continue
+			}
+			ids = append([]string{cur}, ids...)
+			links = append([]domain.WordsLink{{SourceId: s.startId, TargetId: s.endId}}, links...)
+			found = true
+			break
+		}
+		if !found {
+			return []domain.Word{}, []domain.WordsLink{}, nil
+		}
+	}
+
+	found, err := repo.FindByIds(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	byId := map[string]domain.Word{}
+	for _, w := range found {
+		byId[w.Id] = w
+	}
+	words := []domain.Word{}
+	for _, id := range ids {
+		w, ok := byId[id]
+		if !ok {
+			return []domain.Word{}, []domain.WordsLink{}, nil
+		}
+		words = append(words, w)
+	}
+	return words, links, nil
+}
+
+func (repo *wordSQLiteRepository) StoreRelation(ctx context.Context, sourceId string, targetId string) error {
+	// like the graph databases, nothing is stored when a word is missing
+	_, err := repo.Datasource.ExecWrite(
+		ctx,
+		`-- word.StoreRelation
+			INSERT INTO relations (start_id, end_id)
+		SELECT w1.id, w2.id FROM words w1, words w2
+		WHERE w1.id = ? AND w2.id = ?;`,
+		sourceId, targetId,
+	)
+	return err
+}
+
+func (repo *wordSQLiteRepository) Lock(ctx context.Context, ids []string) error {
+	// transactions take the database write lock when they begin
+	return nil
+}
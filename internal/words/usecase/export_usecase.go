@@ -0,0 +1,70 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+)
+
+type exportUsecase struct {
+	wordRepo domain.WordRepository
+	linkRepo domain.LinkRepository
+	access   graphAccess
+}
+
+func InitExportUsecase(
+	wordRepo domain.WordRepository,
+	linkRepo domain.LinkRepository,
+	graphRepo domain.GraphRepository,
+	memberRepo domain.MemberRepository,
+	shareRepo domain.ShareRepository,
+) domain.ExportUsecase {
+	return &exportUsecase{
+		wordRepo: wordRepo,
+		linkRepo: linkRepo,
+		access:   graphAccess{graphRepo: graphRepo, memberRepo: memberRepo, shareRepo: shareRepo},
+	}
+}
+
+func (u *exportUsecase) Export(c context.Context, graphId string, user domain.Profile) (*domain.GraphExport, error) {
+	graph, err := u.access.checkRead(c, graphId, user)
+	if err != nil {
+		return nil, err
+	}
+
+	words, relations, err := u.wordRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	links, err := u.linkRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+
+	// the relationships and links to the words of other graphs are left out
+	inGraph := map[string]bool{}
+	for _, w := range words {
+		inGraph[w.Id] = true
+	}
+	res := domain.GraphExport{
+		Version:    domain.GraphExportVersion,
+		ExportedAt: time.Now(),
+		Graph:      *graph,
+		Words:      words,
+		Relations:  []domain.WordsLink{},
+		Links:      []domain.Link{},
+	}
+	for _, r := range relations {
+		if inGraph[r.SourceId] && inGraph[r.TargetId] {
+			res.Relations = append(res.Relations, r)
+		}
+	}
+	for _, l := range links {
+		if inGraph[l.Word1Id] && inGraph[l.Word2Id] {
+			res.Links = append(res.Links, l)
+		}
+	}
+	return &res, nil
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// graphAccess checks the role of a user on a graph,
+// shared by the usecases working on the graph content
+type graphAccess struct {
+	graphRepo  domain.GraphRepository
+	memberRepo domain.MemberRepository
+	shareRepo  domain.ShareRepository
+}
+
+// Role of the user on the graph, empty when the graph is not shared with the user.
+// The site admins and moderators manage every graph.
+func (a graphAccess) role(c context.Context, graph domain.Graph, user domain.Profile) (domain.GraphRole, error) {
+	if user.Role == domain.RoleAdmin || user.Role == domain.RoleModerator {
+		return domain.GraphRoleOwner, nil
+	}
+	if user.Id == "" {
+		return "", nil
+	}
+	if user.Id == graph.UserId {
+		return domain.GraphRoleOwner, nil
+	}
+	m, err := a.memberRepo.FindByGraphIdAndUserId(c, graph.Id, user.Id)
+	if err != nil || m == nil {
+		return "", err
+	}
+	return m.Role, nil
+}
+
+// Whether the share link given with the request opens the graph for reading
+func (a graphAccess) shared(c context.Context, graph domain.Graph, user domain.Profile) (bool, error) {
+	id, err := a.sharedGraphId(c, user, graph.Id)
+	return id != "", err
+}
+
+// Graph the share link given with the request opens for reading, empty when none does.
+// A link of another graph than graphId, when given, or an expired one is ignored,
+// a missing or wrong password returns ErrInvalidCredential.
+func (a graphAccess) sharedGraphId(c context.Context, user domain.Profile, graphId string) (string, error) {
+	if user.Share == nil || user.Share.Token == "" || a.shareRepo == nil {
+		return "", nil
+	}
+	share, err := a.shareRepo.FindByTokenHash(c, common.HashToken(user.Share.Token))
+	if err != nil {
+		return "", common.InternalError(err)
+	}
+	if share == nil || (graphId != "" && share.GraphId != graphId) {
+		return "", nil
+	}
+	if share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now()) {
+		return "", nil
+	}
+	if share.PasswordHash != nil {
+		if err := bcrypt.CompareHashAndPassword([]byte(*share.PasswordHash), []byte(user.Share.Password)); err != nil {
+			return "", common.ErrInvalidCredential
+		}
+	}
+	return share.GraphId, nil
+}
+
+// Load the graph when the user has the given role on it.
+// It returns ErrNotFound when the user can't read the graph,
+// so its existence is not disclosed, and ErrUnauthorization when the role is lower.
+func (a graphAccess) check(c context.Context, graphId string, user domain.Profile, required domain.GraphRole) (*domain.Graph, error) {
+	graph, err := a.graphRepo.SelectOne(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if graph == nil {
+		return nil, common.ErrNotFound
+	}
+
+	role, err := a.role(c, *graph, user)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if role == "" && graph.Visibility == domain.GraphVisibilityPrivate {
+		return nil, common.ErrNotFound
+	}
+	if !role.Includes(required) {
+		return nil, common.ErrUnauthorization
+	}
+	return graph, nil
+}
+
+// Load the deleted graph when the user has the given role on it.
+// A deleted graph is read by no one, so it is ErrNotFound without a role.
+func (a graphAccess) checkDeleted(c context.Context, graphId string, user domain.Profile, required domain.GraphRole) (*domain.Graph, error) {
+	graph, err := a.graphRepo.SelectDeletedOne(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if graph == nil {
+		return nil, common.ErrNotFound
+	}
+
+	role, err := a.role(c, *graph, user)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if role == "" {
+		return nil, common.ErrNotFound
+	}
+	if !role.Includes(required) {
+		return nil, common.ErrUnauthorization
+	}
+	return graph, nil
+}
+
+// Load the graph when the user may read it, as a member,
+// with a share link or because the graph is not private
+func (a graphAccess) checkRead(c context.Context, graphId string, user domain.Profile) (*domain.Graph, error) {
+	graph, err := a.graphRepo.SelectOne(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if graph == nil {
+		return nil, common.ErrNotFound
+	}
+	if graph.Visibility == domain.GraphVisibilityPublic || graph.Visibility == domain.GraphVisibilityUnlisted {
+		return graph, nil
+	}
+
+	role, err := a.role(c, *graph, user)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if role != "" {
+		return graph, nil
+	}
+	ok, err := a.shared(c, *graph, user)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, common.ErrNotFound
+	}
+	return graph, nil
+}
+
+// Check that the user may read the graphs of the words
+func (a graphAccess) checkReadWords(c context.Context, words []domain.Word, user domain.Profile) error {
+	checked := map[string]bool{}
+	for _, w := range words {
+		if checked[w.GraphId] {
+			continue
+		}
+		if _, err := a.checkRead(c, w.GraphId, user); err != nil {
+			return err
+		}
+		checked[w.GraphId] = true
+	}
+	return nil
+}
+
+// Graphs the user finds in the search results, the graphs of the user,
+// the one opened by the share link and the public ones but not the unlisted ones
+func (a graphAccess) searchable(c context.Context, user domain.Profile) (domain.WordSearchGraphs, error) {
+	if user.Role == domain.RoleAdmin || user.Role == domain.RoleModerator {
+		return domain.WordSearchGraphs{All: true}, nil
+	}
+	graphs := domain.WordSearchGraphs{Ids: []string{}, Public: true}
+	if user.Id != "" {
+		owned, err := a.graphRepo.Select(c, user.Id)
+		if err != nil {
+			return graphs, common.InternalError(err)
+		}
+		for _, g := range owned {
+			graphs.Ids = append(graphs.Ids, g.Id)
+		}
+	}
+	shared, err := a.sharedGraphId(c, user, "")
+	if err != nil {
+		return graphs, err
+	}
+	if shared != "" {
+		graphs.Ids = append(graphs.Ids, shared)
+	}
+	return graphs, nil
+}
+
+// Check the role of the user on the graphs of the words
+func (a graphAccess) checkWords(c context.Context, words []domain.Word, user domain.Profile, required domain.GraphRole) error {
+	checked := map[string]bool{}
+	for _, w := range words {
+		if checked[w.GraphId] {
+			continue
+		}
+		if _, err := a.check(c, w.GraphId, user, required); err != nil {
+			return err
+		}
+		checked[w.GraphId] = true
+	}
+	return nil
+}
@@ -10,63 +10,148 @@ import (
 )
 
 type graphUsecase struct {
-	graphRepo domain.GraphRepository
+	graphRepo  domain.GraphRepository
+	graphCache domain.GraphCache
+	access     graphAccess
+	transactor domain.Transactor
 }
 
-func InitGraphUsecase(repo domain.GraphRepository) domain.GraphUsecase {
+func InitGraphUsecase(repo domain.GraphRepository, memberRepo domain.MemberRepository, shareRepo domain.ShareRepository, graphCache domain.GraphCache, transactor domain.Transactor) domain.GraphUsecase {
 	return &graphUsecase{
-		graphRepo: repo,
+		graphRepo:  repo,
+		graphCache: graphCache,
+		access:     graphAccess{graphRepo: repo, memberRepo: memberRepo, shareRepo: shareRepo},
+		transactor: transactor,
 	}
 }
 
 func (u *graphUsecase) List(c context.Context, user domain.Profile) ([]domain.Graph, error) {
-	return u.graphRepo.Select(user.Id)
+	return u.graphRepo.Select(c, user.Id)
 }
 
-func (u *graphUsecase) Get(c context.Context, id string) (*domain.Graph, error) {
-	return u.graphRepo.SelectOne(id)
+func (u *graphUsecase) Get(c context.Context, id string, user domain.Profile) (*domain.Graph, error) {
+	return u.access.checkRead(c, id, user)
 }
 
 func (u *graphUsecase) Create(c context.Context, graph domain.Graph, user domain.Profile) (res *string, err error) {
 	// insert to db
 	w := &graph
 	w.CreatedAt = common.ToPointer(time.Now())
-	wId, err := u.graphRepo.Store(*w)
+	if w.Visibility == "" {
+		w.Visibility = domain.GraphVisibilityPrivate
+	}
+	wId, err := u.graphRepo.Store(c, *w)
 	if err != nil {
 		slog.Error("Create error", err)
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
 
 	return wId, nil
 }
 
 func (u *graphUsecase) Update(c context.Context, id string, graph domain.Graph, user domain.Profile) error {
-	sp, err := u.graphRepo.SelectOne(id)
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// renaming the graph is left to its owners
+		if _, err := u.access.check(c, id, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+
+		if err := u.graphRepo.Update(c, id, graph); err != nil {
+			slog.Error("Update graph error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return common.ErrInternalServerError
+		return err
 	}
+	u.graphCache.InvalidateGraph(c, id)
+	return nil
+}
 
-	if sp == nil || (user.Role == domain.RoleMember && user.Id != sp.UserId) {
-		return common.ErrNotFound
+func (u *graphUsecase) Delete(c context.Context, id string, user domain.Profile) error {
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, id, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+
+		if err := u.graphRepo.Delete(c, id); err != nil {
+			slog.Error("Delete graph error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	u.graphCache.InvalidateGraph(c, id)
+	return nil
+}
 
-	err = u.graphRepo.Update(id, graph)
+func (u *graphUsecase) ListDeleted(c context.Context, user domain.Profile) ([]domain.Graph, error) {
+	graphs, err := u.graphRepo.SelectDeleted(c, user.Id)
 	if err != nil {
-		slog.Error("Update graph error", err)
+		slog.Error("List deleted graphs error", err)
+		return nil, common.InternalError(err)
 	}
-	return err
+	return graphs, nil
 }
 
-func (u *graphUsecase) Delete(c context.Context, id string, user domain.Profile) error {
-	graph, err := u.graphRepo.SelectOne(id)
+func (u *graphUsecase) Restore(c context.Context, id string, user domain.Profile) (*domain.Graph, error) {
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.checkDeleted(c, id, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+
+		if err := u.graphRepo.Restore(c, id); err != nil {
+			slog.Error("Restore graph error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return common.ErrInternalServerError
+		return nil, err
 	}
+	u.graphCache.InvalidateGraph(c, id)
 
-	if graph == nil || (user.Role == domain.RoleMember && user.Id != graph.UserId) {
-		return common.ErrNotFound
+	res, err := u.graphRepo.SelectOne(c, id)
+	if err != nil || res == nil {
+		return nil, common.ErrInternalServerError
 	}
+	return res, nil
+}
+
+func (u *graphUsecase) Clone(c context.Context, id string, name string, user domain.Profile) (*domain.Graph, error) {
+	var graphId *string
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// any graph the user reads may be cloned
+		origin, err := u.access.checkRead(c, id, user)
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			name = origin.Name
+		}
 
-	return u.graphRepo.Delete(id)
+		graphId, err = u.graphRepo.Clone(c, id, domain.Graph{
+			UserId:     user.Id,
+			Name:       name,
+			Visibility: domain.GraphVisibilityPrivate,
+			OriginId:   &origin.Id,
+		})
+		if err != nil {
+			slog.Error("Clone graph error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
+	res, err := u.graphRepo.SelectOne(c, *graphId)
+	if err != nil || res == nil {
+		return nil, common.ErrInternalServerError
+	}
+	return res, nil
 }
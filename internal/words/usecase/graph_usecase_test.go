@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/repository"
+)
+
+// A graph deleted by its creator, shared with an owner and an editor
+func newDeletedGraph(t *testing.T) (domain.GraphUsecase, string, map[string]domain.Profile) {
+	ctx := context.Background()
+	mem := datasource.InitMemory()
+	users := map[string]domain.Profile{}
+	for _, id := range []string{"creator", "owner", "editor", "stranger"} {
+		mem.Users[id] = &domain.User{Id: id}
+		users[id] = domain.Profile{Id: id}
+	}
+
+	graphRepo := repository.InitGraphMemoryRepository(mem)
+	graphId, err := graphRepo.Store(ctx, domain.Graph{
+		UserId:     "creator",
+		Name:       "private",
+		Visibility: domain.GraphVisibilityPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	memberRepo := repository.InitMemberMemoryRepository(mem)
+	for id, role := range map[string]domain.GraphRole{"owner": domain.GraphRoleOwner, "editor": domain.GraphRoleEditor} {
+		// invited, then accepted by the user
+		m := domain.GraphMember{
+			GraphId: *graphId,
+			Email:   id + "@example.com",
+			Role:    role,
+			Status:  domain.MemberStatusPending,
+		}
+		memberId, err := memberRepo.Store(ctx, m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.UserId = common.ToPointer(id)
+		m.Status = domain.MemberStatusAccepted
+		if err := memberRepo.Update(ctx, *memberId, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	u := InitGraphUsecase(
+		graphRepo,
+		memberRepo,
+		repository.InitShareMemoryRepository(mem),
+		repository.InitGraphDataCache(cache.Nop{}),
+		mem,
+	)
+	if err := u.Delete(ctx, *graphId, users["creator"]); err != nil {
+		t.Fatal(err)
+	}
+	return u, *graphId, users
+}
+
+func TestGraphRestoreChecksTheRole(t *testing.T) {
+	u, graphId, users := newDeletedGraph(t)
+
+	for name, want := range map[string]error{"stranger": common.ErrNotFound, "editor": common.ErrUnauthorization} {
+		if _, err := u.Restore(context.Background(), graphId, users[name]); !errors.Is(err, want) {
+			t.Fatalf("Restore() by the %s = %v, want %v", name, err, want)
+		}
+	}
+
+	g, err := u.Restore(context.Background(), graphId, users["owner"])
+	if err != nil || g.Id != graphId {
+		t.Fatalf("Restore() by an owner = %+v, %v", g, err)
+	}
+}
+
+func TestGraphListDeletedOfTheOwners(t *testing.T) {
+	u, graphId, users := newDeletedGraph(t)
+
+	for name, want := range map[string]int{"creator": 1, "owner": 1, "editor": 0, "stranger": 0} {
+		graphs, err := u.ListDeleted(context.Background(), users[name])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(graphs) != want || (want == 1 && graphs[0].Id != graphId) {
+			t.Fatalf("ListDeleted() of the %s = %+v, want %d graphs", name, graphs, want)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/exp/slog"
+)
+
+type importUsecase struct {
+	graphRepo  domain.GraphRepository
+	wordRepo   domain.WordRepository
+	linkRepo   domain.LinkRepository
+	graphCache domain.GraphCache
+	access     graphAccess
+	transactor domain.Transactor
+}
+
+func InitImportUsecase(
+	graphRepo domain.GraphRepository,
+	wordRepo domain.WordRepository,
+	linkRepo domain.LinkRepository,
+	memberRepo domain.MemberRepository,
+	graphCache domain.GraphCache,
+	transactor domain.Transactor,
+) domain.ImportUsecase {
+	return &importUsecase{
+		graphRepo:  graphRepo,
+		wordRepo:   wordRepo,
+		linkRepo:   linkRepo,
+		graphCache: graphCache,
+		access:     graphAccess{graphRepo: graphRepo, memberRepo: memberRepo},
+		transactor: transactor,
+	}
+}
+
+// Key a relationship whatever its direction
+func relationKey(w1Id string, w2Id string) [2]string {
+	if w1Id > w2Id {
+		return [2]string{w2Id, w1Id}
+	}
+	return [2]string{w1Id, w2Id}
+}
+
+func (u *importUsecase) Import(c context.Context, data domain.GraphExport, graphId string, user domain.Profile) (*domain.ImportResult, error) {
+	res := &domain.ImportResult{GraphId: graphId, Errors: []domain.ImportError{}}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if graphId == "" {
+			name := data.Graph.Name
+			if name == "" {
+				name = "Imported graph"
+			}
+			id, err := u.graphRepo.Store(c, domain.Graph{
+				UserId:     user.Id,
+				Name:       name,
+				Visibility: domain.GraphVisibilityPrivate,
+			})
+			if err != nil {
+				return common.InternalError(err)
+			}
+			res.GraphId = *id
+		} else if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+
+		// the words merge with the words the graph already has with the same content,
+		// the words of the file are all kept
+		words, relations, err := u.wordRepo.FindByGraphId(c, res.GraphId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		byContent := map[string]string{}
+		for _, w := range words {
+			byContent[strings.ToLower(w.Content)] = w.Id
+		}
+		related := map[[2]string]bool{}
+		for _, r := range relations {
+			related[relationKey(r.SourceId, r.TargetId)] = true
+		}
+
+		wordIds := map[string]string{}
+		for _, w := range data.Words {
+			key := strings.ToLower(w.Content)
+			if id, ok := byContent[key]; ok {
+				wordIds[w.Id] = id
+				res.MergedWords++
+				continue
+			}
+			id, err := u.wordRepo.Store(c, domain.Word{
+				UserId:      user.Id,
+				Content:     w.Content,
+				Description: w.Description,
+				Refs:        w.Refs,
+			}, res.GraphId, nil)
+			if err != nil {
+				slog.Error("Import word error", err)
+				return common.InternalError(err)
+			}
+			wordIds[w.Id] = *id
+			res.Words++
+		}
+
+		storeRelation := func(sourceId string, targetId string) error {
+			key := relationKey(sourceId, targetId)
+			if sourceId == "" || targetId == "" || sourceId == targetId || related[key] {
+				return nil
+			}
+			if err := u.wordRepo.StoreRelation(c, sourceId, targetId); err != nil {
+				return common.InternalError(err)
+			}
+			related[key] = true
+			res.Relations++
+			return nil
+		}
+		for _, r := range data.Relations {
+			if err := storeRelation(wordIds[r.SourceId], wordIds[r.TargetId]); err != nil {
+				return err
+			}
+		}
+
+		for _, l := range data.Links {
+			w1Id, w2Id := wordIds[l.Word1Id], wordIds[l.Word2Id]
+			if w1Id == "" || w2Id == "" || w1Id == w2Id {
+				continue
+			}
+			// a link annotates a relationship, which is created when missing
+			if err := storeRelation(w1Id, w2Id); err != nil {
+				return err
+			}
+			existing, err := u.linkRepo.FindByWordIds(c, w1Id, w2Id)
+			if err != nil {
+				return common.InternalError(err)
+			}
+			if existing != nil {
+				continue
+			}
+			_, err = u.linkRepo.Store(c, domain.Link{
+				UserId:      user.Id,
+				Word1Id:     w1Id,
+				Word2Id:     w2Id,
+				Content:     l.Content,
+				Description: l.Description,
+				Refs:        l.Refs,
+				CreatedAt:   time.Now(),
+			})
+			if err != nil {
+				return common.InternalError(err)
+			}
+			res.Links++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.graphCache.InvalidateGraphData(c, res.GraphId)
+	return res, nil
+}
@@ -2,103 +2,187 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/s2dio-tech/mindgra-backend/common"
 	"github.com/s2dio-tech/mindgra-backend/domain"
 )
 
 type linkUsecase struct {
-	linkRepo domain.LinkRepository
-	wordRepo domain.WordRepository
+	linkRepo     domain.LinkRepository
+	wordRepo     domain.WordRepository
+	revisionRepo domain.RevisionRepository
+	trashRepo    domain.TrashRepository
+	graphCache   domain.GraphCache
+	access       graphAccess
+	transactor   domain.Transactor
 }
 
-func InitLinkUsecase(repo domain.LinkRepository, wordRepo domain.WordRepository) domain.LinkUsecase {
+func InitLinkUsecase(repo domain.LinkRepository, wordRepo domain.WordRepository, graphRepo domain.GraphRepository, memberRepo domain.MemberRepository, shareRepo domain.ShareRepository, revisionRepo domain.RevisionRepository, trashRepo domain.TrashRepository, graphCache domain.GraphCache, transactor domain.Transactor) domain.LinkUsecase {
 	return &linkUsecase{
-		linkRepo: repo,
-		wordRepo: wordRepo,
+		linkRepo:     repo,
+		wordRepo:     wordRepo,
+		revisionRepo: revisionRepo,
+		trashRepo:    trashRepo,
+		graphCache:   graphCache,
+		access:       graphAccess{graphRepo: graphRepo, memberRepo: memberRepo, shareRepo: shareRepo},
+		transactor:   transactor,
 	}
 }
 
-func (u *linkUsecase) Create(c context.Context, w1Id string, w2Id string, link domain.Link, user domain.Profile) (*string, error) {
-	// validate that link word is existed or not
-	w1, err1 := u.wordRepo.FindById(w1Id)
-	w2, err2 := u.wordRepo.FindById(w2Id)
-	if err1 != nil || err2 != nil {
-		return nil, common.ErrInternalServerError
-	}
-	if w1 == nil || w2 == nil {
-		return nil, common.ErrBadParamInput
-	}
+func (u *linkUsecase) Create(c context.Context, w1Id string, w2Id string, link domain.Link, user domain.Profile) (res *string, err error) {
+	graphIds := []string{}
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// only one of the requests on the same words creates the link
+		if err := u.wordRepo.Lock(c, []string{w1Id, w2Id}); err != nil {
+			return common.InternalError(err)
+		}
 
-	// if link of two words is existed
-	// just update
-	r, err := u.linkRepo.FindByWordIds(w1Id, w2Id)
-	if err != nil {
-		return nil, common.ErrInternalServerError
-	}
-	if r != nil {
-		u.linkRepo.Update(r.Id, domain.Link{
+		// validate that link word is existed or not
+		w1, err1 := u.wordRepo.FindById(c, w1Id)
+		w2, err2 := u.wordRepo.FindById(c, w2Id)
+		if err1 != nil || err2 != nil {
+			return common.InternalError(errors.Join(err1, err2))
+		}
+		if w1 == nil || w2 == nil {
+			return common.ErrBadParamInput
+		}
+		if err := u.access.checkWords(c, []domain.Word{*w1, *w2}, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphIds = []string{w1.GraphId, w2.GraphId}
+
+		// if link of two words is existed
+		// just update
+		r, err := u.linkRepo.FindByWordIds(c, w1Id, w2Id)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if r != nil {
+			err = storeRevision(c, u.revisionRepo, linkRevision(*r, user), link.Content, link.Description, link.Refs)
+			if err != nil {
+				return err
+			}
+			err = u.linkRepo.Update(c, r.Id, domain.Link{
+				Content:     link.Content,
+				Description: link.Description,
+				Refs:        link.Refs,
+			})
+			if err != nil {
+				return common.InternalError(err)
+			}
+			res = &r.Id
+			return nil
+		}
+
+		// or not, create new
+		res, err = u.linkRepo.Store(c, domain.Link{
+			UserId:      user.Id,
+			Word1Id:     w1Id,
+			Word2Id:     w2Id,
 			Content:     link.Content,
 			Description: link.Description,
 			Refs:        link.Refs,
+			CreatedAt:   time.Now(),
 		})
-		return &r.Id, nil
-	}
-
-	// or not, create new
-	id, err := u.linkRepo.Store(domain.Link{
-		UserId:      user.Id,
-		Word1Id:     w1Id,
-		Word2Id:     w2Id,
-		Content:     link.Content,
-		Description: link.Description,
-		Refs:        link.Refs,
+		if err != nil {
+			return common.InternalError(err)
+		}
+		return nil
 	})
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, err
 	}
-	return id, nil
+	u.graphCache.InvalidateGraphData(c, graphIds...)
+	return res, nil
 }
 
 func (u *linkUsecase) Update(c context.Context, id string, link domain.Link, user domain.Profile) error {
-	r, err := u.linkRepo.FindById(id)
+	graphIds := []string{}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		r, err := u.linkRepo.FindById(c, id)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if r == nil {
+			return common.ErrNotFound
+		}
+		ws, err := u.wordRepo.FindByIds(c, []string{r.Word1Id, r.Word2Id})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if len(ws) != 2 {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, ws, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphIds = wordGraphIds(ws)
+
+		// the link is read again once its words are locked
+		if err := u.wordRepo.Lock(c, wordIds(ws)); err != nil {
+			return common.InternalError(err)
+		}
+		if r, err = u.linkRepo.FindById(c, id); err != nil {
+			return common.InternalError(err)
+		}
+		if r == nil {
+			return common.ErrNotFound
+		}
+		err = storeRevision(c, u.revisionRepo, linkRevision(*r, user), link.Content, link.Description, link.Refs)
+		if err != nil {
+			return err
+		}
+		if err := u.linkRepo.Update(c, id, link); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return common.ErrInternalServerError
-	}
-	if r == nil {
-		return common.ErrNotFound
-	}
-	if r.UserId != user.Id && user.Role == domain.RoleMember {
-		return common.ErrUnauthorization
+		return err
 	}
-
-	return u.linkRepo.Update(id, link)
+	u.graphCache.InvalidateGraphData(c, graphIds...)
+	return nil
 }
 
-func (u *linkUsecase) GetDetail(id string) (*domain.Link, error) {
-	r, err := u.linkRepo.FindById(id)
+func (u *linkUsecase) GetDetail(c context.Context, id string, user domain.Profile) (*domain.Link, error) {
+	r, err := u.linkRepo.FindById(c, id)
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
 	if r == nil {
 		return nil, common.ErrNotFound
 	}
+	ws, err := u.wordRepo.FindByIds(c, []string{r.Word1Id, r.Word2Id})
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if len(ws) != 2 {
+		return nil, common.ErrNotFound
+	}
+	if err := u.access.checkReadWords(c, ws, user); err != nil {
+		return nil, err
+	}
 	return r, nil
 }
 
-func (u *linkUsecase) GetDetailByWordIds(w1id string, w2id string) (*domain.Link, error) {
-	w1, err1 := u.wordRepo.FindById(w1id)
-	w2, err2 := u.wordRepo.FindById(w2id)
+func (u *linkUsecase) GetDetailByWordIds(c context.Context, w1id string, w2id string, user domain.Profile) (*domain.Link, error) {
+	w1, err1 := u.wordRepo.FindById(c, w1id)
+	w2, err2 := u.wordRepo.FindById(c, w2id)
 	if err1 != nil || err2 != nil {
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(errors.Join(err1, err2))
 	}
 	if w1 == nil || w2 == nil {
 		return nil, common.ErrNotFound
 	}
+	if err := u.access.checkReadWords(c, []domain.Word{*w1, *w2}, user); err != nil {
+		return nil, err
+	}
 
-	r, err := u.linkRepo.FindByWordIds(w1id, w2id)
+	r, err := u.linkRepo.FindByWordIds(c, w1id, w2id)
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
 	if r == nil {
 		return nil, common.ErrNotFound
@@ -107,16 +191,38 @@ func (u *linkUsecase) GetDetailByWordIds(w1id string, w2id string) (*domain.Link
 }
 
 func (u *linkUsecase) Delete(c context.Context, w1Id string, w2Id string, user domain.Profile) error {
-	ws, err := u.wordRepo.FindByIds([]string{w1Id, w2Id})
+	graphIds := []string{}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		ws, err := u.wordRepo.FindByIds(c, []string{w1Id, w2Id})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if len(ws) != 2 {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, ws, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphIds = wordGraphIds(ws)
+
+		// the relationship and its link are kept in the trash until they are purged
+		if err := u.trashRepo.TrashRelation(c, w1Id, w2Id, user.Id); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
 	if err != nil {
-		return common.ErrInternalServerError
+		return err
 	}
-	if len(ws) != 2 || ws[0].UserId != user.Id || ws[1].UserId != user.Id {
-		return common.ErrNotFound
+	u.graphCache.InvalidateGraphData(c, graphIds...)
+	return nil
+}
+
+// Get the graphs of the linked words
+func wordGraphIds(ws []domain.Word) []string {
+	graphIds := []string{}
+	for _, w := range ws {
+		graphIds = append(graphIds, w.GraphId)
 	}
-	// if user.Role == domain.RoleMember && r.UserId != user.Id {
-	// 	return common.ErrUnauthorization
-	// }
-	// return u.linkRepo.Delete(r.Id)
-	return u.linkRepo.Delete(w1Id, w2Id)
+	return graphIds
 }
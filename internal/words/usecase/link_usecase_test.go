@@ -0,0 +1,148 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/datasource/datasourcetest"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/repository"
+)
+
+// The links are read by the neo4j repository over a fake datasource,
+// the graph and its words are kept in memory
+type linkFixture struct {
+	mem      *datasource.Memory
+	neo4j    *datasourcetest.Fake
+	owner    domain.Profile
+	stranger domain.Profile
+	linkId   string
+}
+
+func newLinkFixture(t *testing.T) *linkFixture {
+	ctx := context.Background()
+	f := &linkFixture{
+		mem:      datasource.InitMemory(),
+		neo4j:    datasourcetest.NewFake(),
+		owner:    domain.Profile{Id: "owner"},
+		stranger: domain.Profile{Id: "stranger"},
+		linkId:   "l1",
+	}
+	for _, p := range []domain.Profile{f.owner, f.stranger} {
+		f.mem.Users[p.Id] = &domain.User{Id: p.Id}
+	}
+
+	graphId, err := repository.InitGraphMemoryRepository(f.mem).Store(ctx, domain.Graph{
+		UserId:     f.owner.Id,
+		Name:       "private",
+		Visibility: domain.GraphVisibilityPrivate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wordRepo := repository.InitWordMemoryRepository(f.mem)
+	w1, err := wordRepo.Store(ctx, domain.Word{UserId: f.owner.Id, Content: "rain"}, *graphId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w2, err := wordRepo.Store(ctx, domain.Word{UserId: f.owner.Id, Content: "flood"}, *graphId, w1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f.neo4j.Handlers["link.FindById"] = func(params map[string]any) []map[string]any {
+		if params["id"] != f.linkId {
+			return nil
+		}
+		return []map[string]any{{
+			"id":        f.linkId,
+			"word1Id":   *w1,
+			"word2Id":   *w2,
+			"userId":    f.owner.Id,
+			"content":   "causes",
+			"createdAt": neo4j.LocalDateTimeOf(time.Now()),
+		}}
+	}
+	return f
+}
+
+func (f *linkFixture) linkUsecase() domain.LinkUsecase {
+	return InitLinkUsecase(
+		repository.InitLinkRepository(f.neo4j),
+		repository.InitWordMemoryRepository(f.mem),
+		repository.InitGraphMemoryRepository(f.mem),
+		repository.InitMemberMemoryRepository(f.mem),
+		repository.InitShareMemoryRepository(f.mem),
+		repository.InitRevisionMemoryRepository(f.mem),
+		repository.InitTrashMemoryRepository(f.mem),
+		repository.InitGraphDataCache(cache.Nop{}),
+		f.mem,
+	)
+}
+
+func TestLinkUpdateChecksTheGraphOfTheWords(t *testing.T) {
+	f := newLinkFixture(t)
+	u := f.linkUsecase()
+	link := domain.Link{Content: "prevents"}
+
+	err := u.Update(context.Background(), f.linkId, link, f.stranger)
+	if !errors.Is(err, common.ErrNotFound) {
+		t.Fatalf("Update() by a stranger = %v, want %v", err, common.ErrNotFound)
+	}
+	if slices.Contains(f.neo4j.Queries, "link.Update") {
+		t.Fatal("Update() by a stranger wrote the link")
+	}
+
+	if err := u.Update(context.Background(), f.linkId, link, f.owner); err != nil {
+		t.Fatalf("Update() by the owner = %v", err)
+	}
+	if !slices.Contains(f.neo4j.Queries, "link.Update") {
+		t.Fatal("Update() by the owner didn't write the link")
+	}
+}
+
+func TestLinkUpdateOfMissingWords(t *testing.T) {
+	f := newLinkFixture(t)
+	f.neo4j.Handlers["link.FindById"] = func(params map[string]any) []map[string]any {
+		return []map[string]any{{"id": f.linkId, "userId": f.owner.Id, "content": "causes"}}
+	}
+
+	err := f.linkUsecase().Update(context.Background(), f.linkId, domain.Link{Content: "prevents"}, f.owner)
+	if !errors.Is(err, common.ErrNotFound) {
+		t.Fatalf("Update() = %v, want %v", err, common.ErrNotFound)
+	}
+}
+
+func TestLinkGetDetailOfAPrivateGraph(t *testing.T) {
+	f := newLinkFixture(t)
+	u := f.linkUsecase()
+
+	for name, user := range map[string]domain.Profile{"anonymous": {}, "stranger": f.stranger} {
+		if _, err := u.GetDetail(context.Background(), f.linkId, user); !errors.Is(err, common.ErrNotFound) {
+			t.Fatalf("GetDetail() by %s = %v, want %v", name, err, common.ErrNotFound)
+		}
+	}
+
+	l, err := u.GetDetail(context.Background(), f.linkId, f.owner)
+	if err != nil || l.Content != "causes" {
+		t.Fatalf("GetDetail() by the owner = %+v, %v", l, err)
+	}
+}
+
+func TestLinkGetDetailOfMissingWords(t *testing.T) {
+	f := newLinkFixture(t)
+	f.neo4j.Handlers["link.FindById"] = func(params map[string]any) []map[string]any {
+		return []map[string]any{{"id": f.linkId, "userId": f.owner.Id, "content": "causes"}}
+	}
+
+	if _, err := f.linkUsecase().GetDetail(context.Background(), f.linkId, f.owner); !errors.Is(err, common.ErrNotFound) {
+		t.Fatalf("GetDetail() = %v, want %v", err, common.ErrNotFound)
+	}
+}
@@ -0,0 +1,257 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/exp/slog"
+)
+
+type memberUsecase struct {
+	memberRepo   domain.MemberRepository
+	userRepo     domain.UserRepository
+	emailUsecase domain.EmailUsecase
+	access       graphAccess
+	transactor   domain.Transactor
+}
+
+func InitMemberUsecase(
+	repo domain.MemberRepository,
+	graphRepo domain.GraphRepository,
+	userRepo domain.UserRepository,
+	emailUsecase domain.EmailUsecase,
+	transactor domain.Transactor,
+) domain.MemberUsecase {
+	return &memberUsecase{
+		memberRepo:   repo,
+		userRepo:     userRepo,
+		emailUsecase: emailUsecase,
+		access:       graphAccess{graphRepo: graphRepo, memberRepo: repo},
+		transactor:   transactor,
+	}
+}
+
+// Get the account of the current user
+func (u *memberUsecase) currentUser(c context.Context, user domain.Profile) (*domain.User, error) {
+	me, err := u.userRepo.FindById(c, user.Id)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if me == nil {
+		return nil, common.ErrUnauthentication
+	}
+	return me, nil
+}
+
+// The invitations are addressed to the trimmed and lower-cased emails
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Hide the email but its first letter and its domain, "b***@example.com"
+func maskEmail(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at <= 0 {
+		return "***"
+	}
+	first := []rune(email[:at])[0]
+	return string(first) + "***" + email[at:]
+}
+
+// The owners see the emails of the members,
+// the other members only their own one
+func (u *memberUsecase) List(c context.Context, graphId string, user domain.Profile) ([]domain.GraphMember, error) {
+	graph, err := u.access.check(c, graphId, user, domain.GraphRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	role, err := u.access.role(c, *graph, user)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	members, err := u.memberRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if role.Includes(domain.GraphRoleOwner) {
+		return members, nil
+	}
+	for i, m := range members {
+		if m.UserId == nil || *m.UserId != user.Id {
+			members[i].Email = maskEmail(m.Email)
+		}
+	}
+	return members, nil
+}
+
+func (u *memberUsecase) ListInvitations(c context.Context, user domain.Profile) ([]domain.GraphMember, error) {
+	me, err := u.currentUser(c, user)
+	if err != nil {
+		return nil, err
+	}
+	members, err := u.memberRepo.FindPendingByEmail(c, normalizeEmail(me.Email))
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	return members, nil
+}
+
+func (u *memberUsecase) Invite(c context.Context, graphId string, email string, role domain.GraphRole, user domain.Profile) (res *string, err error) {
+	email = normalizeEmail(email)
+	var graph *domain.Graph
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		var err error
+		graph, err = u.access.check(c, graphId, user, domain.GraphRoleOwner)
+		if err != nil {
+			return err
+		}
+
+		// the creator of the graph is always its owner
+		creator, err := u.userRepo.FindById(c, graph.UserId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if creator != nil && normalizeEmail(creator.Email) == email {
+			return common.ErrConflict
+		}
+		m, err := u.memberRepo.FindByGraphIdAndEmail(c, graphId, email)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if m != nil {
+			return common.ErrConflict
+		}
+
+		res, err = u.memberRepo.Store(c, domain.GraphMember{
+			GraphId:   graphId,
+			Email:     email,
+			Role:      role,
+			Status:    domain.MemberStatusPending,
+			InvitedBy: user.Id,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// the invitation is kept when the email can't be sent,
+	// the invited user finds it in the pending invitations
+	if err := u.sendInvitation(c, *graph, email, user); err != nil {
+		slog.Error("Send invitation error", err)
+	}
+	return res, nil
+}
+
+func (u *memberUsecase) sendInvitation(c context.Context, graph domain.Graph, email string, user domain.Profile) error {
+	inviter, err := u.currentUser(c, user)
+	if err != nil {
+		return err
+	}
+	name := email
+	invited, err := u.userRepo.FindByEmail(c, email)
+	if err != nil {
+		return err
+	}
+	if invited != nil {
+		name = invited.Name
+	}
+
+	return u.emailUsecase.SendEmail(c, domain.Email{
+		From: domain.Contact{
+			Name:  common.AppConfig.AppName,
+			Email: common.AppConfig.AppEmail,
+		},
+		To: []domain.Contact{
+			{Name: name, Email: email},
+		},
+		Template: *u.emailUsecase.GetGraphInvitationMailTemplate(name, inviter.Name, graph.Name, graph.Id),
+	})
+}
+
+func (u *memberUsecase) Accept(c context.Context, graphId string, user domain.Profile) error {
+	me, err := u.currentUser(c, user)
+	if err != nil {
+		return err
+	}
+
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		m, err := u.memberRepo.FindByGraphIdAndEmail(c, graphId, normalizeEmail(me.Email))
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if m == nil {
+			return common.ErrNotFound
+		}
+		if m.Status == domain.MemberStatusAccepted {
+			return nil
+		}
+
+		m.UserId = &me.Id
+		m.Status = domain.MemberStatusAccepted
+		m.AcceptedAt = common.ToPointer(time.Now())
+		if err := u.memberRepo.Update(c, m.Id, *m); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
+
+func (u *memberUsecase) ChangeRole(c context.Context, graphId string, memberId string, role domain.GraphRole, user domain.Profile) error {
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+
+		m, err := u.memberRepo.FindById(c, memberId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if m == nil || m.GraphId != graphId {
+			return common.ErrNotFound
+		}
+
+		m.Role = role
+		if err := u.memberRepo.Update(c, m.Id, *m); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
+
+func (u *memberUsecase) Remove(c context.Context, graphId string, memberId string, user domain.Profile) error {
+	me, err := u.currentUser(c, user)
+	if err != nil {
+		return err
+	}
+
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		m, err := u.memberRepo.FindById(c, memberId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if m == nil || m.GraphId != graphId {
+			return common.ErrNotFound
+		}
+
+		// members may leave the graph or decline the invitation,
+		// the others are removed by the owners
+		if m.Email != normalizeEmail(me.Email) {
+			if _, err := u.access.check(c, graphId, user, domain.GraphRoleOwner); err != nil {
+				return err
+			}
+		}
+
+		if err := u.memberRepo.Delete(c, m.Id); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	userRepository "github.com/s2dio-tech/mindgra-backend/internal/users/repository"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/repository"
+)
+
+// emailUsecase sends no email
+type emailUsecase struct {
+	domain.EmailUsecase
+}
+
+func (emailUsecase) SendEmail(c context.Context, e domain.Email) error {
+	return nil
+}
+
+func (emailUsecase) GetGraphInvitationMailTemplate(name string, inviterName string, graphName string, graphId string) *domain.EmailTemplate {
+	return &domain.EmailTemplate{}
+}
+
+// A graph of the user "owner", with the accounts of "editor" and "bob"
+func newMemberFixture(t *testing.T) (domain.MemberUsecase, string) {
+	if common.AppConfig == nil {
+		common.AppConfig = &common.Configuration{}
+	}
+	mem := datasource.InitMemory()
+	for id, email := range map[string]string{"owner": "owner@x.com", "editor": "editor@x.com", "bob": "Bob@x.com"} {
+		mem.Users[id] = &domain.User{Id: id, Name: id, Email: email}
+	}
+	graphRepo := repository.InitGraphMemoryRepository(mem)
+	graphId, err := graphRepo.Store(context.Background(), domain.Graph{UserId: "owner", Name: "rain", Visibility: domain.GraphVisibilityPrivate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u := InitMemberUsecase(
+		repository.InitMemberMemoryRepository(mem),
+		graphRepo,
+		userRepository.InitUserMemoryRepository(mem),
+		emailUsecase{},
+		mem,
+	)
+	return u, *graphId
+}
+
+func TestMemberInviteNormalizesTheEmail(t *testing.T) {
+	ctx := context.Background()
+	u, graphId := newMemberFixture(t)
+	owner := domain.Profile{Id: "owner"}
+
+	if _, err := u.Invite(ctx, graphId, " Bob@X.com ", domain.GraphRoleViewer, owner); err != nil {
+		t.Fatal(err)
+	}
+	for _, email := range []string{"bob@x.com", "BOB@x.com", " Owner@x.com"} {
+		if _, err := u.Invite(ctx, graphId, email, domain.GraphRoleEditor, owner); !errors.Is(err, common.ErrConflict) {
+			t.Errorf("Invite(%q) = %v, want a conflict", email, err)
+		}
+	}
+
+	// found by the account whatever the case of its email
+	bob := domain.Profile{Id: "bob"}
+	invitations, err := u.ListInvitations(ctx, bob)
+	if err != nil || len(invitations) != 1 || invitations[0].Email != "bob@x.com" {
+		t.Fatalf("ListInvitations() = %+v, %v", invitations, err)
+	}
+	if err := u.Accept(ctx, graphId, bob); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMemberListMasksTheEmails(t *testing.T) {
+	ctx := context.Background()
+	u, graphId := newMemberFixture(t)
+	owner := domain.Profile{Id: "owner"}
+	for _, email := range []string{"editor@x.com", "bob@x.com", "carol@x.com"} {
+		if _, err := u.Invite(ctx, graphId, email, domain.GraphRoleEditor, owner); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, id := range []string{"editor", "bob"} {
+		if err := u.Accept(ctx, graphId, domain.Profile{Id: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, test := range []struct {
+		user string
+		want map[string]bool
+	}{
+		{"owner", map[string]bool{"editor@x.com": true, "bob@x.com": true, "carol@x.com": true}},
+		// their own email and the masked ones of the others
+		{"editor", map[string]bool{"editor@x.com": true, "b***@x.com": true, "c***@x.com": true}},
+	} {
+		members, err := u.List(ctx, graphId, domain.Profile{Id: test.user})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := map[string]bool{}
+		for _, m := range members {
+			got[m.Email] = true
+		}
+		if !maps.Equal(got, test.want) {
+			t.Errorf("List() by the %s = %v, want %v", test.user, got, test.want)
+		}
+	}
+
+	if _, err := u.List(ctx, graphId, domain.Profile{Id: "stranger"}); !errors.Is(err, common.ErrNotFound) {
+		t.Fatalf("List() by a stranger = %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/exp/slog"
+)
+
+type revisionUsecase struct {
+	revisionRepo domain.RevisionRepository
+	wordRepo     domain.WordRepository
+	linkRepo     domain.LinkRepository
+	graphCache   domain.GraphCache
+	access       graphAccess
+	transactor   domain.Transactor
+}
+
+func InitRevisionUsecase(
+	repo domain.RevisionRepository,
+	wordRepo domain.WordRepository,
+	linkRepo domain.LinkRepository,
+	graphRepo domain.GraphRepository,
+	memberRepo domain.MemberRepository,
+	shareRepo domain.ShareRepository,
+	graphCache domain.GraphCache,
+	transactor domain.Transactor,
+) domain.RevisionUsecase {
+	return &revisionUsecase{
+		revisionRepo: repo,
+		wordRepo:     wordRepo,
+		linkRepo:     linkRepo,
+		graphCache:   graphCache,
+		access:       graphAccess{graphRepo: graphRepo, memberRepo: memberRepo, shareRepo: shareRepo},
+		transactor:   transactor,
+	}
+}
+
+// Keep the values of a word before the user updates it
+func wordRevision(w domain.Word, user domain.Profile) domain.Revision {
+	return domain.Revision{
+		EntityType:  domain.RevisionEntityWord,
+		EntityId:    w.Id,
+		UserId:      user.Id,
+		Content:     w.Content,
+		Description: w.Description,
+		Refs:        w.Refs,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Keep the values of a link before the user updates it
+func linkRevision(l domain.Link, user domain.Profile) domain.Revision {
+	return domain.Revision{
+		EntityType:  domain.RevisionEntityLink,
+		EntityId:    l.Id,
+		UserId:      user.Id,
+		Content:     l.Content,
+		Description: l.Description,
+		Refs:        l.Refs,
+		CreatedAt:   time.Now(),
+	}
+}
+
+// Tell whether an update changes the values of the revision
+func sameRevisionValues(r domain.Revision, content string, description *string, refs *[]string) bool {
+	return r.Content == content &&
+		stringValue(r.Description) == stringValue(description) &&
+		equalRefs(r.Refs, refs)
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func equalRefs(a *[]string, b *[]string) bool {
+	var as, bs []string
+	if a != nil {
+		as = *a
+	}
+	if b != nil {
+		bs = *b
+	}
+	if len(as) != len(bs) {
+		return false
+	}
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Store the revision unless the update keeps its values
+func storeRevision(c context.Context, repo domain.RevisionRepository, r domain.Revision, content string, description *string, refs *[]string) error {
+	if sameRevisionValues(r, content, description, refs) {
+		return nil
+	}
+	if _, err := repo.Store(c, r); err != nil {
+		slog.Error("Store revision error", err)
+		return common.InternalError(err)
+	}
+	return nil
+}
+
+// Get the current values of the entity as a revision,
+// with the words telling the graphs it belongs to
+func (u *revisionUsecase) current(c context.Context, entityType domain.RevisionEntity, entityId string) (*domain.Revision, []domain.Word, error) {
+	switch entityType {
+	case domain.RevisionEntityWord:
+		w, err := u.wordRepo.FindById(c, entityId)
+		if err != nil {
+			return nil, nil, common.InternalError(err)
+		}
+		if w == nil {
+			return nil, nil, common.ErrNotFound
+		}
+		r := wordRevision(*w, domain.Profile{Id: w.UserId})
+		return &r, []domain.Word{*w}, nil
+	case domain.RevisionEntityLink:
+		l, err := u.linkRepo.FindById(c, entityId)
+		if err != nil {
+			return nil, nil, common.InternalError(err)
+		}
+		if l == nil {
+			return nil, nil, common.ErrNotFound
+		}
+		ws, err := u.wordRepo.FindByIds(c, []string{l.Word1Id, l.Word2Id})
+		if err != nil {
+			return nil, nil, common.InternalError(err)
+		}
+		if len(ws) != 2 {
+			return nil, nil, common.ErrNotFound
+		}
+		r := linkRevision(*l, domain.Profile{Id: l.UserId})
+		return &r, ws, nil
+	}
+	return nil, nil, common.ErrNotFound
+}
+
+// Get a revision of the entity, or its current values
+func (u *revisionUsecase) revision(c context.Context, current *domain.Revision, id string) (*domain.Revision, error) {
+	if id == domain.RevisionCurrent {
+		return current, nil
+	}
+	r, err := u.revisionRepo.FindById(c, id)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	// the revisions of other entities are not found
+	if r == nil || r.EntityType != current.EntityType || r.EntityId != current.EntityId {
+		return nil, common.ErrNotFound
+	}
+	return r, nil
+}
+
+func (u *revisionUsecase) List(c context.Context, entityType domain.RevisionEntity, entityId string, user domain.Profile) ([]domain.Revision, error) {
+	_, ws, err := u.current(c, entityType, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.access.checkReadWords(c, ws, user); err != nil {
+		return nil, err
+	}
+
+	revisions, err := u.revisionRepo.FindByEntity(c, entityType, entityId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	return revisions, nil
+}
+
+func (u *revisionUsecase) Diff(c context.Context, entityType domain.RevisionEntity, entityId string, fromId string, toId string, user domain.Profile) (*domain.RevisionDiff, error) {
+	current, ws, err := u.current(c, entityType, entityId)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.access.checkReadWords(c, ws, user); err != nil {
+		return nil, err
+	}
+
+	from, err := u.revision(c, current, fromId)
+	if err != nil {
+		return nil, err
+	}
+	to, err := u.revision(c, current, toId)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.RevisionDiff{
+		From:    fromId,
+		To:      toId,
+		Changes: diffRevisions(*from, *to),
+	}, nil
+}
+
+func (u *revisionUsecase) Revert(c context.Context, entityType domain.RevisionEntity, entityId string, revisionId string, user domain.Profile) error {
+	graphIds := []string{}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		_, ws, err := u.current(c, entityType, entityId)
+		if err != nil {
+			return err
+		}
+		// the values are read again once locked
+		if err := u.wordRepo.Lock(c, wordIds(ws)); err != nil {
+			return common.InternalError(err)
+		}
+		current, ws, err := u.current(c, entityType, entityId)
+		if err != nil {
+			return err
+		}
+		if err := u.access.checkWords(c, ws, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphIds = wordGraphIds(ws)
+
+		r, err := u.revision(c, current, revisionId)
+		if err != nil {
+			return err
+		}
+		// the current values are kept, so the revert may be reverted too
+		current.UserId = user.Id
+		current.CreatedAt = time.Now()
+		if err := storeRevision(c, u.revisionRepo, *current, r.Content, r.Description, r.Refs); err != nil {
+			return err
+		}
+
+		if entityType == domain.RevisionEntityWord {
+			err = u.wordRepo.Update(c, domain.Word{
+				Id:          entityId,
+				Content:     r.Content,
+				Description: r.Description,
+				Refs:        r.Refs,
+			})
+		} else {
+			err = u.linkRepo.Update(c, entityId, domain.Link{
+				Content:     r.Content,
+				Description: r.Description,
+				Refs:        r.Refs,
+			})
+		}
+		if err != nil {
+			slog.Error("Revert error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u.graphCache.InvalidateGraphData(c, graphIds...)
+	return nil
+}
+
+// List the fields which differ between two revisions
+func diffRevisions(from domain.Revision, to domain.Revision) []domain.RevisionChange {
+	changes := []domain.RevisionChange{}
+	if from.Content != to.Content {
+		changes = append(changes, domain.RevisionChange{Field: "content", From: from.Content, To: to.Content})
+	}
+	if stringValue(from.Description) != stringValue(to.Description) {
+		changes = append(changes, domain.RevisionChange{Field: "description", From: from.Description, To: to.Description})
+	}
+	if !equalRefs(from.Refs, to.Refs) {
+		change := domain.RevisionChange{Field: "refs", From: from.Refs, To: to.Refs}
+		fromRefs, toRefs := map[string]bool{}, map[string]bool{}
+		if from.Refs != nil {
+			for _, ref := range *from.Refs {
+				fromRefs[ref] = true
+			}
+		}
+		if to.Refs != nil {
+			for _, ref := range *to.Refs {
+				toRefs[ref] = true
+				if !fromRefs[ref] {
+					change.Added = append(change.Added, ref)
+				}
+			}
+		}
+		if from.Refs != nil {
+			for _, ref := range *from.Refs {
+				if !toRefs[ref] {
+					change.Removed = append(change.Removed, ref)
+				}
+			}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// Get the ids of the words
+func wordIds(ws []domain.Word) []string {
+	ids := []string{}
+	for _, w := range ws {
+		ids = append(ids, w.Id)
+	}
+	return ids
+}
@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/repository"
+)
+
+func (f *linkFixture) revisionUsecase() domain.RevisionUsecase {
+	return InitRevisionUsecase(
+		repository.InitRevisionMemoryRepository(f.mem),
+		repository.InitWordMemoryRepository(f.mem),
+		repository.InitLinkRepository(f.neo4j),
+		repository.InitGraphMemoryRepository(f.mem),
+		repository.InitMemberMemoryRepository(f.mem),
+		repository.InitShareMemoryRepository(f.mem),
+		repository.InitGraphDataCache(cache.Nop{}),
+		f.mem,
+	)
+}
+
+func TestLinkRevisionsListDiffRevert(t *testing.T) {
+	ctx := context.Background()
+	f := newLinkFixture(t)
+	if err := f.linkUsecase().Update(ctx, f.linkId, domain.Link{Content: "prevents"}, f.owner); err != nil {
+		t.Fatal(err)
+	}
+	u := f.revisionUsecase()
+
+	revisions, err := u.List(ctx, domain.RevisionEntityLink, f.linkId, f.owner)
+	if err != nil {
+		t.Fatalf("List() = %v", err)
+	}
+	if len(revisions) != 1 || revisions[0].Content != "causes" {
+		t.Fatalf("List() = %+v, want the revision of the replaced content", revisions)
+	}
+
+	diff, err := u.Diff(ctx, domain.RevisionEntityLink, f.linkId, revisions[0].Id, domain.RevisionCurrent, f.owner)
+	if err != nil {
+		t.Fatalf("Diff() = %v", err)
+	}
+	if diff == nil {
+		t.Fatal("Diff() = nil")
+	}
+
+	f.neo4j.Queries = nil
+	if err := u.Revert(ctx, domain.RevisionEntityLink, f.linkId, revisions[0].Id, f.owner); err != nil {
+		t.Fatalf("Revert() = %v", err)
+	}
+	if !slices.Contains(f.neo4j.Queries, "link.Update") {
+		t.Fatal("Revert() didn't write the link")
+	}
+
+	if _, err := u.List(ctx, domain.RevisionEntityLink, f.linkId, f.stranger); err == nil {
+		t.Fatal("List() by a stranger succeeded")
+	}
+}
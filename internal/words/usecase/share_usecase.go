@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type shareUsecase struct {
+	shareRepo  domain.ShareRepository
+	access     graphAccess
+	transactor domain.Transactor
+}
+
+func InitShareUsecase(
+	repo domain.ShareRepository,
+	graphRepo domain.GraphRepository,
+	memberRepo domain.MemberRepository,
+	transactor domain.Transactor,
+) domain.ShareUsecase {
+	return &shareUsecase{
+		shareRepo:  repo,
+		access:     graphAccess{graphRepo: graphRepo, memberRepo: memberRepo, shareRepo: repo},
+		transactor: transactor,
+	}
+}
+
+func (u *shareUsecase) List(c context.Context, graphId string, user domain.Profile) ([]domain.ShareLink, error) {
+	if _, err := u.access.check(c, graphId, user, domain.GraphRoleOwner); err != nil {
+		return nil, err
+	}
+	shares, err := u.shareRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	return shares, nil
+}
+
+func (u *shareUsecase) Create(c context.Context, graphId string, share domain.ShareLink, password *string, user domain.Profile) (res *domain.ShareLink, token string, err error) {
+	if share.ExpiresAt != nil && !share.ExpiresAt.After(time.Now()) {
+		return nil, "", common.ErrBadParamInput
+	}
+
+	// only the hashes are stored, the token is given back once
+	token = common.NewSecretToken(32)
+	share.GraphId = graphId
+	share.TokenHash = common.HashToken(token)
+	share.CreatedBy = user.Id
+	share.CreatedAt = time.Now()
+	if password != nil && *password != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
+		if err != nil {
+			return nil, "", common.ErrInternalServerError
+		}
+		share.PasswordHash = common.ToPointer(string(hash))
+	}
+
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+		id, err := u.shareRepo.Store(c, share)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		share.Id = *id
+		return nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &share, token, nil
+}
+
+func (u *shareUsecase) Revoke(c context.Context, graphId string, id string, user domain.Profile) error {
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleOwner); err != nil {
+			return err
+		}
+
+		s, err := u.shareRepo.FindById(c, id)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if s == nil || s.GraphId != graphId {
+			return common.ErrNotFound
+		}
+
+		if err := u.shareRepo.Delete(c, s.Id); err != nil {
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"golang.org/x/exp/slog"
+)
+
+type trashUsecase struct {
+	trashRepo  domain.TrashRepository
+	wordRepo   domain.WordRepository
+	graphCache domain.GraphCache
+	access     graphAccess
+	transactor domain.Transactor
+}
+
+func InitTrashUsecase(
+	repo domain.TrashRepository,
+	wordRepo domain.WordRepository,
+	graphRepo domain.GraphRepository,
+	memberRepo domain.MemberRepository,
+	shareRepo domain.ShareRepository,
+	graphCache domain.GraphCache,
+	transactor domain.Transactor,
+) domain.TrashUsecase {
+	return &trashUsecase{
+		trashRepo:  repo,
+		wordRepo:   wordRepo,
+		graphCache: graphCache,
+		access:     graphAccess{graphRepo: graphRepo, memberRepo: memberRepo, shareRepo: shareRepo},
+		transactor: transactor,
+	}
+}
+
+// Get a word of the trash of the graph
+func (u *trashUsecase) trashedWord(c context.Context, graphId string, id string) (*domain.TrashedWord, error) {
+	w, err := u.trashRepo.FindWordById(c, id)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	if w == nil || w.GraphId != graphId {
+		return nil, common.ErrNotFound
+	}
+	return w, nil
+}
+
+// Tell whether a relationship between the words
+// is in the trash of the graph
+func (u *trashUsecase) hasRelation(c context.Context, graphId string, w1Id string, w2Id string) (bool, error) {
+	trash, err := u.trashRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		return false, common.InternalError(err)
+	}
+	for _, r := range trash.Relations {
+		if (r.SourceId == w1Id && r.TargetId == w2Id) || (r.SourceId == w2Id && r.TargetId == w1Id) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (u *trashUsecase) List(c context.Context, graphId string, user domain.Profile) (*domain.Trash, error) {
+	if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+		return nil, err
+	}
+
+	trash, err := u.trashRepo.FindByGraphId(c, graphId)
+	if err != nil {
+		slog.Error("List trash error", err)
+		return nil, common.InternalError(err)
+	}
+	return trash, nil
+}
+
+func (u *trashUsecase) RestoreWord(c context.Context, graphId string, id string, user domain.Profile) error {
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		if _, err := u.trashedWord(c, graphId, id); err != nil {
+			return err
+		}
+
+		if err := u.trashRepo.RestoreWord(c, id); err != nil {
+			slog.Error("Restore word error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u.graphCache.InvalidateGraphData(c, graphId)
+	return nil
+}
+
+func (u *trashUsecase) RestoreRelation(c context.Context, graphId string, w1Id string, w2Id string, user domain.Profile) error {
+	graphIds := []string{}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		// concurrent links of the same words wait for the restore
+		if err := u.wordRepo.Lock(c, []string{w1Id, w2Id}); err != nil {
+			return common.InternalError(err)
+		}
+		found, err := u.hasRelation(c, graphId, w1Id, w2Id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return common.ErrNotFound
+		}
+		// both words must be out of the trash
+		ws, err := u.wordRepo.FindByIds(c, []string{w1Id, w2Id})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if len(ws) != 2 {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, ws, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphIds = wordGraphIds(ws)
+
+		// the words were linked again since
+		neighbors, err := u.wordRepo.FindNeighborIds(c, w1Id, 1)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		for _, n := range neighbors {
+			if (n.SourceId == w1Id && n.TargetId == w2Id) || (n.SourceId == w2Id && n.TargetId == w1Id) {
+				return common.ErrConflict
+			}
+		}
+
+		restored, err := u.trashRepo.RestoreRelation(c, w1Id, w2Id)
+		if err != nil {
+			slog.Error("Restore relation error", err)
+			return common.InternalError(err)
+		}
+		if !restored {
+			return common.ErrNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u.graphCache.InvalidateGraphData(c, graphIds...)
+	return nil
+}
+
+func (u *trashUsecase) PurgeWord(c context.Context, graphId string, id string, user domain.Profile) error {
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		if _, err := u.trashedWord(c, graphId, id); err != nil {
+			return err
+		}
+
+		if err := u.trashRepo.DeleteWord(c, id); err != nil {
+			slog.Error("Purge word error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
+
+func (u *trashUsecase) PurgeRelation(c context.Context, graphId string, w1Id string, w2Id string, user domain.Profile) error {
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		found, err := u.hasRelation(c, graphId, w1Id, w2Id)
+		if err != nil {
+			return err
+		}
+		if !found {
+			return common.ErrNotFound
+		}
+
+		if err := u.trashRepo.DeleteRelation(c, w1Id, w2Id); err != nil {
+			slog.Error("Purge relation error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+}
+
+func (u *trashUsecase) Empty(c context.Context, graphId string, user domain.Profile) error {
+	return u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+
+		trash, err := u.trashRepo.FindByGraphId(c, graphId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		for _, r := range trash.Relations {
+			if err := u.trashRepo.DeleteRelation(c, r.SourceId, r.TargetId); err != nil {
+				slog.Error("Empty trash error", err)
+				return common.InternalError(err)
+			}
+		}
+		for _, w := range trash.Words {
+			if err := u.trashRepo.DeleteWord(c, w.Id); err != nil {
+				slog.Error("Empty trash error", err)
+				return common.InternalError(err)
+			}
+		}
+		return nil
+	})
+}
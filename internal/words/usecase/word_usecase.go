@@ -2,6 +2,9 @@ package usecase
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
 	"time"
 
 	"github.com/s2dio-tech/mindgra-backend/common"
@@ -10,23 +13,41 @@ import (
 )
 
 type wordUsecase struct {
-	wordRepo  domain.WordRepository
-	graphRepo domain.GraphRepository
+	wordRepo     domain.WordRepository
+	graphRepo    domain.GraphRepository
+	revisionRepo domain.RevisionRepository
+	trashRepo    domain.TrashRepository
+	wordIndex    domain.WordIndex
+	similarity   domain.WordSimilarity
+	graphCache   domain.GraphCache
+	access       graphAccess
+	transactor   domain.Transactor
 }
 
-func InitWordUsecase(repo domain.WordRepository, spRepo domain.GraphRepository) domain.WordUsecase {
+func InitWordUsecase(repo domain.WordRepository, spRepo domain.GraphRepository, memberRepo domain.MemberRepository, shareRepo domain.ShareRepository, revisionRepo domain.RevisionRepository, trashRepo domain.TrashRepository, wordIndex domain.WordIndex, similarity domain.WordSimilarity, graphCache domain.GraphCache, transactor domain.Transactor) domain.WordUsecase {
 	return &wordUsecase{
-		wordRepo:  repo,
-		graphRepo: spRepo,
+		wordRepo:     repo,
+		graphRepo:    spRepo,
+		revisionRepo: revisionRepo,
+		trashRepo:    trashRepo,
+		wordIndex:    wordIndex,
+		similarity:   similarity,
+		graphCache:   graphCache,
+		access:       graphAccess{graphRepo: spRepo, memberRepo: memberRepo, shareRepo: shareRepo},
+		transactor:   transactor,
 	}
 }
 
-func (u *wordUsecase) GetGraphData(c context.Context, graphId string) (data *domain.WordsGraphData, err error) {
-	ws, ls, err := u.wordRepo.FindByGraphId(graphId)
+func (u *wordUsecase) GetGraphData(c context.Context, graphId string, user domain.Profile) (data *domain.WordsGraphData, err error) {
+	if _, err := u.access.checkRead(c, graphId, user); err != nil {
+		return nil, err
+	}
+
+	ws, ls, err := u.wordRepo.FindByGraphId(c, graphId)
 
 	if err != nil {
 		slog.Error("GetGraphData error", err)
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
 
 	return &domain.WordsGraphData{
@@ -35,106 +56,354 @@ func (u *wordUsecase) GetGraphData(c context.Context, graphId string) (data *dom
 	}, nil
 }
 
-func (u *wordUsecase) SearchWord(c context.Context, text string) ([]domain.Word, error) {
-	res, err := u.wordRepo.FindByContentOrDescription(text, 10)
+// length in runes of the highlighted descriptions
+const searchSnippetSize = 160
+
+// The cursor is the position in the results of the first hit of the next page
+func encodeSearchCursor(position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(position)))
+}
+
+func decodeSearchCursor(cursor string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
 	if err != nil {
-		slog.Error("FindByContentOrDescription error", err)
-		return nil, common.ErrInternalServerError
+		return 0, common.ErrBadParamInput
 	}
-	return res, nil
+	position, err := strconv.Atoi(string(b))
+	if err != nil || position < 0 {
+		return 0, common.ErrBadParamInput
+	}
+	return position, nil
 }
 
-func (u *wordUsecase) GetWordById(c context.Context, id string) (*domain.Word, error) {
-	return u.wordRepo.FindById(id)
+// Graphs to search in, the graph of the search,
+// the graphs of the user or every graph the user finds in the search results
+func (u *wordUsecase) searchGraphs(c context.Context, search domain.WordSearch, user domain.Profile) (domain.WordSearchGraphs, error) {
+	if search.GraphId != "" {
+		if _, err := u.access.checkRead(c, search.GraphId, user); err != nil {
+			return domain.WordSearchGraphs{}, err
+		}
+		return domain.WordSearchGraphs{Ids: []string{search.GraphId}}, nil
+	}
+	if search.Scope != domain.SearchScopeMine {
+		return u.access.searchable(c, user)
+	}
+	if user.Id == "" {
+		return domain.WordSearchGraphs{}, common.ErrUnauthorization
+	}
+	graphs, err := u.graphRepo.Select(c, user.Id)
+	if err != nil {
+		return domain.WordSearchGraphs{}, common.InternalError(err)
+	}
+	ids := []string{}
+	for _, g := range graphs {
+		ids = append(ids, g.Id)
+	}
+	return domain.WordSearchGraphs{Ids: ids}, nil
 }
 
-func (u *wordUsecase) Create(c context.Context, word domain.Word, graphId string, user domain.Profile) (res *string, err error) {
-	sp, err := u.graphRepo.SelectOne(graphId)
+func (u *wordUsecase) SearchWord(c context.Context, search domain.WordSearch, user domain.Profile) (*domain.WordSearchPage, error) {
+	graphs, err := u.searchGraphs(c, search, user)
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, err
 	}
-
-	if sp == nil || (user.Role == domain.RoleMember && user.Id != sp.UserId) {
-		return nil, common.ErrNotFound
+	page := &domain.WordSearchPage{Results: []domain.WordSearchHit{}, Limit: search.Limit}
+	if !graphs.All && !graphs.Public && len(graphs.Ids) == 0 {
+		return page, nil
 	}
 
-	// insert to db
-	w := &word
-	w.CreatedAt = common.ToPointer(time.Now())
-	wId, err := u.wordRepo.Store(*w, graphId, nil)
+	position := search.Offset
+	if search.Cursor != "" {
+		if position, err = decodeSearchCursor(search.Cursor); err != nil {
+			return nil, err
+		}
+	}
+	// one more hit tells there is a next page
+	hits, err := u.wordRepo.Search(c, search.Text, graphs, position, search.Limit+1)
 	if err != nil {
-		slog.Error("Create error", err)
-		return nil, common.ErrInternalServerError
+		slog.Error("Search error", err)
+		return nil, common.InternalError(err)
+	}
+	if len(hits) > search.Limit {
+		hits = hits[:search.Limit]
+		page.NextCursor = encodeSearchCursor(position + search.Limit)
 	}
+	page.Results = hits
 
-	return wId, nil
+	terms := common.Tokenize(search.Text)
+	for i, h := range page.Results {
+		highlights := map[string]string{}
+		if s, ok := common.Highlight(h.Content, terms, 0); ok {
+			highlights["content"] = s
+		}
+		if h.Description != nil {
+			if s, ok := common.Highlight(*h.Description, terms, searchSnippetSize); ok {
+				highlights["description"] = s
+			}
+		}
+		page.Results[i].Highlights = highlights
+	}
+	return page, nil
 }
 
-func (u *wordUsecase) CreateWordWithLink(c context.Context, word domain.Word, linkWordId string, graphId string, user domain.Profile) (res *string, err error) {
-	sp, err := u.graphRepo.SelectOne(graphId)
-	if err != nil {
-		return nil, common.ErrInternalServerError
+func (u *wordUsecase) Suggest(c context.Context, graphId string, text string, limit int, user domain.Profile) ([]domain.WordSuggestion, error) {
+	if _, err := u.access.checkRead(c, graphId, user); err != nil {
+		return nil, err
 	}
 
-	if sp == nil || (user.Role == domain.RoleMember && user.Id != sp.UserId) {
-		return nil, common.ErrNotFound
+	res, err := u.wordIndex.Suggest(c, graphId, text, limit)
+	if err != nil {
+		slog.Error("Suggest error", err)
+		return nil, common.InternalError(err)
 	}
+	return res, nil
+}
 
-	// validate that link word is existed or not
-	joinWord, err := u.wordRepo.FindById(linkWordId)
+func (u *wordUsecase) Similar(c context.Context, id string, limit int, user domain.Profile) ([]domain.SimilarWord, error) {
+	w, err := u.wordRepo.FindById(c, id)
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
-	if joinWord == nil {
+	if w == nil {
 		return nil, common.ErrNotFound
 	}
+	if err := u.access.checkReadWords(c, []domain.Word{*w}, user); err != nil {
+		return nil, err
+	}
 
-	wId, err := u.wordRepo.Store(word, graphId, &linkWordId)
+	// the words already linked to it are left out
+	links, err := u.wordRepo.FindNeighborIds(c, id, 1)
+	if err != nil {
+		return nil, common.InternalError(err)
+	}
+	linked := []string{}
+	for _, l := range links {
+		linked = append(linked, l.SourceId, l.TargetId)
+	}
+	res, err := u.similarity.Similar(c, w.GraphId, *w, linked, limit)
 	if err != nil {
-		return nil, common.ErrInternalServerError
+		slog.Error("Similar error", err)
+		return nil, common.InternalError(err)
 	}
+	return res, nil
+}
 
-	return wId, nil
+func (u *wordUsecase) SimilarToDraft(c context.Context, graphId string, w domain.Word, limit int, user domain.Profile) ([]domain.SimilarWord, error) {
+	if _, err := u.access.checkRead(c, graphId, user); err != nil {
+		return nil, err
+	}
+
+	w.Id = ""
+	res, err := u.similarity.Similar(c, graphId, w, nil, limit)
+	if err != nil {
+		slog.Error("SimilarToDraft error", err)
+		return nil, common.InternalError(err)
+	}
+	return res, nil
 }
 
-func (u *wordUsecase) Update(c context.Context, id string, word domain.Word) error {
-	w, err := u.wordRepo.FindById(id)
+func (u *wordUsecase) GetWordById(c context.Context, id string, user domain.Profile) (*domain.Word, error) {
+	w, err := u.wordRepo.FindById(c, id)
 	if err != nil {
-		return common.ErrInternalServerError
+		return nil, common.InternalError(err)
 	}
 	if w == nil {
-		return common.ErrNotFound
+		return nil, common.ErrNotFound
+	}
+	if err := u.access.checkReadWords(c, []domain.Word{*w}, user); err != nil {
+		return nil, err
 	}
+	return w, nil
+}
+
+func (u *wordUsecase) Create(c context.Context, word domain.Word, graphId string, user domain.Profile) (res *string, err error) {
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
 
-	return u.wordRepo.Update(domain.Word{
-		Id:          id,
-		Content:     word.Content,
-		Description: word.Description,
-		Refs:        word.Refs,
+		// insert to db
+		w := &word
+		w.CreatedAt = common.ToPointer(time.Now())
+		res, err = u.wordRepo.Store(c, *w, graphId, nil)
+		if err != nil {
+			slog.Error("Create error", err)
+			return common.InternalError(err)
+		}
+		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	u.graphCache.InvalidateGraphData(c, graphId)
+
+	return res, nil
 }
 
-func (u *wordUsecase) Delete(c context.Context, id string, user domain.Profile) error {
-	word, err := u.wordRepo.FindById(id)
+func (u *wordUsecase) CreateWordWithLink(c context.Context, word domain.Word, linkWordId string, graphId string, user domain.Profile) (res *string, err error) {
+	// the linked word may belong to another graph
+	linkGraphId := ""
+	err = u.transactor.WithinTransaction(c, func(c context.Context) error {
+		if _, err := u.access.check(c, graphId, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+
+		// validate that link word is existed or not,
+		// and keep it from being deleted until the new word is linked
+		if err := u.wordRepo.Lock(c, []string{linkWordId}); err != nil {
+			return common.InternalError(err)
+		}
+		joinWord, err := u.wordRepo.FindById(c, linkWordId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if joinWord == nil {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, []domain.Word{*joinWord}, user, domain.GraphRoleViewer); err != nil {
+			return err
+		}
+
+		res, err = u.wordRepo.Store(c, word, graphId, &linkWordId)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		linkGraphId = joinWord.GraphId
+		return nil
+	})
 	if err != nil {
-		return common.ErrInternalServerError
+		return nil, err
 	}
+	u.graphCache.InvalidateGraphData(c, graphId, linkGraphId)
+
+	return res, nil
+}
 
-	if user.Role == domain.RoleMember && user.Id != word.UserId {
-		return common.ErrUnauthorization
+func (u *wordUsecase) Update(c context.Context, id string, word domain.Word, user domain.Profile) error {
+	graphId := ""
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		// the revision keeps the values read once locked
+		if err := u.wordRepo.Lock(c, []string{id}); err != nil {
+			return common.InternalError(err)
+		}
+		w, err := u.wordRepo.FindById(c, id)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if w == nil {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, []domain.Word{*w}, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphId = w.GraphId
+
+		err = storeRevision(c, u.revisionRepo, wordRevision(*w, user), word.Content, word.Description, word.Refs)
+		if err != nil {
+			return err
+		}
+		err = u.wordRepo.Update(c, domain.Word{
+			Id:          id,
+			Content:     word.Content,
+			Description: word.Description,
+			Refs:        word.Refs,
+		})
+		if err != nil {
+			slog.Error("Update error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
+	u.graphCache.InvalidateGraphData(c, graphId)
+	return nil
+}
 
-	return u.wordRepo.Delete(id)
+func (u *wordUsecase) Delete(c context.Context, id string, user domain.Profile) error {
+	graphId := ""
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		word, err := u.wordRepo.FindById(c, id)
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if word == nil {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, []domain.Word{*word}, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+		graphId = word.GraphId
 
+		// the word is kept in the trash of the graph until it is purged
+		if err := u.trashRepo.TrashWord(c, id, user.Id); err != nil {
+			slog.Error("Trash word error", err)
+			return common.InternalError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	u.graphCache.InvalidateGraphData(c, graphId)
+	return nil
 }
 
-func (u *wordUsecase) FindPath(c context.Context, fromId string, toId string) ([]domain.Word, []domain.WordsLink, error) {
-	return u.wordRepo.FindPath(fromId, toId)
+func (u *wordUsecase) FindPath(c context.Context, fromId string, toId string, user domain.Profile) ([]domain.Word, []domain.WordsLink, error) {
+	ends, err := u.wordRepo.FindByIds(c, []string{fromId, toId})
+	if err != nil {
+		return nil, nil, common.InternalError(err)
+	}
+	if len(ends) == 0 || (fromId != toId && len(ends) != 2) {
+		return nil, nil, common.ErrNotFound
+	}
+	if err := u.access.checkReadWords(c, ends, user); err != nil {
+		return nil, nil, err
+	}
+
+	words, links, err := u.wordRepo.FindPath(c, fromId, toId)
+	if err != nil {
+		return nil, nil, common.InternalError(err)
+	}
+	// a path going through a graph the user can't read is not shown
+	if err := u.access.checkReadWords(c, words, user); err != nil {
+		if errors.Is(err, common.ErrNotFound) {
+			return []domain.Word{}, []domain.WordsLink{}, nil
+		}
+		return nil, nil, err
+	}
+	return words, links, nil
 }
 
-func (u *wordUsecase) Link2Words(c context.Context, sourceId string, targetId string) error {
+func (u *wordUsecase) Link2Words(c context.Context, sourceId string, targetId string, user domain.Profile) error {
 	if sourceId == targetId {
 		return common.ErrBadParamInput
 	}
-	return u.wordRepo.StoreRelation(sourceId, targetId)
+
+	ws := []domain.Word{}
+	err := u.transactor.WithinTransaction(c, func(c context.Context) error {
+		var err error
+		ws, err = u.wordRepo.FindByIds(c, []string{sourceId, targetId})
+		if err != nil {
+			return common.InternalError(err)
+		}
+		if len(ws) != 2 {
+			return common.ErrNotFound
+		}
+		if err := u.access.checkWords(c, ws, user, domain.GraphRoleEditor); err != nil {
+			return err
+		}
+
+		return u.wordRepo.StoreRelation(c, sourceId, targetId)
+	})
+	if err != nil {
+		return err
+	}
+
+	// the relation shows in the graph data of both words
+	for _, w := range ws {
+		u.graphCache.InvalidateGraphData(c, w.GraphId)
+	}
+	return nil
 }
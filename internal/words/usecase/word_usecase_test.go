@@ -0,0 +1,194 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/common/cache"
+	"github.com/s2dio-tech/mindgra-backend/datasource"
+	"github.com/s2dio-tech/mindgra-backend/domain"
+	"github.com/s2dio-tech/mindgra-backend/internal/words/repository"
+)
+
+// A word "rain" in a graph of each visibility of the user "owner",
+// in a private graph shared by a link and in a deleted public graph,
+// the graphs are found by their name
+type searchFixture struct {
+	usecase domain.WordUsecase
+	ids     map[string]string
+}
+
+func newSearchFixture(t *testing.T) *searchFixture {
+	ctx := context.Background()
+	mem := datasource.InitMemory()
+	mem.Users["owner"] = &domain.User{Id: "owner"}
+	graphRepo := repository.InitGraphMemoryRepository(mem)
+	wordRepo := repository.InitWordMemoryRepository(mem)
+	shareRepo := repository.InitShareMemoryRepository(mem)
+	f := &searchFixture{ids: map[string]string{}}
+
+	for _, name := range []string{"public", "unlisted", "private", "shared", "deleted"} {
+		visibility := domain.GraphVisibility(name)
+		if name == "shared" {
+			visibility = domain.GraphVisibilityPrivate
+		} else if name == "deleted" {
+			visibility = domain.GraphVisibilityPublic
+		}
+		id, err := graphRepo.Store(ctx, domain.Graph{UserId: "owner", Name: name, Visibility: visibility})
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.ids[name] = *id
+		if _, err := wordRepo.Store(ctx, domain.Word{UserId: "owner", Content: "rain in " + name}, *id, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := graphRepo.Delete(ctx, f.ids["deleted"]); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shareRepo.Store(ctx, domain.ShareLink{GraphId: f.ids["shared"], TokenHash: common.HashToken("token")}); err != nil {
+		t.Fatal(err)
+	}
+
+	graphCache := repository.InitGraphDataCache(cache.Nop{})
+	f.usecase = InitWordUsecase(
+		wordRepo,
+		graphRepo,
+		repository.InitMemberMemoryRepository(mem),
+		shareRepo,
+		repository.InitRevisionMemoryRepository(mem),
+		repository.InitTrashMemoryRepository(mem),
+		repository.InitWordIndex(wordRepo, graphCache, 0),
+		repository.InitWordSimilarity(wordRepo, graphCache, 0),
+		graphCache,
+		mem,
+	)
+	return f
+}
+
+// Names of the graphs of the words found
+func (f *searchFixture) searchedGraphs(t *testing.T, search domain.WordSearch, user domain.Profile) []string {
+	page, err := f.usecase.SearchWord(context.Background(), search, user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphs := []string{}
+	for _, h := range page.Results {
+		for name, id := range f.ids {
+			if id == h.GraphId {
+				graphs = append(graphs, name)
+			}
+		}
+	}
+	sort.Strings(graphs)
+	return graphs
+}
+
+func TestSearchWordOfTheReadableGraphs(t *testing.T) {
+	f := newSearchFixture(t)
+	all := domain.WordSearch{Text: "rain", Scope: domain.SearchScopeAll, Limit: 10}
+
+	for _, test := range []struct {
+		name   string
+		search domain.WordSearch
+		user   domain.Profile
+		want   []string
+	}{
+		{"anonymous", all, domain.Profile{}, []string{"public"}},
+		{"share link", all, domain.Profile{Share: &domain.ShareCredential{Token: "token"}}, []string{"public", "shared"}},
+		{"owner", all, domain.Profile{Id: "owner"}, []string{"private", "public", "shared", "unlisted"}},
+		{"admin", all, domain.Profile{Id: "admin", Role: domain.RoleAdmin}, []string{"private", "public", "shared", "unlisted"}},
+		{"stranger of mine", domain.WordSearch{Text: "rain", Scope: domain.SearchScopeMine, Limit: 10}, domain.Profile{Id: "stranger"}, []string{}},
+		{"graph", domain.WordSearch{Text: "rain", GraphId: f.ids["unlisted"], Limit: 10}, domain.Profile{}, []string{"unlisted"}},
+	} {
+		if got := f.searchedGraphs(t, test.search, test.user); !slices.Equal(got, test.want) {
+			t.Errorf("SearchWord() by %s = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestSearchWordPages(t *testing.T) {
+	u := newSearchFixture(t).usecase
+	owner := domain.Profile{Id: "owner"}
+	search := domain.WordSearch{Text: "rain", Limit: 3}
+
+	page, err := u.SearchWord(context.Background(), search, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Results) != 3 || page.NextCursor == "" {
+		t.Fatalf("first page = %d hits, cursor %q", len(page.Results), page.NextCursor)
+	}
+	seen := map[string]bool{}
+	for _, h := range page.Results {
+		seen[h.Id] = true
+	}
+
+	search.Cursor = page.NextCursor
+	page, err = u.SearchWord(context.Background(), search, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Results) != 1 || page.NextCursor != "" || seen[page.Results[0].Id] {
+		t.Fatalf("last page = %+v, cursor %q", page.Results, page.NextCursor)
+	}
+
+	// the offset counts the readable hits only
+	search.Cursor, search.Offset = "", 3
+	page, err = u.SearchWord(context.Background(), search, owner)
+	if err != nil || len(page.Results) != 1 {
+		t.Fatalf("page at offset 3 = %+v, %v", page, err)
+	}
+}
+
+func TestSimilarLeavesOutTheLinkedWords(t *testing.T) {
+	ctx := context.Background()
+	mem := datasource.InitMemory()
+	mem.Users["owner"] = &domain.User{Id: "owner"}
+	graphRepo := repository.InitGraphMemoryRepository(mem)
+	wordRepo := repository.InitWordMemoryRepository(mem)
+	graphId, err := graphRepo.Store(ctx, domain.Graph{UserId: "owner", Name: "rain", Visibility: domain.GraphVisibilityPrivate})
+	if err != nil {
+		t.Fatal(err)
+	}
+	storm, err := wordRepo.Store(ctx, domain.Word{UserId: "owner", Content: "rain storm"}, *graphId, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := map[string]string{}
+	for _, c := range []string{"rain cloud", "rain drop"} {
+		var link *string
+		if c == "rain cloud" {
+			link = storm
+		}
+		id, err := wordRepo.Store(ctx, domain.Word{UserId: "owner", Content: c}, *graphId, link)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[*id] = c
+	}
+
+	graphCache := repository.InitGraphDataCache(cache.Nop{})
+	u := InitWordUsecase(
+		wordRepo,
+		graphRepo,
+		repository.InitMemberMemoryRepository(mem),
+		repository.InitShareMemoryRepository(mem),
+		repository.InitRevisionMemoryRepository(mem),
+		repository.InitTrashMemoryRepository(mem),
+		repository.InitWordIndex(wordRepo, graphCache, 0),
+		repository.InitWordSimilarity(wordRepo, graphCache, 0),
+		graphCache,
+		mem,
+	)
+	res, err := u.Similar(ctx, *storm, 10, domain.Profile{Id: "owner"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res) != 1 || ids[res[0].Id] != "rain drop" {
+		t.Fatalf("Similar() of the storm = %+v, want the drop only", res)
+	}
+}
@@ -1,27 +1,230 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"net/url"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/neo4j"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+
+	common "github.com/s2dio-tech/mindgra-backend/common"
+	"github.com/s2dio-tech/mindgra-backend/migration/migrations"
 )
 
+const usage = `Usage: migrate COMMAND [ARG]
+
+Commands:
+  up            apply all pending migrations
+  down N        roll back the last N applied migrations
+  goto V        migrate up or down to version V
+  status        print the applied version and the pending migrations
+  force V       set the version without running migrations, to recover
+                from a dirty state, -1 clears the version
+  create NAME   add empty up and down files to the source tree,
+                in the directory of the DB_DRIVER migrations
+
+The database is read from the same DB_* variables as the API,
+neo4j and sqlite have their own migrations,
+the memgraph schema is applied by hand from migrations/memgraph/schema.cypher.
+`
+
 func main() {
-	host, _ := os.LookupEnv("DB_HOST")
-	port, _ := os.LookupEnv("DB_PORT")
-	username, _ := os.LookupEnv("DB_USERNAME")
-	password, _ := os.LookupEnv("DB_PASSWORD")
-
-	m, err := migrate.New(
-		"file://./migrations",
-		"neo4j://"+username+":"+password+"@"+host+":"+port)
+	flag.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// create works on the source tree only, no database needed
+	if args[0] == "create" {
+		if len(args) != 2 {
+			log.Fatal("create needs a NAME")
+		}
+		if err := create(os.Getenv("DB_DRIVER"), args[1]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	common.InitDatabaseConfig()
+	m, err := newMigrate()
 	if err != nil {
 		log.Fatal(err)
 	}
-	if err := m.Up(); err != nil {
+	defer m.Close()
+
+	switch args[0] {
+	case "up":
+		err = m.Up()
+	case "down":
+		n, parseErr := intArg(args, "down needs the number of migrations N")
+		if parseErr != nil {
+			log.Fatal(parseErr)
+		}
+		if n <= 0 {
+			log.Fatal("N must be positive")
+		}
+		err = m.Steps(-n)
+	case "goto":
+		v, parseErr := intArg(args, "goto needs a version V")
+		if parseErr != nil {
+			log.Fatal(parseErr)
+		}
+		if v < 0 {
+			log.Fatal("V must not be negative")
+		}
+		err = m.Migrate(uint(v))
+	case "force":
+		v, parseErr := intArg(args, "force needs a version V")
+		if parseErr != nil {
+			log.Fatal(parseErr)
+		}
+		err = m.Force(v)
+	case "status":
+		err = status(m)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if errors.Is(err, migrate.ErrNoChange) {
+		log.Println("no change")
+		return
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
+
+// Open the embedded migrations against the configured database
+func newMigrate() (*migrate.Migrate, error) {
+	var dbUrl string
+	switch common.AppConfig.DBDriver {
+	case common.DBDriverNeo4J:
+		dbUrl = (&url.URL{
+			Scheme:   "neo4j",
+			User:     url.UserPassword(common.AppConfig.DBUsername, common.AppConfig.DBPassword),
+			Host:     common.AppConfig.DBHost + ":" + common.AppConfig.DBPort,
+			RawQuery: "x-multi-statement=true",
+		}).String()
+	case common.DBDriverSQLite:
+		// the driver opens whatever follows the scheme as the file
+		dbUrl = "sqlite://" + common.AppConfig.DBPath
+	case common.DBDriverMemgraph:
+		return nil, errors.New("no migrations for DB_DRIVER memgraph, apply migrations/memgraph/schema.cypher by hand")
+	default:
+		return nil, fmt.Errorf("no migrations for DB_DRIVER %s", common.AppConfig.DBDriver)
+	}
+
+	src, err := migrations.Source(common.AppConfig.DBDriver)
+	if err != nil {
+		return nil, err
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, dbUrl)
+	if err != nil {
+		return nil, err
+	}
+	m.Log = logger{}
+	return m, nil
+}
+
+// Read the integer argument of a command
+func intArg(args []string, missing string) (int, error) {
+	if len(args) != 2 {
+		return 0, errors.New(missing)
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a number", args[1])
+	}
+	return n, nil
+}
+
+func status(m *migrate.Migrate) error {
+	version, dirty, err := m.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		fmt.Println("version: none")
+	} else if err != nil {
+		return err
+	} else if dirty {
+		fmt.Printf("version: %d (dirty)\n", version)
+	} else {
+		fmt.Printf("version: %d\n", version)
+	}
+
+	src, err := migrations.Source(common.AppConfig.DBDriver)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	next, err := src.First()
+	for err == nil {
+		state := "pending"
+		if next <= version {
+			state = "applied"
+		}
+		r, name, readErr := src.ReadUp(next)
+		if readErr != nil {
+			return readErr
+		}
+		r.Close()
+		fmt.Printf("%-8s %d_%s\n", state, next, name)
+		next, err = src.Next(next)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+func create(driver string, name string) error {
+	if !namePattern.MatchString(name) {
+		return errors.New("NAME must only contain lower case letters, digits and underscores")
+	}
+	dir, ext := "migration/migrations", ".cypher"
+	switch driver {
+	case "", common.DBDriverNeo4J:
+	case common.DBDriverSQLite:
+		dir, ext = filepath.Join(dir, "sqlite"), ".sql"
+	default:
+		return fmt.Errorf("no migrations for DB_DRIVER %s", driver)
+	}
+
+	base := filepath.Join(dir, strconv.FormatInt(time.Now().Unix(), 10)+"_"+name)
+	for _, f := range []string{base + ".up" + ext, base + ".down" + ext} {
+		file, err := os.OpenFile(f, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		file.Close()
+		fmt.Println(f)
+	}
+	return nil
+}
+
+// logger prints the progress reported by migrate
+type logger struct{}
+
+func (logger) Printf(format string, v ...interface{}) {
+	log.Printf(format, v...)
+}
+
+func (logger) Verbose() bool {
+	return false
+}
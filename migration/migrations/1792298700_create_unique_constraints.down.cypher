@@ -0,0 +1,6 @@
+DROP CONSTRAINT token_id_unique IF EXISTS;
+DROP CONSTRAINT graph_id_unique IF EXISTS;
+DROP CONSTRAINT link_id_unique IF EXISTS;
+DROP CONSTRAINT word_id_unique IF EXISTS;
+DROP CONSTRAINT user_id_unique IF EXISTS;
+DROP CONSTRAINT user_email_unique IF EXISTS;
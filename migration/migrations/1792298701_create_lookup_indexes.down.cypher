@@ -0,0 +1,3 @@
+DROP INDEX token_user_id IF EXISTS;
+DROP INDEX link_word2_id IF EXISTS;
+DROP INDEX link_word1_id IF EXISTS;
@@ -0,0 +1,3 @@
+CREATE INDEX link_word1_id IF NOT EXISTS FOR (n:Link) ON (n.word1Id);
+CREATE INDEX link_word2_id IF NOT EXISTS FOR (n:Link) ON (n.word2Id);
+CREATE INDEX token_user_id IF NOT EXISTS FOR (n:Token) ON (n.userId);
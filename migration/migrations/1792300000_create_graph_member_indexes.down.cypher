@@ -0,0 +1,4 @@
+DROP INDEX graph_member_email IF EXISTS;
+DROP INDEX graph_member_user_id IF EXISTS;
+DROP INDEX graph_member_graph_id IF EXISTS;
+DROP CONSTRAINT graph_member_id_unique IF EXISTS;
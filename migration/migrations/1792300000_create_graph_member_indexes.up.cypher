@@ -0,0 +1,4 @@
+CREATE CONSTRAINT graph_member_id_unique IF NOT EXISTS FOR (n:GraphMember) REQUIRE n.id IS UNIQUE;
+CREATE INDEX graph_member_graph_id IF NOT EXISTS FOR (n:GraphMember) ON (n.graphId);
+CREATE INDEX graph_member_user_id IF NOT EXISTS FOR (n:GraphMember) ON (n.userId);
+CREATE INDEX graph_member_email IF NOT EXISTS FOR (n:GraphMember) ON (n.email);
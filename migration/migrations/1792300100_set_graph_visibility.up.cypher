@@ -0,0 +1 @@
+MATCH (g:Graph) WHERE g.visibility IS NULL SET g.visibility = 'private';
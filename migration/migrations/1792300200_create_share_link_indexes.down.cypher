@@ -0,0 +1,3 @@
+DROP INDEX share_link_graph_id IF EXISTS;
+DROP CONSTRAINT share_link_token_hash_unique IF EXISTS;
+DROP CONSTRAINT share_link_id_unique IF EXISTS;
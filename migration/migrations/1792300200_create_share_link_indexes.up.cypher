@@ -0,0 +1,3 @@
+CREATE CONSTRAINT share_link_id_unique IF NOT EXISTS FOR (n:ShareLink) REQUIRE n.id IS UNIQUE;
+CREATE CONSTRAINT share_link_token_hash_unique IF NOT EXISTS FOR (n:ShareLink) REQUIRE n.tokenHash IS UNIQUE;
+CREATE INDEX share_link_graph_id IF NOT EXISTS FOR (n:ShareLink) ON (n.graphId);
@@ -0,0 +1,2 @@
+DROP INDEX revision_entity_id IF EXISTS;
+DROP CONSTRAINT revision_id_unique IF EXISTS;
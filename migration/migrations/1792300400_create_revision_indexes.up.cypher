@@ -0,0 +1,2 @@
+CREATE CONSTRAINT revision_id_unique IF NOT EXISTS FOR (n:Revision) REQUIRE n.id IS UNIQUE;
+CREATE INDEX revision_entity_id IF NOT EXISTS FOR (n:Revision) ON (n.entityId);
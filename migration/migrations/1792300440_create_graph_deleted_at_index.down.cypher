@@ -0,0 +1 @@
+DROP INDEX graph_deleted_at IF EXISTS;
@@ -0,0 +1 @@
+CREATE INDEX graph_deleted_at IF NOT EXISTS FOR (n:Graph) ON (n.deletedAt);
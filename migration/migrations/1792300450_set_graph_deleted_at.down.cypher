@@ -0,0 +1 @@
+MATCH (g:Graph) REMOVE g.deletedAt;
@@ -0,0 +1 @@
+MATCH (g:Graph {deleteFlag: true}) WHERE g.deletedAt IS NULL SET g.deletedAt = localdatetime();
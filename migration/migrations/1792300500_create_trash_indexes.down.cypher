@@ -0,0 +1,3 @@
+DROP INDEX trashed_link_id IF EXISTS;
+DROP INDEX trashed_word_graph_id IF EXISTS;
+DROP INDEX trashed_word_id IF EXISTS;
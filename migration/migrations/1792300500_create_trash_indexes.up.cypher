@@ -0,0 +1,3 @@
+CREATE INDEX trashed_word_id IF NOT EXISTS FOR (n:TrashedWord) ON (n.id);
+CREATE INDEX trashed_word_graph_id IF NOT EXISTS FOR (n:TrashedWord) ON (n.graphId);
+CREATE INDEX trashed_link_id IF NOT EXISTS FOR (n:TrashedLink) ON (n.id);
@@ -0,0 +1,7 @@
+MATCH (m:GraphMember)
+WITH m, toLower(trim(m.email)) AS email
+ORDER BY CASE m.status WHEN 'accepted' THEN 0 ELSE 1 END, m.createdAt, m.id
+WITH m.graphId AS graphId, email, collect(m) AS members
+FOREACH (m IN tail(members) | DETACH DELETE m)
+WITH email, head(members) AS kept
+SET kept.email = email;
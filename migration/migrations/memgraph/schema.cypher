@@ -0,0 +1,38 @@
+// Constraints and indexes of the memgraph driver, the counterpart of the
+// neo4j migrations, applied by hand as golang-migrate has no memgraph driver:
+//   mgconsole < migration/migrations/memgraph/schema.cypher
+// The API refuses to serve until every statement below has been applied.
+// There is no full-text index, memgraph searches the words with a substring scan.
+// A unique constraint of memgraph is not backed by an index, both are created.
+CREATE CONSTRAINT ON (n:User) ASSERT n.email IS UNIQUE;
+CREATE CONSTRAINT ON (n:User) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:Word) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:Link) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:Graph) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:Token) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:GraphMember) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:ShareLink) ASSERT n.id IS UNIQUE;
+CREATE CONSTRAINT ON (n:ShareLink) ASSERT n.tokenHash IS UNIQUE;
+CREATE CONSTRAINT ON (n:Revision) ASSERT n.id IS UNIQUE;
+CREATE INDEX ON :User(email);
+CREATE INDEX ON :User(id);
+CREATE INDEX ON :Word(id);
+CREATE INDEX ON :Link(id);
+CREATE INDEX ON :Link(word1Id);
+CREATE INDEX ON :Link(word2Id);
+CREATE INDEX ON :Graph(id);
+CREATE INDEX ON :Graph(deletedAt);
+CREATE INDEX ON :Token(id);
+CREATE INDEX ON :Token(userId);
+CREATE INDEX ON :GraphMember(id);
+CREATE INDEX ON :GraphMember(graphId);
+CREATE INDEX ON :GraphMember(userId);
+CREATE INDEX ON :GraphMember(email);
+CREATE INDEX ON :ShareLink(id);
+CREATE INDEX ON :ShareLink(tokenHash);
+CREATE INDEX ON :ShareLink(graphId);
+CREATE INDEX ON :Revision(id);
+CREATE INDEX ON :Revision(entityId);
+CREATE INDEX ON :TrashedWord(id);
+CREATE INDEX ON :TrashedWord(graphId);
+CREATE INDEX ON :TrashedLink(id);
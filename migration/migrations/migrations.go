@@ -0,0 +1,64 @@
+// Schema migrations, embedded in the binaries
+package migrations
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/s2dio-tech/mindgra-backend/common"
+)
+
+// Cypher migrations of the neo4j driver
+//
+//go:embed *.cypher
+var FS embed.FS
+
+// SQL migrations of the sqlite driver
+//
+//go:embed sqlite/*.sql
+var SQLiteFS embed.FS
+
+// Constraints and indexes of the memgraph driver, applied by hand
+//
+//go:embed memgraph/schema.cypher
+var MemgraphSchema string
+
+// Open the migrations written for the DB_DRIVER
+func Source(driver string) (source.Driver, error) {
+	switch driver {
+	case common.DBDriverNeo4J:
+		return iofs.New(FS, ".")
+	case common.DBDriverSQLite:
+		return iofs.New(SQLiteFS, "sqlite")
+	default:
+		return nil, fmt.Errorf("no migrations for DB_DRIVER %s", driver)
+	}
+}
+
+// Version of the last embedded migration of the DB_DRIVER
+func LatestVersion(driver string) (uint, error) {
+	src, err := Source(driver)
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		next, err := src.Next(version)
+		if errors.Is(err, fs.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		version = next
+	}
+}
@@ -0,0 +1,39 @@
+package migrations
+
+import (
+	"io/fs"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var schemaStatement = regexp.MustCompile(`(?i)^\s*(CREATE|DROP)\s+(FULLTEXT\s+)?(INDEX|CONSTRAINT)\b`)
+
+// neo4j refuses a schema change after a data write in the same transaction,
+// and a multi-statement migration runs in one
+func TestCypherMigrationsDontMixSchemaAndData(t *testing.T) {
+	files, err := fs.Glob(FS, "*.cypher")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range files {
+		b, err := FS.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		schema, data := 0, 0
+		for _, statement := range strings.Split(string(b), ";") {
+			if strings.TrimSpace(statement) == "" {
+				continue
+			}
+			if schemaStatement.MatchString(statement) {
+				schema++
+			} else {
+				data++
+			}
+		}
+		if schema > 0 && data > 0 {
+			t.Errorf("%s has %d schema and %d data statements", name, schema, data)
+		}
+	}
+}
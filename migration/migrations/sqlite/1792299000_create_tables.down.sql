@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS words_fts_delete;
+DROP TRIGGER IF EXISTS words_fts_update;
+DROP TRIGGER IF EXISTS words_fts_insert;
+DROP TABLE IF EXISTS words_fts;
+DROP TABLE IF EXISTS links;
+DROP TABLE IF EXISTS relations;
+DROP TABLE IF EXISTS words;
+DROP TABLE IF EXISTS graphs;
+DROP TABLE IF EXISTS tokens;
+DROP TABLE IF EXISTS users;
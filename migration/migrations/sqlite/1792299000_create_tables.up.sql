@@ -0,0 +1,87 @@
+CREATE TABLE users (
+	id TEXT PRIMARY KEY,
+	name TEXT NOT NULL,
+	email TEXT NOT NULL UNIQUE,
+	password TEXT NOT NULL,
+	role TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP
+);
+
+CREATE TABLE tokens (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	type TEXT NOT NULL,
+	token TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX tokens_user_id ON tokens (user_id, type);
+
+CREATE TABLE graphs (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	name TEXT NOT NULL,
+	type TEXT NOT NULL,
+	delete_flag BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP
+);
+CREATE INDEX graphs_user_id ON graphs (user_id);
+
+CREATE TABLE words (
+	id TEXT PRIMARY KEY,
+	graph_id TEXT NOT NULL REFERENCES graphs (id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	description TEXT,
+	-- json array of strings
+	refs TEXT,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP
+);
+CREATE INDEX words_graph_id ON words (graph_id);
+
+-- the CONCERN relationships of the graph databases,
+-- link_id is set when a link annotates the relationship
+CREATE TABLE relations (
+	id INTEGER PRIMARY KEY,
+	start_id TEXT NOT NULL REFERENCES words (id) ON DELETE CASCADE,
+	end_id TEXT NOT NULL REFERENCES words (id) ON DELETE CASCADE,
+	link_id TEXT
+);
+CREATE INDEX relations_start_id ON relations (start_id);
+CREATE INDEX relations_end_id ON relations (end_id);
+
+CREATE TABLE links (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	word1_id TEXT NOT NULL REFERENCES words (id) ON DELETE CASCADE,
+	word2_id TEXT NOT NULL REFERENCES words (id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	description TEXT,
+	refs TEXT,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP
+);
+CREATE INDEX links_word1_id ON links (word1_id);
+CREATE INDEX links_word2_id ON links (word2_id);
+
+-- full-text index of the words, kept in sync by the triggers
+CREATE VIRTUAL TABLE words_fts USING fts5 (
+	id UNINDEXED,
+	content,
+	description,
+	tokenize = 'porter unicode61'
+);
+
+CREATE TRIGGER words_fts_insert AFTER INSERT ON words BEGIN
+	INSERT INTO words_fts (id, content, description) VALUES (new.id, new.content, new.description);
+END;
+
+CREATE TRIGGER words_fts_update AFTER UPDATE OF content, description ON words BEGIN
+	UPDATE words_fts SET content = new.content, description = new.description WHERE id = old.id;
+END;
+
+CREATE TRIGGER words_fts_delete AFTER DELETE ON words BEGIN
+	DELETE FROM words_fts WHERE id = old.id;
+END;
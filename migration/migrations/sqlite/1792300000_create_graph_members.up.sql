@@ -0,0 +1,16 @@
+-- invitations and memberships of the shared graphs,
+-- user_id is set when the invitation is accepted
+CREATE TABLE graph_members (
+	id TEXT PRIMARY KEY,
+	graph_id TEXT NOT NULL REFERENCES graphs (id) ON DELETE CASCADE,
+	user_id TEXT REFERENCES users (id) ON DELETE CASCADE,
+	email TEXT NOT NULL,
+	role TEXT NOT NULL,
+	status TEXT NOT NULL,
+	invited_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	accepted_at TIMESTAMP,
+	UNIQUE (graph_id, email)
+);
+CREATE INDEX graph_members_user_id ON graph_members (user_id);
+CREATE INDEX graph_members_email ON graph_members (email);
@@ -0,0 +1 @@
+ALTER TABLE graphs DROP COLUMN visibility;
@@ -0,0 +1,2 @@
+-- private, unlisted or public
+ALTER TABLE graphs ADD COLUMN visibility TEXT NOT NULL DEFAULT 'private';
@@ -0,0 +1,13 @@
+-- links giving read access to a graph without an account,
+-- only the hashes of the token and of the password are kept
+CREATE TABLE share_links (
+	id TEXT PRIMARY KEY,
+	graph_id TEXT NOT NULL REFERENCES graphs (id) ON DELETE CASCADE,
+	token_hash TEXT NOT NULL UNIQUE,
+	permission TEXT NOT NULL,
+	password_hash TEXT,
+	expires_at TIMESTAMP,
+	created_by TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX share_links_graph_id ON share_links (graph_id);
@@ -0,0 +1,2 @@
+-- graph a clone was copied from
+ALTER TABLE graphs ADD COLUMN origin_id TEXT;
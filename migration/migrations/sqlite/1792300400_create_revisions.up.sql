@@ -0,0 +1,14 @@
+-- the values of the words and links before each of their updates,
+-- entity_id is the id of the word or of the link
+CREATE TABLE revisions (
+	id TEXT PRIMARY KEY,
+	entity_type TEXT NOT NULL,
+	entity_id TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	user_id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	description TEXT,
+	refs TEXT,
+	created_at TIMESTAMP NOT NULL,
+	UNIQUE (entity_type, entity_id, version)
+);
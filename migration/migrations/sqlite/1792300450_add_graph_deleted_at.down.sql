@@ -0,0 +1 @@
+ALTER TABLE graphs DROP COLUMN deleted_at;
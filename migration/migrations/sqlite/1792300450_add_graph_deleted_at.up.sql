@@ -0,0 +1,4 @@
+-- time a graph was deleted at, the graphs deleted before it was kept
+-- are kept for the whole retention from now on
+ALTER TABLE graphs ADD COLUMN deleted_at TIMESTAMP;
+UPDATE graphs SET deleted_at = CURRENT_TIMESTAMP WHERE delete_flag;
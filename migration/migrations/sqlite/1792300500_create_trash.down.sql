@@ -0,0 +1,3 @@
+DROP TABLE trashed_links;
+DROP TABLE trashed_relations;
+DROP TABLE trashed_words;
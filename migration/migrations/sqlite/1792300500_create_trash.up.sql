@@ -0,0 +1,43 @@
+-- the deleted words, moved out of words so the full-text index
+-- and the queries of the graphs never see them
+CREATE TABLE trashed_words (
+	id TEXT PRIMARY KEY,
+	graph_id TEXT NOT NULL REFERENCES graphs (id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	description TEXT,
+	refs TEXT,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP,
+	deleted_by TEXT NOT NULL,
+	deleted_at TIMESTAMP NOT NULL
+);
+CREATE INDEX trashed_words_graph_id ON trashed_words (graph_id);
+
+-- the relationships of the trashed words, trashed_with is the word they were
+-- trashed with, and the relationships deleted on their own with none
+CREATE TABLE trashed_relations (
+	id INTEGER PRIMARY KEY,
+	start_id TEXT NOT NULL,
+	end_id TEXT NOT NULL,
+	link_id TEXT,
+	trashed_with TEXT,
+	deleted_by TEXT NOT NULL,
+	deleted_at TIMESTAMP NOT NULL
+);
+CREATE INDEX trashed_relations_start_id ON trashed_relations (start_id);
+CREATE INDEX trashed_relations_end_id ON trashed_relations (end_id);
+CREATE INDEX trashed_relations_trashed_with ON trashed_relations (trashed_with);
+
+-- the links of the trashed relationships
+CREATE TABLE trashed_links (
+	id TEXT PRIMARY KEY,
+	user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+	word1_id TEXT NOT NULL,
+	word2_id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	description TEXT,
+	refs TEXT,
+	created_at TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- the invitations are addressed to the lower-cased emails,
+-- of the ones differing by case the accepted one or else the first is kept
+DELETE FROM graph_members
+WHERE EXISTS (
+	SELECT 1 FROM graph_members o
+	WHERE o.graph_id = graph_members.graph_id
+		AND lower(trim(o.email)) = lower(trim(graph_members.email))
+		AND o.id <> graph_members.id
+		AND (
+			(o.status = 'accepted') > (graph_members.status = 'accepted')
+			OR ((o.status = 'accepted') = (graph_members.status = 'accepted')
+				AND (o.created_at < graph_members.created_at
+					OR (o.created_at = graph_members.created_at AND o.id < graph_members.id)))
+		)
+);
+UPDATE graph_members SET email = lower(trim(email));
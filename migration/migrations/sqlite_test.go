@@ -0,0 +1,69 @@
+package migrations
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/s2dio-tech/mindgra-backend/common"
+)
+
+func TestSQLiteLowerGraphMemberEmails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mindgra.db")
+	src, err := Source(common.DBDriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, "sqlite://"+path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	if err := m.Migrate(1792300500); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	_, err = db.Exec(`
+		INSERT INTO users (id, name, email, password, role, created_at) VALUES ('u1', 'u1', 'u1@x.com', '', 'user', '2024-01-01');
+		INSERT INTO graphs (id, user_id, name, type, created_at) VALUES ('g1', 'u1', 'g1', '', '2024-01-01'), ('g2', 'u1', 'g2', '', '2024-01-01');
+		INSERT INTO graph_members (id, graph_id, email, role, status, invited_by, created_at) VALUES
+			('m1', 'g1', 'Bob@x.com', 'viewer', 'pending', 'u1', '2024-01-01'),
+			('m2', 'g1', ' bob@x.com', 'editor', 'pending', 'u1', '2024-01-02'),
+			('m3', 'g2', 'Ann@x.com', 'viewer', 'pending', 'u1', '2024-01-01'),
+			('m4', 'g2', 'ann@x.com', 'editor', 'accepted', 'u1', '2024-01-02'),
+			('m5', 'g2', 'Bob@X.com ', 'viewer', 'pending', 'u1', '2024-01-01');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Up(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := db.Query(`SELECT id, email FROM graph_members ORDER BY id`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	got := []string{}
+	for rows.Next() {
+		var id, email string
+		if err := rows.Scan(&id, &email); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, id+" "+email)
+	}
+	// the first invitation and the accepted one are kept
+	want := []string{"m1 bob@x.com", "m4 ann@x.com", "m5 bob@x.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("graph_members = %v, want %v", got, want)
+	}
+}